@@ -0,0 +1,51 @@
+// GitHub OIDCシム
+// CognitoにGitHubをOIDCプロバイダーとして登録するため、GitHubのOAuth2をOIDCとして公開する
+// Cognitoから到達できるHTTPSのURL（GITHUB_OIDC_ISSUER）で公開する必要がある
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/pkg/githuboidc"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+func main() {
+	utils.LoadEnvFile()
+
+	config, err := githuboidc.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid GitHub OIDC config: %v", err)
+	}
+
+	port := utils.GetEnv("GITHUB_OIDC_PORT", "8081")
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           githuboidc.NewServer(config).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("GitHub OIDC shim starting on port %s (issuer %s)", port, config.Issuer)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+	log.Println("GitHub OIDC shim stopped")
+}
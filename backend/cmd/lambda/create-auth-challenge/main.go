@@ -0,0 +1,122 @@
+// create-auth-challenge - CUSTOM_AUTH の Create Auth Challenge トリガー
+// ワンタイムコードを生成してメールで送信する。同じログイン中の再入力では同じコードを使い続ける
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/matthewyuh246/aws-cognito/pkg/mailer"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	customChallenge = "CUSTOM_CHALLENGE"
+
+	codeDigits = 6
+	// metadataPrefix - challengeMetadata に保持するコード（クライアントには返されない）
+	metadataPrefix = "OTP:"
+)
+
+type challengeHandler struct {
+	sender  mailer.Sender
+	codeTTL time.Duration
+	subject string
+}
+
+func (h *challengeHandler) handle(ctx context.Context, event *events.CognitoEventUserPoolsCreateAuthChallenge) (*events.CognitoEventUserPoolsCreateAuthChallenge, error) {
+	if event.Request.ChallengeName != customChallenge {
+		return event, nil
+	}
+
+	email := event.Request.UserAttributes["email"]
+	if email == "" {
+		return nil, fmt.Errorf("user has no email attribute")
+	}
+
+	code, expiresAt, found := previousCode(event.Request.Session)
+	if !found {
+		var err error
+		code, err = utils.GenerateNumericCode(codeDigits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate code: %w", err)
+		}
+		expiresAt = time.Now().Add(h.codeTTL).Unix()
+
+		err = h.sender.Send(ctx, mailer.Message{
+			To:      email,
+			Subject: h.subject,
+			Body:    fmt.Sprintf("ログインコード: %s\n\nこのコードの有効期限は%d分です。心当たりがない場合はこのメールを破棄してください。\n", code, int(h.codeTTL.Minutes())),
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Sent login code to %s", utils.MaskSensitiveData(email, 1, 0, ""))
+	}
+
+	event.Response.PublicChallengeParameters = map[string]string{
+		"delivery_medium": "EMAIL",
+		"destination":     maskEmail(email),
+	}
+	event.Response.PrivateChallengeParameters = map[string]string{
+		"answer":     code,
+		"expires_at": strconv.FormatInt(expiresAt, 10),
+	}
+	event.Response.ChallengeMetadata = fmt.Sprintf("%s%s:%d", metadataPrefix, code, expiresAt)
+
+	return event, nil
+}
+
+// previousCode - 同じログインで既に送信したコードと有効期限
+func previousCode(session []*events.CognitoEventUserPoolsChallengeResult) (string, int64, bool) {
+	for i := len(session) - 1; i >= 0; i-- {
+		metadata := session[i].ChallengeMetadata
+		if !strings.HasPrefix(metadata, metadataPrefix) {
+			continue
+		}
+		code, expires, ok := strings.Cut(strings.TrimPrefix(metadata, metadataPrefix), ":")
+		if !ok {
+			continue
+		}
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			continue
+		}
+		return code, expiresAt, true
+	}
+	return "", 0, false
+}
+
+// maskEmail - 送信先の表示用（a***@example.com）
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return utils.MaskSensitiveData(email, 1, 0, "")
+	}
+	return utils.MaskSensitiveData(local, 1, 0, "") + "@" + domain
+}
+
+func main() {
+	sender, err := mailer.New(mailer.LoadConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize mail sender: %v", err)
+	}
+
+	codeTTL, err := time.ParseDuration(utils.GetEnv("LOGIN_CODE_TTL", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid LOGIN_CODE_TTL: %v", err)
+	}
+
+	h := &challengeHandler{
+		sender:  sender,
+		codeTTL: codeTTL,
+		subject: utils.GetEnv("LOGIN_CODE_SUBJECT", "ログインコード"),
+	}
+	lambda.Start(h.handle)
+}
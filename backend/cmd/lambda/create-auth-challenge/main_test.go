@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/matthewyuh246/aws-cognito/pkg/mailer"
+)
+
+type fakeSender struct {
+	sent []mailer.Message
+}
+
+func (s *fakeSender) Send(ctx context.Context, msg mailer.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func newEvent(session ...*events.CognitoEventUserPoolsChallengeResult) *events.CognitoEventUserPoolsCreateAuthChallenge {
+	event := &events.CognitoEventUserPoolsCreateAuthChallenge{}
+	event.Request.ChallengeName = customChallenge
+	event.Request.UserAttributes = map[string]string{"email": "alice@example.com"}
+	event.Request.Session = session
+	return event
+}
+
+func TestHandle_SendsCodeOnce(t *testing.T) {
+	sender := &fakeSender{}
+	h := &challengeHandler{sender: sender, codeTTL: 5 * time.Minute, subject: "ログインコード"}
+
+	first, err := h.handle(context.Background(), newEvent())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].To != "alice@example.com" {
+		t.Fatalf("expected one mail to the user, got %v", sender.sent)
+	}
+
+	code := first.Response.PrivateChallengeParameters["answer"]
+	if len(code) != codeDigits || !strings.Contains(sender.sent[0].Body, code) {
+		t.Errorf("mail should contain the %d-digit code: code=%q body=%q", codeDigits, code, sender.sent[0].Body)
+	}
+	expiresAt, err := strconv.ParseInt(first.Response.PrivateChallengeParameters["expires_at"], 10, 64)
+	if err != nil || expiresAt <= time.Now().Unix() || expiresAt > time.Now().Add(5*time.Minute).Unix() {
+		t.Errorf("unexpected expires_at: %q", first.Response.PrivateChallengeParameters["expires_at"])
+	}
+	if first.Response.PublicChallengeParameters["destination"] != maskEmail("alice@example.com") {
+		t.Errorf("unexpected public parameters: %v", first.Response.PublicChallengeParameters)
+	}
+
+	// 誤ったコードの後の再チャレンジでは、新しいメールを送らず同じコードと有効期限を使う
+	retry, err := h.handle(context.Background(), newEvent(&events.CognitoEventUserPoolsChallengeResult{
+		ChallengeName:     customChallenge,
+		ChallengeMetadata: first.Response.ChallengeMetadata,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Errorf("retry should not send another mail, sent %d", len(sender.sent))
+	}
+	if retry.Response.PrivateChallengeParameters["answer"] != code ||
+		retry.Response.PrivateChallengeParameters["expires_at"] != first.Response.PrivateChallengeParameters["expires_at"] {
+		t.Errorf("retry should reuse the code: %v", retry.Response.PrivateChallengeParameters)
+	}
+}
+
+func TestHandle_RequiresEmail(t *testing.T) {
+	h := &challengeHandler{sender: &fakeSender{}, codeTTL: time.Minute}
+
+	event := newEvent()
+	event.Request.UserAttributes = map[string]string{}
+	if _, err := h.handle(context.Background(), event); err == nil {
+		t.Fatal("expected an error for a user without email")
+	}
+}
+
+func TestPreviousCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      []string
+		wantCode      string
+		wantExpiresAt int64
+		wantFound     bool
+	}{
+		{name: "no session"},
+		{name: "latest code", metadata: []string{"OTP:111111:100", "OTP:222222:200"}, wantCode: "222222", wantExpiresAt: 200, wantFound: true},
+		{name: "malformed expiry skipped", metadata: []string{"OTP:111111:100", "OTP:222222:soon"}, wantCode: "111111", wantExpiresAt: 100, wantFound: true},
+		{name: "missing expiry", metadata: []string{"OTP:111111"}},
+		{name: "other metadata", metadata: []string{"SRP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var session []*events.CognitoEventUserPoolsChallengeResult
+			for _, metadata := range tt.metadata {
+				session = append(session, &events.CognitoEventUserPoolsChallengeResult{ChallengeMetadata: metadata})
+			}
+
+			code, expiresAt, found := previousCode(session)
+			if code != tt.wantCode || expiresAt != tt.wantExpiresAt || found != tt.wantFound {
+				t.Errorf("previousCode() = (%q, %d, %v), want (%q, %d, %v)", code, expiresAt, found, tt.wantCode, tt.wantExpiresAt, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// define-auth-challenge - CUSTOM_AUTH の Define Auth Challenge トリガー
+// メールのワンタイムコード（CUSTOM_CHALLENGE）のみでログインさせ、誤りが続いた場合は失敗させる
+// 認証アプリ（TOTP）のMFAを有効にしたユーザーは、メールのコードだけではMFAを迂回できてしまうため受け付けない
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+const (
+	customChallenge = "CUSTOM_CHALLENGE"
+
+	// maxAttempts - 1回のログインで受け付けるコード入力の回数
+	maxAttempts = 3
+)
+
+type challengeHandler struct {
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI
+}
+
+func (h *challengeHandler) handle(ctx context.Context, event *events.CognitoEventUserPoolsDefineAuthChallenge) (*events.CognitoEventUserPoolsDefineAuthChallenge, error) {
+	session := event.Request.Session
+
+	switch {
+	case event.Request.UserNotFound:
+		event.Response.FailAuthentication = true
+	case len(session) == 0:
+		// コードを送信する前に確認し、MFAを有効にしたユーザーにはパスワードとMFAでログインさせる
+		mfaEnabled, err := h.softwareTokenMFAEnabled(ctx, event.UserPoolID, event.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if mfaEnabled {
+			log.Printf("Refused passwordless login for a user with TOTP MFA")
+			event.Response.FailAuthentication = true
+			break
+		}
+		event.Response.ChallengeName = customChallenge
+	case !onlyCustomChallenges(session):
+		// SRPやパスワードとの組み合わせは受け付けない
+		event.Response.FailAuthentication = true
+	case session[len(session)-1].ChallengeResult:
+		event.Response.IssueTokens = true
+	case len(session) >= maxAttempts:
+		event.Response.FailAuthentication = true
+	default:
+		event.Response.ChallengeName = customChallenge
+	}
+
+	return event, nil
+}
+
+// softwareTokenMFAEnabled - ユーザーが認証アプリ（TOTP）のMFAを有効にしているか
+func (h *challengeHandler) softwareTokenMFAEnabled(ctx context.Context, userPoolID, username string) (bool, error) {
+	out, err := h.cognitoClient.AdminGetUserWithContext(ctx, &cognitoidentityprovider.AdminGetUserInput{
+		UserPoolId: aws.String(userPoolID),
+		Username:   aws.String(username),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, setting := range out.UserMFASettingList {
+		if aws.StringValue(setting) == cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func onlyCustomChallenges(session []*events.CognitoEventUserPoolsChallengeResult) bool {
+	for _, challenge := range session {
+		if challenge.ChallengeName != customChallenge {
+			return false
+		}
+	}
+	return true
+}
+
+func main() {
+	sess, err := session.NewSession()
+	if err != nil {
+		log.Fatalf("Failed to create AWS session: %v", err)
+	}
+
+	h := &challengeHandler{cognitoClient: cognitoidentityprovider.New(sess)}
+	lambda.Start(h.handle)
+}
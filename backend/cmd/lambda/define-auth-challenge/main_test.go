@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+)
+
+// fakeCognitoClient - ユーザーごとに有効なMFAを返す
+type fakeCognitoClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	mfaSettings map[string][]string
+	err         error
+	lookups     int
+}
+
+func (c *fakeCognitoClient) AdminGetUserWithContext(ctx aws.Context, input *cognitoidentityprovider.AdminGetUserInput, opts ...request.Option) (*cognitoidentityprovider.AdminGetUserOutput, error) {
+	c.lookups++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cognitoidentityprovider.AdminGetUserOutput{
+		Username:           input.Username,
+		UserMFASettingList: aws.StringSlice(c.mfaSettings[aws.StringValue(input.Username)]),
+	}, nil
+}
+
+func challengeResults(names []string, results []bool) []*events.CognitoEventUserPoolsChallengeResult {
+	session := make([]*events.CognitoEventUserPoolsChallengeResult, len(names))
+	for i := range names {
+		session[i] = &events.CognitoEventUserPoolsChallengeResult{ChallengeName: names[i], ChallengeResult: results[i]}
+	}
+	return session
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		username      string
+		userNotFound  bool
+		challenges    []string
+		results       []bool
+		wantChallenge string
+		wantTokens    bool
+		wantFail      bool
+	}{
+		{name: "first attempt", wantChallenge: customChallenge},
+		{name: "user not found", userNotFound: true, wantFail: true},
+		{name: "correct code", challenges: []string{customChallenge}, results: []bool{true}, wantTokens: true},
+		{name: "one wrong code", challenges: []string{customChallenge}, results: []bool{false}, wantChallenge: customChallenge},
+		{
+			name:       "correct on last attempt",
+			challenges: []string{customChallenge, customChallenge, customChallenge},
+			results:    []bool{false, false, true},
+			wantTokens: true,
+		},
+		{
+			name:       "attempt limit reached",
+			challenges: []string{customChallenge, customChallenge, customChallenge},
+			results:    []bool{false, false, false},
+			wantFail:   true,
+		},
+		{name: "combined with srp", challenges: []string{"SRP_A", "PASSWORD_VERIFIER"}, results: []bool{true, true}, wantFail: true},
+		// メールのコードだけでTOTPのMFAを迂回させない
+		{name: "totp enabled", username: "totp-user", wantFail: true},
+		{name: "sms mfa only", username: "sms-user", wantChallenge: customChallenge},
+	}
+
+	client := &fakeCognitoClient{mfaSettings: map[string][]string{
+		"totp-user": {"SMS_MFA", "SOFTWARE_TOKEN_MFA"},
+		"sms-user":  {"SMS_MFA"},
+	}}
+	h := &challengeHandler{cognitoClient: client}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &events.CognitoEventUserPoolsDefineAuthChallenge{}
+			event.UserPoolID = "ap-northeast-1_TEST"
+			event.UserName = tt.username
+			event.Request.UserNotFound = tt.userNotFound
+			event.Request.Session = challengeResults(tt.challenges, tt.results)
+
+			out, err := h.handle(context.Background(), event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Response.ChallengeName != tt.wantChallenge ||
+				out.Response.IssueTokens != tt.wantTokens ||
+				out.Response.FailAuthentication != tt.wantFail {
+				t.Errorf("unexpected response: %+v", out.Response)
+			}
+		})
+	}
+}
+
+func TestHandler_MFALookup(t *testing.T) {
+	t.Run("only before the first challenge", func(t *testing.T) {
+		client := &fakeCognitoClient{}
+		h := &challengeHandler{cognitoClient: client}
+
+		event := &events.CognitoEventUserPoolsDefineAuthChallenge{}
+		event.Request.Session = challengeResults([]string{customChallenge}, []bool{false})
+		if _, err := h.handle(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		event = &events.CognitoEventUserPoolsDefineAuthChallenge{}
+		event.Request.UserNotFound = true
+		if _, err := h.handle(context.Background(), event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if client.lookups != 0 {
+			t.Errorf("lookups = %d, want 0", client.lookups)
+		}
+	})
+
+	t.Run("lookup failure fails the trigger", func(t *testing.T) {
+		h := &challengeHandler{cognitoClient: &fakeCognitoClient{err: errors.New("throttled")}}
+
+		event := &events.CognitoEventUserPoolsDefineAuthChallenge{}
+		event.UserName = "alice"
+		if _, err := h.handle(context.Background(), event); err == nil {
+			t.Fatal("expected an error when the MFA settings cannot be read")
+		}
+	})
+}
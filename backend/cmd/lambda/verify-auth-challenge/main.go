@@ -0,0 +1,36 @@
+// verify-auth-challenge - CUSTOM_AUTH の Verify Auth Challenge Response トリガー
+// 入力されたコードが送信したコードと一致し、有効期限内であれば正解とする
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func handler(ctx context.Context, event *events.CognitoEventUserPoolsVerifyAuthChallenge) (*events.CognitoEventUserPoolsVerifyAuthChallenge, error) {
+	expected := event.Request.PrivateChallengeParameters["answer"]
+	answer := strings.TrimSpace(fmt.Sprint(event.Request.ChallengeAnswer))
+
+	expiresAt, err := strconv.ParseInt(event.Request.PrivateChallengeParameters["expires_at"], 10, 64)
+	if err != nil {
+		event.Response.AnswerCorrect = false
+		return event, nil
+	}
+
+	event.Response.AnswerCorrect = expected != "" &&
+		time.Now().Unix() <= expiresAt &&
+		subtle.ConstantTimeCompare([]byte(answer), []byte(expected)) == 1
+
+	return event, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	valid := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	expired := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		expected  string
+		expiresAt string
+		answer    interface{}
+		want      bool
+	}{
+		{"correct code", "123456", valid, "123456", true},
+		{"surrounding spaces", "123456", valid, " 123456\n", true},
+		{"wrong code", "123456", valid, "654321", false},
+		{"prefix of code", "123456", valid, "12345", false},
+		{"expired", "123456", expired, "123456", false},
+		{"malformed expiry", "123456", "5m", "123456", false},
+		{"missing expiry", "123456", "", "123456", false},
+		{"no code issued", "", valid, "", false},
+		{"missing answer", "123456", valid, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &events.CognitoEventUserPoolsVerifyAuthChallenge{}
+			event.Request.PrivateChallengeParameters = map[string]string{
+				"answer":     tt.expected,
+				"expires_at": tt.expiresAt,
+			}
+			event.Request.ChallengeAnswer = tt.answer
+
+			out, err := handler(context.Background(), event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Response.AnswerCorrect != tt.want {
+				t.Errorf("AnswerCorrect = %v, want %v", out.Response.AnswerCorrect, tt.want)
+			}
+		})
+	}
+}
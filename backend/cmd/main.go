@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -18,6 +19,7 @@ import (
 	"github.com/matthewyuh246/aws-cognito/internal/usecase"
 	awsconfig "github.com/matthewyuh246/aws-cognito/pkg/aws"
 	"github.com/matthewyuh246/aws-cognito/pkg/database"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
 	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -38,38 +40,186 @@ func initAWS(region string) *session.Session {
 	return sess
 }
 
-func migrateTables(db *gorm.DB) error {
-	return db.AutoMigrate(
+// initIdentityProvider - IDENTITY_PROVIDER（cognito / oidc）に応じてIdPを初期化する
+// エンドポイントは発行者のディスカバリードキュメントから取得し、取得できない場合は起動を中止する
+func initIdentityProvider(config *awsconfig.Config) repository.IIdentityProvider {
+	scopes := strings.Fields(utils.GetEnv("COGNITO_SCOPES", "openid email profile aws.cognito.signin.user.admin"))
+
+	discoveryCacheFile := utils.GetEnv("OIDC_DISCOVERY_CACHE_FILE", "")
+	discoveryRefreshInterval, err := time.ParseDuration(utils.GetEnv("OIDC_DISCOVERY_REFRESH_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid OIDC_DISCOVERY_REFRESH_INTERVAL: %v", err)
+	}
+
+	var identityProvider repository.IIdentityProvider
+
+	switch kind := utils.GetEnv("IDENTITY_PROVIDER", repository.IdentityProviderCognito); kind {
+	case repository.IdentityProviderCognito:
+		identityProvider = repository.NewCognitoIdentityProvider(repository.CognitoProviderConfig{
+			Domain:                   utils.GetEnv("COGNITO_DOMAIN_URL", ""),
+			Issuer:                   config.CognitoIssuer(),
+			ClientID:                 config.UserPoolClientID,
+			Scopes:                   scopes,
+			DiscoveryCacheFile:       discoveryCacheFile,
+			DiscoveryRefreshInterval: discoveryRefreshInterval,
+		})
+	case repository.IdentityProviderOIDC:
+		hints, err := repository.ParseProviderHints(utils.GetEnv("OIDC_PROVIDER_HINTS", ""))
+		if err != nil {
+			log.Fatalf("Invalid OIDC_PROVIDER_HINTS: %v", err)
+		}
+		// 外部IdP経由のログインはIDトークンのプロバイダーと照合するため、クレームの指定が必要
+		if len(hints) > 0 && utils.GetEnv("OIDC_PROVIDER_CLAIM", "") == "" {
+			log.Fatalf("OIDC_PROVIDER_CLAIM is required when OIDC_PROVIDER_HINTS is set")
+		}
+		identityProvider = repository.NewOIDCIdentityProvider(repository.OIDCProviderConfig{
+			Issuer:                   utils.GetEnv("OIDC_ISSUER", ""),
+			ClientID:                 utils.GetEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:             utils.GetEnv("OIDC_CLIENT_SECRET", ""),
+			Audience:                 utils.GetEnv("OIDC_AUDIENCE", ""),
+			DiscoveryCacheFile:       discoveryCacheFile,
+			DiscoveryRefreshInterval: discoveryRefreshInterval,
+			Scopes:                   strings.Fields(utils.GetEnv("OIDC_SCOPES", "openid email profile")),
+			ProviderHintParam:        utils.GetEnv("OIDC_PROVIDER_HINT_PARAM", ""),
+			ProviderHints:            hints,
+			ProviderClaim:            utils.GetEnv("OIDC_PROVIDER_CLAIM", ""),
+			GroupsClaim:              utils.GetEnv("OIDC_GROUPS_CLAIM", "groups"),
+		})
+	case repository.IdentityProviderDev:
+		// 任意のユーザーとしてログインできるため、開発環境を明示した場合のみ起動する
+		if os.Getenv("GO_ENV") != "development" {
+			log.Fatalf("IDENTITY_PROVIDER=dev requires GO_ENV=development")
+		}
+		log.Printf("WARNING: Using the development identity provider. Do not use in production")
+		identityProvider, err = repository.NewDevIdentityProvider(repository.DevProviderConfig{
+			Subject: utils.GetEnv("DEV_USER_SUB", "dev-user"),
+			Email:   utils.GetEnv("DEV_USER_EMAIL", "dev@example.com"),
+			Name:    utils.GetEnv("DEV_USER_NAME", "Dev User"),
+			Groups:  strings.Fields(utils.GetEnv("DEV_USER_GROUPS", "")),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create development identity provider: %v", err)
+		}
+	default:
+		log.Fatalf("Invalid IDENTITY_PROVIDER: %s", kind)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := identityProvider.Discover(ctx); err != nil {
+		log.Fatalf("Failed to load OIDC discovery document: %v", err)
+	}
+
+	return identityProvider
+}
+
+func migrateTables(db *gorm.DB, adminRole string) error {
+	if err := db.AutoMigrate(
 		&domain.User{},
-	)
+		&domain.UserIdentity{},
+		&domain.OAuthState{},
+		&domain.RateLimitCounter{},
+		&domain.AccountDeletion{},
+		&domain.AdminAuditLog{},
+		&domain.Permission{},
+		&domain.Role{},
+		&domain.UserRole{},
+	); err != nil {
+		return err
+	}
+	if err := repository.BackfillUserIdentities(db); err != nil {
+		return err
+	}
+	return repository.SeedDefaultRoles(db, adminRole)
+}
+
+// startCleanupJob - 期限切れのレコードを定期的に削除する
+func startCleanupJob(ctx context.Context, name string, interval time.Duration, deleteExpired func(context.Context, time.Time) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				deleted, err := deleteExpired(ctx, now)
+				if err != nil {
+					log.Printf("Failed to delete expired %s: %v", name, err)
+					continue
+				}
+				if deleted > 0 {
+					log.Printf("Deleted %d expired %s", deleted, name)
+				}
+			}
+		}
+	}()
 }
 
 func main() {
 	utils.LoadEnvFile()
 
 	config := awsconfig.LoadCognitoConfig()
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	db := initDB()
 	defer database.Close(db)
 
-	if err := migrateTables(db); err != nil {
+	if err := migrateTables(db, config.AdminRole); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	log.Println("Database migration completed")
 
 	awsSession := initAWS(config.AWSRegion)
 
+	// 削除を受け付けたアカウントを物理削除するまでの猶予期間（この間に再ログインすると取り消せる）
+	deletionGracePeriod, err := time.ParseDuration(utils.GetEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"))
+	if err != nil {
+		log.Fatalf("Invalid ACCOUNT_DELETION_GRACE_PERIOD: %v", err)
+	}
+
+	// IDトークンのクレームとプロフィール項目の対応（不足する必須項目はuserInfoエンドポイントで補う）
+	profileClaimMappings, err := domain.ParseProfileClaimMappings(utils.GetEnv("PROFILE_CLAIM_MAPPINGS", ""))
+	if err != nil {
+		log.Fatalf("Invalid PROFILE_CLAIM_MAPPINGS: %v", err)
+	}
+	requiredProfileFields, err := domain.ParseProfileFields(utils.GetEnv("PROFILE_REQUIRED_CLAIMS", "email,name"))
+	if err != nil {
+		log.Fatalf("Invalid PROFILE_REQUIRED_CLAIMS: %v", err)
+	}
+
 	// リポジトリの初期化
 	userRepo := repository.NewUserRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	oauthStateRepo := repository.NewOAuthStateRepository(db)
+	rateLimitRepo := repository.NewRateLimitRepository(db)
+	deletionRepo := repository.NewAccountDeletionRepository(db)
+	adminAuditRepo := repository.NewAdminAuditRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+
+	// バックグラウンドジョブ
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	startCleanupJob(jobCtx, "OAuth states", 10*time.Minute, oauthStateRepo.DeleteExpired)
+	startCleanupJob(jobCtx, "rate limit counters", 10*time.Minute, rateLimitRepo.DeleteExpired)
 	
+	identityProvider := initIdentityProvider(config)
+	log.Printf("Identity provider: %s", identityProvider.Name())
+
 	authConfig := repository.AuthConfig{
-		CognitoDomain:    utils.GetEnv("COGNITO_DOMAIN_URL", ""),
-		UserPoolClientID: config.UserPoolClientID,
+		IdentityProvider: identityProvider,
 		AllowedDomains: []string{
 			"http://localhost:3000",
 			"http://localhost:5173",
 			utils.GetEnv("FE_URL", "http://localhost:5173"),
 		},
+		// infra/cognito.tf の logout_urls（汎用OIDCではIdPに登録したログアウト後のURL）と一致させる
+		LogoutURLs: []string{
+			utils.GetEnv("FE_URL", "http://localhost:5173"),
+			"http://localhost:5173",
+		},
 	}
 	authRepo := repository.NewAuthRepository(authConfig)
 
@@ -77,19 +227,69 @@ func main() {
 	authUsecase := usecase.NewAuthUsecase(
 		userRepo,
 		authRepo,
+		oauthStateRepo,
+		rateLimitRepo,
+		deletionRepo,
+		identityProvider,
 		awsSession,
 		config.UserPoolID,
+		config.UserPoolClientID,
 		config.JWTSecret,
+		config.MFAIssuer,
+		domain.ProfileClaimConfig{
+			Mappings: profileClaimMappings,
+			Required: requiredProfileFields,
+		},
+	)
+
+	// ロール階層（Cognitoのグループとローカルのロールの両方に適用する）
+	roleHierarchy, err := domain.ParseRoleHierarchy(config.RoleHierarchy)
+	if err != nil {
+		log.Fatalf("Invalid ROLE_HIERARCHY: %v", err)
+	}
+
+	// ロール割り当ての変更は最大でこの時間だけ遅れて反映される
+	permissionCacheTTL, err := time.ParseDuration(utils.GetEnv("PERMISSION_CACHE_TTL", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid PERMISSION_CACHE_TTL: %v", err)
+	}
+	permissionUsecase := usecase.NewPermissionUsecase(userRepo, permissionRepo, roleHierarchy, permissionCacheTTL)
+
+	accountUsecase := usecase.NewAccountUsecase(
+		userRepo,
+		identityRepo,
+		deletionRepo,
+		identityProvider,
+		permissionUsecase,
+		awsSession,
+		config.UserPoolID,
+		deletionGracePeriod,
 	)
+	startCleanupJob(jobCtx, "deleted accounts", time.Hour, accountUsecase.PurgeDeletedAccounts)
+
+	adminUsecase := usecase.NewAdminUsecase(
+		userRepo,
+		adminAuditRepo,
+		awsSession,
+		config.UserPoolID,
+	)
+
+	awsCredentialsUsecase := usecase.NewAWSCredentialsUsecase(awsSession, config.IdentityPoolID, config.CognitoIssuer())
 
 	// controllerの初期化
-	authController := controller.NewAuthController(authUsecase)
+	authController := controller.NewAuthController(authUsecase, awsCredentialsUsecase)
+	accountController := controller.NewAccountController(accountUsecase, permissionUsecase)
+	adminController := controller.NewAdminController(adminUsecase)
+
+	// 認証ミドルウェアの初期化
+	authMiddleware := middleware.NewAuthMiddleware(identityProvider, roleHierarchy)
+	permissionMiddleware := middleware.NewPermissionMiddleware(permissionUsecase)
 
 	// Echoサーバーの初期化
 	e := echo.New()
 	
 	// ルート設定
-	routes.SetupRoutes(e, authController)
+	routes.SetupRoutes(e, authController, accountController, adminController, authMiddleware, permissionMiddleware, config.AdminRole, identityProvider.Name() == repository.IdentityProviderCognito)
 
 	// サーバー起動（優雅な終了付き）
 	port := utils.GetEnv("PORT", "8080")
@@ -5,19 +5,44 @@ import (
 	"log"
 
 	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
 	"github.com/matthewyuh246/aws-cognito/pkg/database"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 	"gorm.io/gorm"
 )
 
-func runMigrationsUp(db *gorm.DB) error {
-	return db.AutoMigrate(
+func runMigrationsUp(db *gorm.DB, adminRole string) error {
+	if err := db.AutoMigrate(
 		&domain.User{},
-	)
+		&domain.UserIdentity{},
+		&domain.OAuthState{},
+		&domain.RateLimitCounter{},
+		&domain.AccountDeletion{},
+		&domain.AdminAuditLog{},
+		&domain.Permission{},
+		&domain.Role{},
+		&domain.UserRole{},
+	); err != nil {
+		return err
+	}
+	if err := repository.BackfillUserIdentities(db); err != nil {
+		return err
+	}
+	return repository.SeedDefaultRoles(db, adminRole)
 }
 
 func runMigrationsDown(db *gorm.DB) error {
 	return db.Migrator().DropTable(
+		&domain.UserRole{},
+		"role_permissions",
+		&domain.Role{},
+		&domain.Permission{},
+		&domain.AdminAuditLog{},
+		&domain.AccountDeletion{},
+		&domain.UserIdentity{},
 		&domain.User{},
+		&domain.OAuthState{},
+		&domain.RateLimitCounter{},
 	)
 }
 
@@ -35,7 +60,7 @@ func main() {
 
 	if *up {
 		log.Println("Running migrations up...")
-		if err := runMigrationsUp(db); err != nil {
+		if err := runMigrationsUp(db, utils.GetEnv("ADMIN_ROLE", "admin")); err != nil {
 			log.Fatalf("Failed to run migrations up: %v", err)
 		}
 		log.Println("Migrations up completed successfully")
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/internal/usecase"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+)
+
+type AccountController struct {
+	accountUsecase    usecase.IAccountUsecase
+	permissionUsecase usecase.IPermissionUsecase
+	logger            *logger.Logger
+}
+
+func NewAccountController(accountUsecase usecase.IAccountUsecase, permissionUsecase usecase.IPermissionUsecase) *AccountController {
+	return &AccountController{
+		accountUsecase:    accountUsecase,
+		permissionUsecase: permissionUsecase,
+		logger:            logger.New("ACCOUNT_CONTROLLER"),
+	}
+}
+
+// ListIdentities - 連携済みのログイン方法の一覧
+func (ac *AccountController) ListIdentities(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	identities, err := ac.accountUsecase.ListIdentities(c.Request().Context(), claims)
+	if err != nil {
+		ac.logger.Error("ID一覧取得エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendIdentities(c, identities)
+}
+
+// LinkIdentity - 別のログイン方法のIDトークンでIDを連携する
+func (ac *AccountController) LinkIdentity(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	var req request.LinkIdentityRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	identity, err := ac.accountUsecase.LinkIdentity(c.Request().Context(), claims, req.IDToken)
+	if err != nil {
+		ac.logger.Error("ID連携エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("ID連携成功", map[string]interface{}{
+		"sub":         claims.Sub,
+		"provider":    identity.Provider,
+		"identity_id": identity.ID,
+	})
+
+	return response.SendIdentityLinked(c, identity)
+}
+
+// UnlinkIdentity - IDの連携を解除する
+func (ac *AccountController) UnlinkIdentity(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	identityID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return response.SendBadRequest(c, "無効なIDです")
+	}
+
+	if err := ac.accountUsecase.UnlinkIdentity(c.Request().Context(), claims, uint(identityID)); err != nil {
+		ac.logger.Error("ID連携解除エラー", map[string]interface{}{
+			"sub":         claims.Sub,
+			"identity_id": identityID,
+			"error":       err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("ID連携解除成功", map[string]interface{}{
+		"sub":         claims.Sub,
+		"identity_id": identityID,
+	})
+
+	return response.SendSuccess(c, "連携を解除しました")
+}
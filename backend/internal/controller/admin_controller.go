@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/usecase"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+)
+
+type AdminController struct {
+	adminUsecase usecase.IAdminUsecase
+	logger       *logger.Logger
+}
+
+func NewAdminController(adminUsecase usecase.IAdminUsecase) *AdminController {
+	return &AdminController{
+		adminUsecase: adminUsecase,
+		logger:       logger.New("ADMIN_CONTROLLER"),
+	}
+}
+
+// ListUsers - ユーザー一覧（email・status・enabledのいずれか1つで絞り込み可能）
+func (ac *AdminController) ListUsers(c echo.Context) error {
+	actor, ok := adminActor(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	var req request.AdminListUsersRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	page, err := ac.adminUsecase.ListUsers(c.Request().Context(), actor, req.ToQuery())
+	if err != nil {
+		ac.logger.Error("ユーザー一覧取得エラー", map[string]interface{}{
+			"actor": actor.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendAdminUsers(c, page)
+}
+
+// GetUser - ユーザーの詳細
+func (ac *AdminController) GetUser(c echo.Context) error {
+	actor, ok := adminActor(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	username := c.Param("username")
+	user, err := ac.adminUsecase.GetUser(c.Request().Context(), actor, username)
+	if err != nil {
+		ac.logger.Error("ユーザー取得エラー", map[string]interface{}{
+			"actor":  actor.Sub,
+			"target": username,
+			"error":  err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendAdminUser(c, user)
+}
+
+// DisableUser - ユーザーを無効化する
+func (ac *AdminController) DisableUser(c echo.Context) error {
+	return ac.runUserAction(c, "ユーザー無効化", "ユーザーを無効化しました", ac.adminUsecase.DisableUser)
+}
+
+// EnableUser - ユーザーを有効化する
+func (ac *AdminController) EnableUser(c echo.Context) error {
+	return ac.runUserAction(c, "ユーザー有効化", "ユーザーを有効化しました", ac.adminUsecase.EnableUser)
+}
+
+// ResetUserPassword - パスワードをリセットし、再設定コードを送信する
+func (ac *AdminController) ResetUserPassword(c echo.Context) error {
+	return ac.runUserAction(c, "パスワードリセット", "パスワードをリセットしました", ac.adminUsecase.ResetUserPassword)
+}
+
+// GlobalSignOutUser - ユーザーを全デバイスからサインアウトさせる
+func (ac *AdminController) GlobalSignOutUser(c echo.Context) error {
+	return ac.runUserAction(c, "グローバルサインアウト", "ユーザーをサインアウトさせました", ac.adminUsecase.GlobalSignOutUser)
+}
+
+// AddUserToGroup - ユーザーをグループ（ロール）に追加する
+func (ac *AdminController) AddUserToGroup(c echo.Context) error {
+	var req request.AdminGroupRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	return ac.runUserAction(c, "グループ追加", "グループに追加しました", func(ctx context.Context, actor domain.AdminActor, username string) error {
+		return ac.adminUsecase.AddUserToGroup(ctx, actor, username, req.Group)
+	})
+}
+
+// RemoveUserFromGroup - ユーザーをグループ（ロール）から外す
+func (ac *AdminController) RemoveUserFromGroup(c echo.Context) error {
+	group := c.Param("group")
+	return ac.runUserAction(c, "グループ削除", "グループから外しました", func(ctx context.Context, actor domain.AdminActor, username string) error {
+		return ac.adminUsecase.RemoveUserFromGroup(ctx, actor, username, group)
+	})
+}
+
+// runUserAction - :username のユーザーに対する操作を実行する
+func (ac *AdminController) runUserAction(c echo.Context, name, successMessage string, action func(ctx context.Context, actor domain.AdminActor, username string) error) error {
+	actor, ok := adminActor(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	username := c.Param("username")
+	if err := action(c.Request().Context(), actor, username); err != nil {
+		ac.logger.Error(name+"エラー", map[string]interface{}{
+			"actor":  actor.Sub,
+			"target": username,
+			"error":  err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info(name+"成功", map[string]interface{}{
+		"actor":  actor.Sub,
+		"target": username,
+	})
+
+	return response.SendSuccess(c, successMessage)
+}
+
+// adminActor - 監査ログに記録する操作者の情報
+func adminActor(c echo.Context) (domain.AdminActor, bool) {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return domain.AdminActor{}, false
+	}
+	return domain.AdminActor{
+		Sub:      claims.Sub,
+		Username: claims.Username,
+		Email:    claims.Email,
+		IP:       c.RealIP(),
+	}, true
+}
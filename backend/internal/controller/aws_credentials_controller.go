@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+)
+
+// GetAWSCredentials - IDプールの一時的なAWS認証情報を発行する（S3への直接アップロード用）
+// IDプールはユーザープールのIDトークンのみを受け付けるため、BearerトークンはIDトークンでなければならない
+func (ac *AuthController) GetAWSCredentials(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok || claims.TokenUse != "id" {
+		return response.SendUnauthorized(c, "IDトークンが必要です")
+	}
+	idToken, ok := middleware.GetRawToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "IDトークンが必要です")
+	}
+
+	credentials, err := ac.awsCredentialsUsecase.GetCredentials(c.Request().Context(), claims, idToken)
+	if err != nil {
+		ac.logger.Error("AWS認証情報取得エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("AWS認証情報発行", map[string]interface{}{
+		"sub":         claims.Sub,
+		"identity_id": credentials.IdentityID,
+		"expiration":  credentials.Expiration,
+	})
+
+	return response.SendAWSCredentials(c, credentials)
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+)
+
+// RespondToChallenge - 認証チャレンジへの応答（トークンが発行されるまで繰り返す）
+func (ac *AuthController) RespondToChallenge(c echo.Context) error {
+	var req request.ChallengeRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	tokens, user, challenge, err := ac.authUsecase.RespondToChallenge(c.Request().Context(), req.Session, req.Responses)
+	if err != nil {
+		ac.logger.Error("チャレンジ応答エラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	if challenge != nil {
+		ac.logger.Info("追加のチャレンジ", map[string]interface{}{
+			"challenge": challenge.Name,
+		})
+		return response.SendChallenge(c, challenge)
+	}
+
+	ac.logger.Info("チャレンジ応答によるログイン成功", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return response.SendLoginSuccess(c, tokens, user)
+}
+
+// AssociateTOTP - 認証アプリ登録用のシークレットとotpauth URIを発行する
+func (ac *AuthController) AssociateTOTP(c echo.Context) error {
+	claims, accessToken, ok := requireAccessToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "アクセストークンが必要です")
+	}
+
+	accountName := claims.Email
+	if accountName == "" {
+		accountName = claims.Username
+	}
+
+	enrollment, err := ac.authUsecase.AssociateSoftwareToken(c.Request().Context(), accessToken, accountName)
+	if err != nil {
+		ac.logger.Error("認証アプリ登録エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendTOTPEnrollment(c, enrollment)
+}
+
+// VerifyTOTP - 認証アプリのコードで登録を完了する
+func (ac *AuthController) VerifyTOTP(c echo.Context) error {
+	claims, accessToken, ok := requireAccessToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "アクセストークンが必要です")
+	}
+
+	var req request.VerifyTOTPRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	if err := ac.authUsecase.VerifySoftwareToken(c.Request().Context(), accessToken, req.Code, req.DeviceName); err != nil {
+		ac.logger.Error("認証アプリ検証エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("認証アプリ登録成功", map[string]interface{}{
+		"sub": claims.Sub,
+	})
+
+	return response.SendSuccess(c, "認証アプリを登録しました")
+}
+
+// SetMFAPreference - MFAの有効化・優先設定を更新する
+func (ac *AuthController) SetMFAPreference(c echo.Context) error {
+	claims, accessToken, ok := requireAccessToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "アクセストークンが必要です")
+	}
+
+	var req request.MFAPreferenceRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	preference := domain.MFAPreference{
+		TOTPEnabled:   *req.TOTPEnabled,
+		TOTPPreferred: req.TOTPPreferred,
+	}
+
+	if err := ac.authUsecase.SetMFAPreference(c.Request().Context(), accessToken, preference); err != nil {
+		ac.logger.Error("MFA設定エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("MFA設定更新", map[string]interface{}{
+		"sub":            claims.Sub,
+		"totp_enabled":   preference.TOTPEnabled,
+		"totp_preferred": preference.TOTPPreferred,
+	})
+
+	return response.SendSuccess(c, "MFAの設定を更新しました")
+}
+
+// requireAccessToken - ユーザー自身の操作を行うCognito APIにはアクセストークンが必要（IDトークンでは不可）
+func requireAccessToken(c echo.Context) (*domain.UserClaims, string, bool) {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok || claims.TokenUse != "access" {
+		return nil, "", false
+	}
+
+	token, ok := middleware.GetRawToken(c)
+	if !ok {
+		return nil, "", false
+	}
+	return claims, token, true
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+// SignUp - メールアドレス・パスワードでのサインアップ
+func (ac *AuthController) SignUp(c echo.Context) error {
+	var req request.SignUpRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	result, err := ac.authUsecase.SignUp(c.Request().Context(), req.Email, req.Password, req.Name)
+	if err != nil {
+		ac.logger.Error("サインアップエラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("サインアップ成功", map[string]interface{}{
+		"user_sub":  result.UserSub,
+		"confirmed": result.UserConfirmed,
+	})
+
+	return response.SendSignUpSuccess(c, result)
+}
+
+// ConfirmSignUp - サインアップの確認コード検証
+func (ac *AuthController) ConfirmSignUp(c echo.Context) error {
+	var req request.ConfirmSignUpRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	if err := ac.authUsecase.ConfirmSignUp(c.Request().Context(), req.Email, req.Code); err != nil {
+		ac.logger.Error("サインアップ確認エラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("サインアップ確認成功", map[string]interface{}{
+		"email": maskEmail(req.Email),
+	})
+
+	return response.SendSuccess(c, "メールアドレスを確認しました")
+}
+
+// ResendConfirmationCode - 確認コードの再送信
+func (ac *AuthController) ResendConfirmationCode(c echo.Context) error {
+	var req request.ResendCodeRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	details, err := ac.authUsecase.ResendConfirmationCode(c.Request().Context(), req.Email)
+	if err != nil {
+		ac.logger.Error("確認コード再送信エラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendCodeDelivery(c, details)
+}
+
+// LoginWithPassword - メールアドレス・パスワードでのログイン
+func (ac *AuthController) LoginWithPassword(c echo.Context) error {
+	var req request.PasswordLoginRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	tokens, user, challenge, err := ac.authUsecase.LoginWithPassword(c.Request().Context(), req.Email, req.Password, req.Mode)
+	if err != nil {
+		ac.logger.Error("パスワードログインエラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"mode":  req.Mode,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	if challenge != nil {
+		ac.logger.Info("パスワードログインでチャレンジが必要", map[string]interface{}{
+			"email":     maskEmail(req.Email),
+			"challenge": challenge.Name,
+		})
+		return response.SendChallenge(c, challenge)
+	}
+
+	ac.logger.Info("パスワードログイン成功", map[string]interface{}{
+		"user_id": user.ID,
+		"mode":    req.Mode,
+	})
+
+	return response.SendLoginSuccess(c, tokens, user)
+}
+
+// ForgotPassword - パスワード再設定コードの送信
+// アカウントの存在有無が分からないよう、登録の有無にかかわらず同じ応答を返す
+func (ac *AuthController) ForgotPassword(c echo.Context) error {
+	var req request.ForgotPasswordRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	if err := ac.authUsecase.ForgotPassword(c.Request().Context(), req.Email); err != nil {
+		ac.logger.Error("パスワード再設定コード送信エラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendSuccess(c, "登録済みのメールアドレスの場合、パスワード再設定用のコードを送信しました")
+}
+
+// ResetPassword - 確認コードによるパスワード再設定
+func (ac *AuthController) ResetPassword(c echo.Context) error {
+	var req request.ResetPasswordRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	if err := ac.authUsecase.ResetPassword(c.Request().Context(), req.Email, req.Code, req.NewPassword); err != nil {
+		ac.logger.Error("パスワード再設定エラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("パスワード再設定成功", map[string]interface{}{
+		"email": maskEmail(req.Email),
+	})
+
+	return response.SendSuccess(c, "パスワードを再設定しました")
+}
+
+// StartPasswordlessLogin - メールにワンタイムコードを送信し、入力待ちのチャレンジを返す
+func (ac *AuthController) StartPasswordlessLogin(c echo.Context) error {
+	var req request.PasswordlessStartRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	challenge, err := ac.authUsecase.StartPasswordlessLogin(c.Request().Context(), req.Email)
+	if err != nil {
+		ac.logger.Error("パスワードレスログイン開始エラー", map[string]interface{}{
+			"email": maskEmail(req.Email),
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendChallenge(c, challenge)
+}
+
+// VerifyPasswordlessLogin - ワンタイムコードでログインする
+func (ac *AuthController) VerifyPasswordlessLogin(c echo.Context) error {
+	var req request.PasswordlessVerifyRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	tokens, user, challenge, err := ac.authUsecase.AnswerPasswordlessLogin(c.Request().Context(), req.Session, req.Code)
+	if err != nil {
+		ac.logger.Error("パスワードレスログインエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	if challenge != nil {
+		// コードの誤り（試行回数の上限まで再入力できる）
+		return response.SendChallenge(c, challenge)
+	}
+
+	ac.logger.Info("パスワードレスログイン成功", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return response.SendLoginSuccess(c, tokens, user)
+}
+
+// maskEmail - ログ出力用にメールアドレスのローカル部をマスクする
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return utils.MaskSensitiveData(email, 1, 0, "")
+	}
+	return utils.MaskSensitiveData(local, 1, 0, "") + "@" + domain
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+)
+
+// GetProfile - ログイン中のユーザーのプロフィール
+func (ac *AccountController) GetProfile(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	user, err := ac.accountUsecase.GetProfile(c.Request().Context(), claims)
+	if err != nil {
+		ac.logger.Error("プロフィール取得エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendProfile(c, user)
+}
+
+// UpdateProfile - プロフィールを更新する
+// name・pictureの変更はCognitoにも反映するため、アクセストークンでの呼び出しが必要
+func (ac *AccountController) UpdateProfile(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	var req request.UpdateProfileRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	accessToken := ""
+	if _, token, ok := requireAccessToken(c); ok {
+		accessToken = token
+	}
+
+	user, sync, err := ac.accountUsecase.UpdateProfile(c.Request().Context(), claims, accessToken, req.ToProfileUpdate())
+	if err != nil {
+		ac.logger.Error("プロフィール更新エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"sync":  sync,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("プロフィール更新成功", map[string]interface{}{
+		"sub":  claims.Sub,
+		"sync": sync,
+	})
+
+	return response.SendProfileUpdated(c, user, sync)
+}
+
+// VerifyAttribute - Cognitoの属性（email・phone_number）の変更・確認
+func (ac *AccountController) VerifyAttribute(c echo.Context) error {
+	claims, accessToken, ok := requireAccessToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "アクセストークンが必要です")
+	}
+
+	var req request.VerifyAttributeRequest
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	verification, err := ac.accountUsecase.VerifyAttribute(c.Request().Context(), claims, accessToken, req.Attribute, req.Value, req.Code)
+	if err != nil {
+		ac.logger.Error("属性確認エラー", map[string]interface{}{
+			"sub":       claims.Sub,
+			"attribute": req.Attribute,
+			"error":     err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("属性確認", map[string]interface{}{
+		"sub":       claims.Sub,
+		"attribute": req.Attribute,
+		"verified":  verification.Verified,
+	})
+
+	return response.SendAttributeVerification(c, verification)
+}
+
+// DeleteAccount - アカウントを削除する（直近にログインしたセッションのアクセストークンが必要）
+// 猶予期間内に再ログインすると削除を取り消せる
+func (ac *AccountController) DeleteAccount(c echo.Context) error {
+	claims, accessToken, ok := requireAccessToken(c)
+	if !ok {
+		return response.SendUnauthorized(c, "アクセストークンが必要です")
+	}
+
+	deletion, err := ac.accountUsecase.DeleteAccount(c.Request().Context(), claims, accessToken)
+	if err != nil {
+		ac.logger.Error("アカウント削除エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("アカウント削除受付", map[string]interface{}{
+		"sub":      claims.Sub,
+		"user_id":  deletion.UserID,
+		"purge_at": deletion.PurgeAt,
+	})
+
+	return response.SendAccountDeletion(c, deletion)
+}
+
+// GetPermissions - 実効ロールと実効権限（フロントエンドでの表示制御用。認可はサーバー側で行う）
+func (ac *AccountController) GetPermissions(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	permissions, err := ac.permissionUsecase.GetPermissions(c.Request().Context(), claims)
+	if err != nil {
+		ac.logger.Error("権限取得エラー", map[string]interface{}{
+			"sub":   claims.Sub,
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	return response.SendPermissions(c, permissions)
+}
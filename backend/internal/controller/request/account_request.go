@@ -0,0 +1,24 @@
+package request
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// LinkIdentityRequest - ID連携リクエスト
+type LinkIdentityRequest struct {
+	// IDToken - 連携するログイン方法で直近に取得したIDトークン
+	IDToken string `json:"id_token" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *LinkIdentityRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.IDToken == "" {
+		return echo.NewHTTPError(400, "id_token is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// maxGroupNameLength - Cognitoのグループ名の上限
+const maxGroupNameLength = 128
+
+// AdminGroupRequest - グループへの追加リクエスト
+type AdminGroupRequest struct {
+	Group string `json:"group" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *AdminGroupRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	r.Group = strings.TrimSpace(r.Group)
+	if r.Group == "" {
+		return echo.NewHTTPError(400, "group is required")
+	}
+	if len(r.Group) > maxGroupNameLength {
+		return echo.NewHTTPError(400, "group is too long")
+	}
+
+	return nil
+}
+
+// AdminListUsersRequest - 管理者向けユーザー一覧のクエリパラメータ
+type AdminListUsersRequest struct {
+	Email     string `query:"email"`
+	Status    string `query:"status"`
+	Enabled   string `query:"enabled"`
+	Limit     int    `query:"limit"`
+	PageToken string `query:"page_token"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *AdminListUsersRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	r.Status = strings.ToUpper(strings.TrimSpace(r.Status))
+
+	// CognitoのFilter式に埋め込むため、引用符とバックスラッシュを含む値は受け付けない
+	if strings.ContainsAny(r.Email, `"\`) || strings.ContainsAny(r.Status, `"\`) {
+		return echo.NewHTTPError(400, "invalid filter value")
+	}
+
+	if r.Enabled != "" {
+		if _, err := strconv.ParseBool(r.Enabled); err != nil {
+			return echo.NewHTTPError(400, "enabled must be true or false")
+		}
+	}
+
+	if r.Limit < 0 {
+		return echo.NewHTTPError(400, "limit must be positive")
+	}
+
+	return nil
+}
+
+// ToQuery - ドメインの検索条件に変換
+func (r *AdminListUsersRequest) ToQuery() domain.AdminUserQuery {
+	query := domain.AdminUserQuery{
+		Email:     r.Email,
+		Status:    r.Status,
+		Limit:     r.Limit,
+		PageToken: r.PageToken,
+	}
+	if r.Enabled != "" {
+		enabled, _ := strconv.ParseBool(r.Enabled)
+		query.Enabled = &enabled
+	}
+	return query
+}
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAdminListUsersRequest_BindAndValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantErr   bool
+		wantEmail string
+	}{
+		{name: "normalized email", query: url.Values{"email": {"  Alice@Example.com "}}, wantEmail: "alice@example.com"},
+		{name: "quote in email", query: url.Values{"email": {`a" or email ^= "`}}, wantErr: true},
+		{name: "backslash in email", query: url.Values{"email": {`alice\`}}, wantErr: true},
+		{name: "quote in status", query: url.Values{"status": {`confirmed"`}}, wantErr: true},
+		{name: "invalid enabled", query: url.Values{"enabled": {"yes"}}, wantErr: true},
+		{name: "negative limit", query: url.Values{"limit": {"-1"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/?"+tt.query.Encode(), nil), httptest.NewRecorder())
+
+			var req AdminListUsersRequest
+			err := req.BindAndValidate(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BindAndValidate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && req.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", req.Email, tt.wantEmail)
+			}
+		})
+	}
+}
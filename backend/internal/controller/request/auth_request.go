@@ -4,11 +4,26 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+var allowedProviders = map[string]bool{
+	"google":   true,
+	"github":   true,
+	"facebook": true,
+	"cognito":  true,
+}
+
+const (
+	AuthorizeModeJSON     = "json"
+	AuthorizeModeRedirect = "redirect"
+)
+
 // LoginRequest - ソーシャルログインリクエスト
 type LoginRequest struct {
-	Provider string `json:"provider" validate:"required,oneof=google github facebook"`
+	Provider string `json:"provider" validate:"required,oneof=google github facebook cognito"`
 	Code     string `json:"code" validate:"required"`
-	State    string `json:"state,omitempty"`
+	// State - /auth/authorize/:provider で発行されたstate
+	State    string `json:"state" validate:"required"`
+	// CodeVerifier - SPA側でPKCEを生成した場合のみ指定（サーバー生成時はstateから解決）
+	CodeVerifier string `json:"code_verifier,omitempty"`
 }
 
 // BindAndValidate - リクエストをバインドして検証
@@ -25,17 +40,96 @@ func (r *LoginRequest) BindAndValidate(c echo.Context) error {
 	if r.Code == "" {
 		return echo.NewHTTPError(400, "code is required")
 	}
+
+	if r.State == "" {
+		return echo.NewHTTPError(400, "state is required")
+	}
 	
 	// プロバイダーの検証
-	allowedProviders := map[string]bool{
-		"google":   true,
-		"github":   true,
-		"facebook": true,
+	if !allowedProviders[r.Provider] {
+		return echo.NewHTTPError(400, "invalid provider")
 	}
 	
+	return nil
+}
+
+// AuthorizeRequest - 認可リクエスト（state・nonce・PKCE）の発行リクエスト
+type AuthorizeRequest struct {
+	Provider string `param:"provider" validate:"required,oneof=google github facebook cognito"`
+	// RedirectURI - 省略時は FE_URL + /auth/callback
+	RedirectURI string `query:"redirect_uri"`
+	// Mode - json（既定）または redirect（302で認可URLへ遷移）
+	Mode string `query:"mode"`
+	// CodeChallenge - SPA側でPKCEを生成する場合のみ指定
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *AuthorizeRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
 	if !allowedProviders[r.Provider] {
 		return echo.NewHTTPError(400, "invalid provider")
 	}
-	
+
+	if r.Mode == "" {
+		r.Mode = AuthorizeModeJSON
+	}
+	if r.Mode != AuthorizeModeJSON && r.Mode != AuthorizeModeRedirect {
+		return echo.NewHTTPError(400, "invalid mode")
+	}
+
+	if r.CodeChallenge != "" {
+		// plainは許可しない（S256のchallengeはbase64urlで43文字）
+		if r.CodeChallengeMethod != "S256" {
+			return echo.NewHTTPError(400, "code_challenge_method must be S256")
+		}
+		if len(r.CodeChallenge) != 43 {
+			return echo.NewHTTPError(400, "invalid code_challenge")
+		}
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+// RefreshRequest - トークン更新リクエスト
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *RefreshRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.RefreshToken == "" {
+		return echo.NewHTTPError(400, "refresh_token is required")
+	}
+
+	return nil
+}
+
+// LogoutRequest - ログアウトリクエスト
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	// GlobalSignOut - trueの場合、Authorizationヘッダーのアクセストークンで全デバイスからサインアウトする
+	GlobalSignOut bool   `json:"global_sign_out,omitempty"`
+	LogoutURI     string `json:"logout_uri,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *LogoutRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.RefreshToken == "" {
+		return echo.NewHTTPError(400, "refresh_token is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ChallengeRequest - 認証チャレンジへの応答リクエスト
+type ChallengeRequest struct {
+	// Session - ログインまたは前回のチャレンジ応答で返されたsession
+	Session string `json:"session" validate:"required"`
+	// Responses - チャレンジの parameters に列挙されたキーと値
+	Responses map[string]string `json:"responses" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *ChallengeRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.Session == "" {
+		return echo.NewHTTPError(400, "session is required")
+	}
+
+	if len(r.Responses) == 0 {
+		return echo.NewHTTPError(400, "responses is required")
+	}
+
+	return nil
+}
+
+// VerifyTOTPRequest - 認証アプリの登録確認リクエスト
+type VerifyTOTPRequest struct {
+	Code       string `json:"code" validate:"required"`
+	DeviceName string `json:"device_name,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *VerifyTOTPRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return echo.NewHTTPError(400, "code is required")
+	}
+
+	return nil
+}
+
+// MFAPreferenceRequest - MFA設定の更新リクエスト
+type MFAPreferenceRequest struct {
+	TOTPEnabled   *bool `json:"totp_enabled" validate:"required"`
+	TOTPPreferred bool  `json:"totp_preferred,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *MFAPreferenceRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.TOTPEnabled == nil {
+		return echo.NewHTTPError(400, "totp_enabled is required")
+	}
+
+	return nil
+}
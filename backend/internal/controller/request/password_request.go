@@ -0,0 +1,203 @@
+package request
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// SignUpRequest - メールアドレス・パスワードでのサインアップリクエスト
+type SignUpRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Name     string `json:"name,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *SignUpRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if err := normalizeEmail(&r.Email); err != nil {
+		return err
+	}
+
+	if r.Password == "" {
+		return echo.NewHTTPError(400, "password is required")
+	}
+
+	r.Name = strings.TrimSpace(r.Name)
+
+	return nil
+}
+
+// ConfirmSignUpRequest - 確認コードの検証リクエスト
+type ConfirmSignUpRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Code  string `json:"code" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *ConfirmSignUpRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if err := normalizeEmail(&r.Email); err != nil {
+		return err
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return echo.NewHTTPError(400, "code is required")
+	}
+
+	return nil
+}
+
+// ResendCodeRequest - 確認コードの再送信リクエスト
+type ResendCodeRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *ResendCodeRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	return normalizeEmail(&r.Email)
+}
+
+// PasswordLoginRequest - メールアドレス・パスワードでのログインリクエスト
+type PasswordLoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	// Mode - password（既定、USER_PASSWORD_AUTH）または srp（USER_SRP_AUTH）
+	Mode string `json:"mode,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *PasswordLoginRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if err := normalizeEmail(&r.Email); err != nil {
+		return err
+	}
+
+	if r.Password == "" {
+		return echo.NewHTTPError(400, "password is required")
+	}
+
+	if r.Mode == "" {
+		r.Mode = domain.PasswordLoginModePassword
+	}
+	if r.Mode != domain.PasswordLoginModePassword && r.Mode != domain.PasswordLoginModeSRP {
+		return echo.NewHTTPError(400, "invalid mode")
+	}
+
+	return nil
+}
+
+// ForgotPasswordRequest - パスワード再設定コードの送信リクエスト
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *ForgotPasswordRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	return normalizeEmail(&r.Email)
+}
+
+// ResetPasswordRequest - パスワード再設定リクエスト
+type ResetPasswordRequest struct {
+	Email       string `json:"email" validate:"required,email"`
+	Code        string `json:"code" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *ResetPasswordRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if err := normalizeEmail(&r.Email); err != nil {
+		return err
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return echo.NewHTTPError(400, "code is required")
+	}
+
+	if r.NewPassword == "" {
+		return echo.NewHTTPError(400, "new_password is required")
+	}
+
+	return nil
+}
+
+// PasswordlessStartRequest - パスワードレスログイン（メールのワンタイムコード）の開始リクエスト
+type PasswordlessStartRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *PasswordlessStartRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	return normalizeEmail(&r.Email)
+}
+
+// PasswordlessVerifyRequest - ワンタイムコードの入力リクエスト
+type PasswordlessVerifyRequest struct {
+	// Session - 開始時（またはコード誤り時）に返されたチャレンジのsession
+	Session string `json:"session" validate:"required"`
+	Code    string `json:"code" validate:"required"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *PasswordlessVerifyRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.Session == "" {
+		return echo.NewHTTPError(400, "session is required")
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return echo.NewHTTPError(400, "code is required")
+	}
+
+	return nil
+}
+
+// normalizeEmail - メールアドレスを検証し、前後の空白除去と小文字化を行う
+// ユーザープールはメールアドレスをユーザー名として使うため、表記揺れで別ユーザーにならないようにする
+func normalizeEmail(email *string) error {
+	*email = strings.ToLower(strings.TrimSpace(*email))
+	if *email == "" {
+		return echo.NewHTTPError(400, "email is required")
+	}
+
+	addr, err := mail.ParseAddress(*email)
+	if err != nil || addr.Address != *email {
+		return echo.NewHTTPError(400, "invalid email")
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package request
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	maxNameLength    = 256
+	maxPictureLength = 2048
+)
+
+// usernamePattern - 英数字と _ . - の3〜30文字
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,30}$`)
+
+// UpdateProfileRequest - プロフィール更新リクエスト（省略した項目は変更しない）
+type UpdateProfileRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Picture  *string `json:"picture,omitempty"`
+	Username *string `json:"username,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *UpdateProfileRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	if r.Name == nil && r.Picture == nil && r.Username == nil {
+		return echo.NewHTTPError(400, "name, picture or username is required")
+	}
+
+	if r.Name != nil {
+		*r.Name = strings.TrimSpace(*r.Name)
+		if utf8.RuneCountInString(*r.Name) > maxNameLength {
+			return echo.NewHTTPError(400, "name is too long")
+		}
+	}
+
+	if r.Picture != nil {
+		*r.Picture = strings.TrimSpace(*r.Picture)
+		if *r.Picture != "" {
+			u, err := url.Parse(*r.Picture)
+			if err != nil || u.Scheme != "https" || u.Host == "" || len(*r.Picture) > maxPictureLength {
+				return echo.NewHTTPError(400, "picture must be an https URL")
+			}
+		}
+	}
+
+	if r.Username != nil {
+		*r.Username = strings.TrimSpace(*r.Username)
+		if !usernamePattern.MatchString(*r.Username) {
+			return echo.NewHTTPError(400, "invalid username")
+		}
+	}
+
+	return nil
+}
+
+// ToProfileUpdate - ドメインの更新内容に変換
+func (r *UpdateProfileRequest) ToProfileUpdate() domain.ProfileUpdate {
+	return domain.ProfileUpdate{
+		Name:     r.Name,
+		Picture:  r.Picture,
+		Username: r.Username,
+	}
+}
+
+// VerifyAttributeRequest - Cognito属性の変更・確認リクエスト
+type VerifyAttributeRequest struct {
+	// Attribute - email または phone_number
+	Attribute string `json:"attribute" validate:"required"`
+	// Value - 新しい値（指定すると確認コードが送信される）
+	Value string `json:"value,omitempty"`
+	// Code - 受け取った確認コード（指定すると確認を完了する）
+	Code string `json:"code,omitempty"`
+}
+
+// BindAndValidate - リクエストをバインドして検証
+func (r *VerifyAttributeRequest) BindAndValidate(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+
+	r.Attribute = strings.TrimSpace(r.Attribute)
+	if r.Attribute == "" {
+		return echo.NewHTTPError(400, "attribute is required")
+	}
+
+	r.Value = strings.TrimSpace(r.Value)
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Value != "" && r.Code != "" {
+		return echo.NewHTTPError(400, "value and code cannot be specified together")
+	}
+
+	if r.Value != "" && r.Attribute == "email" {
+		return normalizeEmail(&r.Value)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package response
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// IdentityInfo - 連携済みのログイン方法
+type IdentityInfo struct {
+	ID        uint      `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdentitiesResponse - 連携済みID一覧レスポンス
+type IdentitiesResponse struct {
+	Success    bool           `json:"success"`
+	Identities []IdentityInfo `json:"identities"`
+}
+
+// IdentityResponse - ID連携レスポンス
+type IdentityResponse struct {
+	Success  bool         `json:"success"`
+	Message  string       `json:"message"`
+	Identity IdentityInfo `json:"identity"`
+}
+
+// SuccessResponse - 本文を持たない成功レスポンス
+type SuccessResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// SendIdentities - 連携済みID一覧を送信
+func SendIdentities(c echo.Context, identities []domain.UserIdentity) error {
+	infos := make([]IdentityInfo, 0, len(identities))
+	for i := range identities {
+		infos = append(infos, newIdentityInfo(&identities[i]))
+	}
+
+	return c.JSON(http.StatusOK, IdentitiesResponse{
+		Success:    true,
+		Identities: infos,
+	})
+}
+
+// SendIdentityLinked - ID連携成功レスポンスを送信
+func SendIdentityLinked(c echo.Context, identity *domain.UserIdentity) error {
+	return c.JSON(http.StatusCreated, IdentityResponse{
+		Success:  true,
+		Message:  "ログイン方法を連携しました",
+		Identity: newIdentityInfo(identity),
+	})
+}
+
+// SendSuccess - 成功メッセージを送信
+func SendSuccess(c echo.Context, message string) error {
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+func newIdentityInfo(identity *domain.UserIdentity) IdentityInfo {
+	return IdentityInfo{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// AdminUsersResponse - 管理者向けユーザー一覧レスポンス
+type AdminUsersResponse struct {
+	Success       bool               `json:"success"`
+	Users         []domain.AdminUser `json:"users"`
+	NextPageToken string             `json:"next_page_token,omitempty"`
+}
+
+// AdminUserResponse - 管理者向けユーザー詳細レスポンス
+type AdminUserResponse struct {
+	Success bool              `json:"success"`
+	User    *domain.AdminUser `json:"user"`
+}
+
+// SendAdminUsers - ユーザー一覧を送信
+func SendAdminUsers(c echo.Context, page *domain.AdminUserPage) error {
+	return c.JSON(http.StatusOK, AdminUsersResponse{
+		Success:       true,
+		Users:         page.Users,
+		NextPageToken: page.NextPageToken,
+	})
+}
+
+// SendAdminUser - ユーザー詳細を送信
+func SendAdminUser(c echo.Context, user *domain.AdminUser) error {
+	return c.JSON(http.StatusOK, AdminUserResponse{
+		Success: true,
+		User:    user,
+	})
+}
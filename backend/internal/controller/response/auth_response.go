@@ -1,7 +1,9 @@
 package response
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/matthewyuh246/aws-cognito/internal/domain"
@@ -17,11 +19,34 @@ type LoginResponse struct {
 
 // UserInfo - ユーザー情報
 type UserInfo struct {
+	ID       uint   `json:"id"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Name     string `json:"name"`
 	Picture  string `json:"picture"`
+	Provider string `json:"provider"`
 	Sub      string `json:"sub"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// LogoutResponse - ログアウト成功レスポンス
+type LogoutResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	LogoutURL string `json:"logout_url"`
+}
+
+// AuthorizeResponse - 認可リクエスト用のstate・nonce・PKCEのレスポンス
+type AuthorizeResponse struct {
+	Success             bool   `json:"success"`
+	Provider            string `json:"provider"`
+	AuthorizeURL        string `json:"authorize_url"`
+	RedirectURI         string `json:"redirect_uri"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	ExpiresIn           int    `json:"expires_in"`
 }
 
 // ErrorResponse - エラーレスポンス
@@ -29,28 +54,46 @@ type ErrorResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Code    string `json:"code,omitempty"`
+	// Details - エラーの詳細（パスワードポリシー違反の一覧、プロフィールの反映結果など）
+	Details interface{} `json:"details,omitempty"`
 }
 
 // SendLoginSuccess - ログイン成功レスポンスを送信
-func SendLoginSuccess(c echo.Context, tokens *domain.AuthTokens, userInfo map[string]interface{}) error {
-	user := &UserInfo{
-		Email:    getStringFromMap(userInfo, "email"),
-		Username: getStringFromMap(userInfo, "username"),
-		Name:     getStringFromMap(userInfo, "name"),
-		Picture:  getStringFromMap(userInfo, "picture"),
-		Sub:      getStringFromMap(userInfo, "sub"),
-	}
-
+func SendLoginSuccess(c echo.Context, tokens *domain.AuthTokens, user *domain.User) error {
 	response := LoginResponse{
 		Success: true,
 		Message: "ログインが成功しました",
 		Tokens:  tokens,
-		User:    user,
+		User:    NewUserInfo(user),
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
+// SendLogoutSuccess - ログアウト成功レスポンスを送信
+func SendLogoutSuccess(c echo.Context, logoutURL string) error {
+	return c.JSON(http.StatusOK, LogoutResponse{
+		Success:   true,
+		Message:   "ログアウトしました",
+		LogoutURL: logoutURL,
+	})
+}
+
+// SendAuthorizeParams - 認可リクエストのパラメータを送信
+func SendAuthorizeParams(c echo.Context, state *domain.OAuthState, authorizeURL, method string) error {
+	return c.JSON(http.StatusOK, AuthorizeResponse{
+		Success:             true,
+		Provider:            state.Provider,
+		AuthorizeURL:        authorizeURL,
+		RedirectURI:         state.RedirectURI,
+		State:               state.State,
+		Nonce:               state.Nonce,
+		CodeChallenge:       state.CodeChallenge,
+		CodeChallengeMethod: method,
+		ExpiresIn:           int(time.Until(state.ExpiresAt).Seconds()),
+	})
+}
+
 // SendError - エラーレスポンスを送信
 func SendError(c echo.Context, statusCode int, message string, code ...string) error {
 	response := ErrorResponse{
@@ -75,17 +118,80 @@ func SendUnauthorized(c echo.Context, message string) error {
 	return SendError(c, http.StatusUnauthorized, message, "UNAUTHORIZED")
 }
 
+// SendForbidden - 403エラーレスポンス
+func SendForbidden(c echo.Context, message string) error {
+	return SendError(c, http.StatusForbidden, message, "FORBIDDEN")
+}
+
 // SendInternalServerError - 500エラーレスポンス
 func SendInternalServerError(c echo.Context, message string) error {
 	return SendError(c, http.StatusInternalServerError, message, "INTERNAL_SERVER_ERROR")
 }
 
-// ヘルパー関数
-func getStringFromMap(m map[string]interface{}, key string) string {
-	if val, ok := m[key]; ok {
-		if str, ok := val.(string); ok {
-			return str
+// SendAuthError - ドメインエラーの種別に応じたステータスでエラーレスポンスを送信
+func SendAuthError(c echo.Context, err error) error {
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) {
+		return SendInternalServerError(c, "認証に失敗しました")
+	}
+
+	response := ErrorResponse{
+		Success: false,
+		Message: authErr.UserMessage(),
+		Code:    authErr.ReasonCode(),
+	}
+
+	var policyErr *domain.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		response.Details = map[string]interface{}{
+			"password": policyErr.Violations,
 		}
 	}
-	return ""
+
+	var syncErr *domain.ProfileSyncError
+	if errors.As(err, &syncErr) {
+		response.Details = map[string]interface{}{
+			"sync": syncErr.Result,
+		}
+	}
+
+	return c.JSON(authErrorStatus(authErr.Type), response)
+}
+
+func authErrorStatus(errorType domain.AuthErrorType) int {
+	switch errorType {
+	case domain.AuthErrorTypeClient, domain.AuthErrorTypeValidation:
+		return http.StatusBadRequest
+	case domain.AuthErrorTypeSecurity:
+		return http.StatusUnauthorized
+	case domain.AuthErrorTypeConflict:
+		return http.StatusConflict
+	case domain.AuthErrorTypeNotFound:
+		return http.StatusNotFound
+	case domain.AuthErrorTypeRateLimit:
+		return http.StatusTooManyRequests
+	case domain.AuthErrorTypeNetwork:
+		return http.StatusServiceUnavailable
+	case domain.AuthErrorTypeServer, domain.AuthErrorTypeParse:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// NewUserInfo - 永続化されたユーザーからレスポンス用のユーザー情報を作成
+func NewUserInfo(user *domain.User) *UserInfo {
+	if user == nil {
+		return nil
+	}
+	return &UserInfo{
+		ID:       user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		Name:     user.Name,
+		Picture:  user.Picture,
+		Provider: user.Provider,
+		Sub:      user.SubjectID,
+		Groups:   user.Groups,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+package response
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// AWSCredentialsResponse - IDプールの一時的な認証情報のレスポンス
+type AWSCredentialsResponse struct {
+	Success         bool      `json:"success"`
+	IdentityID      string    `json:"identity_id"`
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// SendAWSCredentials - 一時的な認証情報を送信（キャッシュさせない）
+func SendAWSCredentials(c echo.Context, credentials *domain.AWSCredentials) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(http.StatusOK, AWSCredentialsResponse{
+		Success:         true,
+		IdentityID:      credentials.IdentityID,
+		AccessKeyID:     credentials.AccessKeyID,
+		SecretAccessKey: credentials.SecretAccessKey,
+		SessionToken:    credentials.SessionToken,
+		Expiration:      credentials.Expiration,
+	})
+}
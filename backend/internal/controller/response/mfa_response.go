@@ -0,0 +1,42 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// ChallengeResponse - ログインが未完了で、チャレンジへの応答が必要な場合のレスポンス
+type ChallengeResponse struct {
+	Success           bool                  `json:"success"`
+	Message           string                `json:"message"`
+	ChallengeRequired bool                  `json:"challenge_required"`
+	Challenge         *domain.AuthChallenge `json:"challenge"`
+}
+
+// TOTPEnrollmentResponse - 認証アプリ登録用のシークレットのレスポンス
+type TOTPEnrollmentResponse struct {
+	Success    bool   `json:"success"`
+	SecretCode string `json:"secret_code"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// SendChallenge - チャレンジを送信
+func SendChallenge(c echo.Context, challenge *domain.AuthChallenge) error {
+	return c.JSON(http.StatusOK, ChallengeResponse{
+		Success:           true,
+		Message:           "追加の認証が必要です",
+		ChallengeRequired: true,
+		Challenge:         challenge,
+	})
+}
+
+// SendTOTPEnrollment - 認証アプリ登録用のシークレットを送信
+func SendTOTPEnrollment(c echo.Context, enrollment *domain.TOTPEnrollment) error {
+	return c.JSON(http.StatusOK, TOTPEnrollmentResponse{
+		Success:    true,
+		SecretCode: enrollment.SecretCode,
+		OTPAuthURI: enrollment.OTPAuthURI,
+	})
+}
@@ -0,0 +1,49 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// SignUpResponse - サインアップ成功レスポンス
+type SignUpResponse struct {
+	Success       bool                        `json:"success"`
+	Message       string                      `json:"message"`
+	UserSub       string                      `json:"user_sub"`
+	UserConfirmed bool                        `json:"user_confirmed"`
+	CodeDelivery  *domain.CodeDeliveryDetails `json:"code_delivery,omitempty"`
+}
+
+// CodeDeliveryResponse - 確認コード送信レスポンス
+type CodeDeliveryResponse struct {
+	Success      bool                        `json:"success"`
+	Message      string                      `json:"message"`
+	CodeDelivery *domain.CodeDeliveryDetails `json:"code_delivery,omitempty"`
+}
+
+// SendSignUpSuccess - サインアップ成功レスポンスを送信
+func SendSignUpSuccess(c echo.Context, result *domain.SignUpResult) error {
+	message := "確認コードを送信しました"
+	if result.UserConfirmed {
+		message = "登録が完了しました"
+	}
+
+	return c.JSON(http.StatusCreated, SignUpResponse{
+		Success:       true,
+		Message:       message,
+		UserSub:       result.UserSub,
+		UserConfirmed: result.UserConfirmed,
+		CodeDelivery:  result.CodeDelivery,
+	})
+}
+
+// SendCodeDelivery - 確認コード送信レスポンスを送信
+func SendCodeDelivery(c echo.Context, details *domain.CodeDeliveryDetails) error {
+	return c.JSON(http.StatusOK, CodeDeliveryResponse{
+		Success:      true,
+		Message:      "確認コードを送信しました",
+		CodeDelivery: details,
+	})
+}
@@ -0,0 +1,90 @@
+package response
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// ProfileResponse - プロフィールレスポンス
+type ProfileResponse struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message,omitempty"`
+	User    *UserInfo `json:"user"`
+	// Sync - ローカルDB・Cognitoそれぞれへの反映結果
+	Sync *domain.ProfileSyncResult `json:"sync,omitempty"`
+}
+
+// AttributeVerificationResponse - 属性の変更・確認レスポンス
+type AttributeVerificationResponse struct {
+	Success      bool                          `json:"success"`
+	Message      string                        `json:"message"`
+	Verification *domain.AttributeVerification `json:"verification"`
+}
+
+// SendProfile - プロフィールを送信
+func SendProfile(c echo.Context, user *domain.User) error {
+	return c.JSON(http.StatusOK, ProfileResponse{
+		Success: true,
+		User:    NewUserInfo(user),
+	})
+}
+
+// SendProfileUpdated - プロフィール更新成功レスポンスを送信
+func SendProfileUpdated(c echo.Context, user *domain.User, sync *domain.ProfileSyncResult) error {
+	return c.JSON(http.StatusOK, ProfileResponse{
+		Success: true,
+		Message: "プロフィールを更新しました",
+		User:    NewUserInfo(user),
+		Sync:    sync,
+	})
+}
+
+// SendAttributeVerification - 属性の変更・確認の結果を送信
+func SendAttributeVerification(c echo.Context, verification *domain.AttributeVerification) error {
+	message := "確認コードを送信しました"
+	if verification.Verified {
+		message = "確認が完了しました"
+	}
+
+	return c.JSON(http.StatusOK, AttributeVerificationResponse{
+		Success:      true,
+		Message:      message,
+		Verification: verification,
+	})
+}
+
+// AccountDeletionResponse - アカウント削除受付レスポンス
+type AccountDeletionResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	// PurgeAt - この時刻までに再ログインすると削除を取り消せる
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// SendAccountDeletion - アカウント削除受付レスポンスを送信
+func SendAccountDeletion(c echo.Context, deletion *domain.AccountDeletion) error {
+	return c.JSON(http.StatusAccepted, AccountDeletionResponse{
+		Success: true,
+		Message: "アカウントの削除を受け付けました",
+		PurgeAt: deletion.PurgeAt,
+	})
+}
+
+// PermissionsResponse - 実効ロール・権限レスポンス
+type PermissionsResponse struct {
+	Success     bool     `json:"success"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// SendPermissions - 実効ロール・権限を送信
+func SendPermissions(c echo.Context, set *domain.PermissionSet) error {
+	return c.JSON(http.StatusOK, PermissionsResponse{
+		Success:     true,
+		Roles:       set.Roles,
+		Permissions: set.Permissions,
+	})
+}
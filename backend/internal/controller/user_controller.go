@@ -2,23 +2,33 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/matthewyuh246/aws-cognito/internal/controller/request"
 	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
 	"github.com/matthewyuh246/aws-cognito/internal/usecase"
 	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	oauthStateCookieName = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth"
 )
 
 type AuthController struct {
-	authUsecase usecase.IAuthUsecase
-	logger      *logger.Logger
+	authUsecase           usecase.IAuthUsecase
+	awsCredentialsUsecase usecase.IAWSCredentialsUsecase
+	logger                *logger.Logger
 }
 
-func NewAuthController(authUsecase usecase.IAuthUsecase) *AuthController {
+func NewAuthController(authUsecase usecase.IAuthUsecase, awsCredentialsUsecase usecase.IAWSCredentialsUsecase) *AuthController {
 	return &AuthController{
-		authUsecase: authUsecase,
-		logger:      logger.New("AUTH_CONTROLLER"),
+		authUsecase:           authUsecase,
+		awsCredentialsUsecase: awsCredentialsUsecase,
+		logger:                logger.New("AUTH_CONTROLLER"),
 	}
 }
 
@@ -39,31 +49,133 @@ func (ac *AuthController) LoginWithSocialProvider(c echo.Context) error {
 		"code_masked": maskCode(req.Code),
 	})
 
+	// 認可リクエストを開始したブラウザのstate（成否にかかわらず使い捨て）
+	var browserState string
+	if cookie, err := c.Cookie(oauthStateCookieName); err == nil {
+		browserState = cookie.Value
+	}
+	clearOAuthStateCookie(c)
+
 	// ビジネスロジックの実行
-	tokens, err := ac.authUsecase.LoginWithSocialProvider(c.Request().Context(), req.Provider, req.Code)
+	tokens, user, err := ac.authUsecase.LoginWithSocialProvider(c.Request().Context(), req.Provider, req.Code, req.State, browserState, req.CodeVerifier)
 	if err != nil {
 		ac.logger.Error("認証エラー", map[string]interface{}{
 			"provider": req.Provider,
 			"error":    err.Error(),
 		})
-		return response.SendUnauthorized(c, err.Error())
-	}
-
-	// 成功レスポンス（実際の実装では、usecaseからユーザー情報も取得）
-	userInfo := map[string]interface{}{
-		"email":    "user@example.com", // 実際の実装では動的に取得
-		"username": "user",
-		"name":     "User Name",
-		"picture":  "",
-		"sub":      "user-sub-id",
+		return response.SendAuthError(c, err)
 	}
 
 	ac.logger.Info("ソーシャルログイン成功", map[string]interface{}{
 		"provider": req.Provider,
-		"user_id":  userInfo["sub"],
+		"user_id":  user.ID,
+	})
+
+	return response.SendLoginSuccess(c, tokens, user)
+}
+
+// CreateAuthorizationRequest - 1回限りのstate・nonceとPKCEを発行し、Hosted UIの認可URLを返す
+// mode=redirect の場合は認可URLへ302でリダイレクトする（stateはCookieで開始したブラウザに結び付ける）
+func (ac *AuthController) CreateAuthorizationRequest(c echo.Context) error {
+	var req request.AuthorizeRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	state, authorizeURL, err := ac.authUsecase.CreateAuthorizationRequest(c.Request().Context(), req.Provider, req.CodeChallenge, req.RedirectURI)
+	if err != nil {
+		ac.logger.Error("認可リクエスト生成エラー", map[string]interface{}{
+			"provider": req.Provider,
+			"error":    err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	setOAuthStateCookie(c, state.State, state.ExpiresAt)
+
+	if req.Mode == request.AuthorizeModeRedirect {
+		// stateのCookieを含むため、中間のキャッシュに保存させない
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return c.Redirect(http.StatusFound, authorizeURL)
+	}
+
+	return response.SendAuthorizeParams(c, state, authorizeURL, utils.PKCEMethodS256)
+}
+
+// RefreshTokens - リフレッシュトークンによるトークン更新
+func (ac *AuthController) RefreshTokens(c echo.Context) error {
+	var req request.RefreshRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	tokens, user, err := ac.authUsecase.RefreshTokens(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		ac.logger.Error("トークン更新エラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("トークン更新成功", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return response.SendLoginSuccess(c, tokens, user)
+}
+
+// Logout - トークンを失効させてHosted UIのログアウトURLを返す
+func (ac *AuthController) Logout(c echo.Context) error {
+	var req request.LogoutRequest
+
+	if err := req.BindAndValidate(c); err != nil {
+		ac.logger.Error("リクエストバインドエラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return response.SendBadRequest(c, "無効なリクエストです")
+	}
+
+	// グローバルサインアウトにはアクセストークンが必要（IDトークンでは不可）
+	var accessToken string
+	if claims, ok := middleware.GetUserClaims(c); ok && claims.TokenUse == "access" {
+		accessToken, _ = middleware.GetRawToken(c)
+	}
+
+	logoutURL, err := ac.authUsecase.Logout(c.Request().Context(), req.RefreshToken, accessToken, req.LogoutURI, req.GlobalSignOut)
+	if err != nil {
+		ac.logger.Error("ログアウトエラー", map[string]interface{}{
+			"global": req.GlobalSignOut,
+			"error":  err.Error(),
+		})
+		return response.SendAuthError(c, err)
+	}
+
+	ac.logger.Info("ログアウト成功", map[string]interface{}{
+		"global": req.GlobalSignOut,
 	})
 
-	return response.SendLoginSuccess(c, tokens, userInfo)
+	return response.SendLogoutSuccess(c, logoutURL)
+}
+
+// GetSession - 認証済みトークンのクレームを返す
+func (ac *AuthController) GetSession(c echo.Context) error {
+	claims, ok := middleware.GetUserClaims(c)
+	if !ok {
+		return response.SendUnauthorized(c, "認証が必要です")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"claims":  claims,
+	})
 }
 
 // HealthCheck - ヘルスチェック
@@ -74,6 +186,32 @@ func (ac *AuthController) HealthCheck(c echo.Context) error {
 	})
 }
 
+// setOAuthStateCookie - stateを認可リクエストを開始したブラウザに結び付ける
+// /auth/login で送られたstateと一致しない場合はログインを拒否する
+func setOAuthStateCookie(c echo.Context, state string, expiresAt time.Time) {
+	c.SetCookie(&http.Cookie{
+		Name:     oauthStateCookieName,
+		Value:    state,
+		Path:     oauthStateCookiePath,
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		// IdPからのリダイレクト後、SPAが同一サイトから /auth/login を呼ぶ際に送られる
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearOAuthStateCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     oauthStateCookieName,
+		Path:     oauthStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
 // maskCode - 認可コードのマスク
 func maskCode(code string) string {
 	if len(code) <= 8 {
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/usecase"
+)
+
+type fakeAuthUsecase struct {
+	usecase.IAuthUsecase
+	issued int
+}
+
+func (u *fakeAuthUsecase) CreateAuthorizationRequest(ctx context.Context, provider, codeChallenge, redirectURI string) (*domain.OAuthState, string, error) {
+	u.issued++
+	return &domain.OAuthState{
+		State:     "issued-state",
+		Provider:  provider,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, "https://idp.example.com/authorize?state=issued-state", nil
+}
+
+func authorize(t *testing.T, ac *AuthController, secFetchSite string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/authorize/google?mode=redirect", nil)
+	if secFetchSite != "" {
+		req.Header.Set("Sec-Fetch-Site", secFetchSite)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/v1/auth/authorize/:provider")
+	c.SetParamNames("provider")
+	c.SetParamValues("google")
+
+	if err := ac.CreateAuthorizationRequest(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestCreateAuthorizationRequest_RedirectBindsState(t *testing.T) {
+	authUsecase := &fakeAuthUsecase{}
+	ac := NewAuthController(authUsecase, nil)
+
+	rec := authorize(t, ac, "same-site")
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want 302", rec.Code)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected the state cookie, got %v", cookies)
+	}
+	cookie := cookies[0]
+	if cookie.Name != oauthStateCookieName || cookie.Value != "issued-state" {
+		t.Errorf("unexpected cookie: %v", cookie)
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != oauthStateCookiePath {
+		t.Errorf("state cookie must be HttpOnly, SameSite=Lax and scoped to the auth API: %v", cookie)
+	}
+	if got := rec.Header().Get(echo.HeaderCacheControl); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+}
+
+func TestCreateAuthorizationRequest_AllowsCrossSiteRedirect(t *testing.T) {
+	authUsecase := &fakeAuthUsecase{}
+	ac := NewAuthController(authUsecase, nil)
+
+	// 他サイトのログインリンクからの開始も受け付ける（stateはこのブラウザのCookieに結び付くため、他のブラウザでは完了できない）
+	rec := authorize(t, ac, "cross-site")
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want 302", rec.Code)
+	}
+	if authUsecase.issued != 1 || len(rec.Result().Cookies()) != 1 {
+		t.Error("expected the state to be issued and bound to the browser")
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReauthenticationRequired - 重要な操作の前に直近のログインが必要
+var ErrReauthenticationRequired = errors.New("recent authentication is required")
+
+// AccountDeletion - 削除を受け付けたアカウント
+// ユーザー行は論理削除し、PurgeAt を過ぎたら関連データごと物理削除する
+// それまでに紐付いたIDで再ログインすると削除を取り消せる
+// 猶予期間中に同じメールアドレスで新しいアカウントを作れるよう、ユーザー行の一意キーは置き換え、元の値をここに退避する
+type AccountDeletion struct {
+	UserID uint   `json:"user_id" gorm:"primarykey;autoIncrement:false"`
+	Email  string `json:"email" gorm:"index;not null"`
+	// SubjectID - ユーザー行の元のsubject_id（空の場合は一意キーを置き換えていない）
+	SubjectID   string    `json:"-" gorm:"not null;default:''"`
+	RequestedAt time.Time `json:"requested_at" gorm:"not null"`
+	PurgeAt     time.Time `json:"purge_at" gorm:"index;not null"`
+}
+
+// DeletedUserEmail - 削除待ちのユーザー行のメールアドレス（.invalid は実在しないドメイン）
+func DeletedUserEmail(userID uint) string {
+	return fmt.Sprintf("deleted-%d@deleted.invalid", userID)
+}
+
+// DeletedUserSubjectID - 削除待ちのユーザー行のsubject_id
+func DeletedUserSubjectID(userID uint) string {
+	return fmt.Sprintf("deleted-%d", userID)
+}
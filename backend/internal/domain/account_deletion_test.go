@@ -0,0 +1,13 @@
+package domain
+
+import "testing"
+
+func TestDeletedUserKeys(t *testing.T) {
+	// 削除待ちのユーザー行の一意キーは、ユーザーごとに異なり実在のアドレスと衝突しない
+	if DeletedUserEmail(1) == DeletedUserEmail(2) || DeletedUserSubjectID(1) == DeletedUserSubjectID(2) {
+		t.Fatal("deleted user keys must be unique per user")
+	}
+	if got := DeletedUserEmail(42); got != "deleted-42@deleted.invalid" {
+		t.Errorf("DeletedUserEmail() = %s", got)
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+)
+
+// 管理者操作の種別（監査ログに記録する）
+const (
+	AdminActionListUsers     = "list_users"
+	AdminActionGetUser       = "get_user"
+	AdminActionDisableUser   = "disable_user"
+	AdminActionEnableUser    = "enable_user"
+	AdminActionResetPassword = "reset_password"
+	AdminActionGlobalSignOut = "global_sign_out"
+	AdminActionAddToGroup    = "add_to_group"
+	AdminActionRemoveGroup   = "remove_from_group"
+)
+
+// AdminActor - 操作を行った管理者
+type AdminActor struct {
+	Sub      string
+	Username string
+	Email    string
+	IP       string
+}
+
+// AdminUserQuery - ユーザー一覧の検索条件
+// CognitoのListUsersは1つの属性でしか絞り込めないため、Email・Status・Enabledは同時に指定できない
+type AdminUserQuery struct {
+	// Email - メールアドレスの前方一致
+	Email string `json:"email,omitempty"`
+	// Status - UNCONFIRMED, CONFIRMED, FORCE_CHANGE_PASSWORD など
+	Status    string `json:"status,omitempty"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+// AdminUser - Cognitoのユーザーとローカルのユーザーを合わせたもの
+type AdminUser struct {
+	Username       string            `json:"username"`
+	Sub            string            `json:"sub"`
+	Email          string            `json:"email"`
+	EmailVerified  bool              `json:"email_verified"`
+	Status         string            `json:"status"`
+	Enabled        bool              `json:"enabled"`
+	MFAMethods     []string          `json:"mfa_methods,omitempty"`
+	Groups         []string          `json:"groups,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	LastModifiedAt time.Time         `json:"last_modified_at"`
+	// Local - ローカルDBのユーザー（未ログインなどで存在しない場合はnil）
+	Local *User `json:"local,omitempty"`
+}
+
+// AdminUserPage - ユーザー一覧の1ページ
+type AdminUserPage struct {
+	Users         []AdminUser `json:"users"`
+	NextPageToken string      `json:"next_page_token,omitempty"`
+}
+
+// AdminAuditLog - 管理者操作の監査ログ
+// 操作の前に記録し、結果（成功・失敗）を後から更新する
+type AdminAuditLog struct {
+	ID             uint   `json:"id" gorm:"primarykey"`
+	ActorSub       string `json:"actor_sub" gorm:"index;not null"`
+	ActorUsername  string `json:"actor_username"`
+	ActorEmail     string `json:"actor_email"`
+	ActorIP        string `json:"actor_ip"`
+	Action         string `json:"action" gorm:"index;not null"`
+	TargetUsername string `json:"target_username" gorm:"index"`
+	// Details - 検索条件など操作の補足（JSON）
+	Details    string     `json:"details"`
+	Succeeded  bool       `json:"succeeded"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
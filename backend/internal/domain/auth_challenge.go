@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrChallengeSessionInvalid - 改ざん・破損したチャレンジセッション
+	ErrChallengeSessionInvalid = errors.New("challenge session is invalid")
+	// ErrChallengeSessionExpired - 有効期限切れのチャレンジセッション
+	ErrChallengeSessionExpired = errors.New("challenge session expired")
+)
+
+// Cognitoの認証チャレンジ名
+const (
+	ChallengeNewPasswordRequired = "NEW_PASSWORD_REQUIRED"
+	ChallengeSMSMFA              = "SMS_MFA"
+	ChallengeSoftwareTokenMFA    = "SOFTWARE_TOKEN_MFA"
+	ChallengeSelectMFAType       = "SELECT_MFA_TYPE"
+	ChallengeCustomChallenge     = "CUSTOM_CHALLENGE"
+)
+
+// AuthChallenge - ログインを完了するためにクライアントが応答すべきチャレンジ
+type AuthChallenge struct {
+	Name string `json:"name"`
+	// Parameters - /auth/challenge の responses に必要なキー
+	Parameters []string `json:"parameters"`
+	// Options - 選択式のチャレンジ（SELECT_MFA_TYPE）で応答できる値
+	Options []string `json:"options,omitempty"`
+	// Details - クライアントに表示してよいチャレンジの情報（コードの送信先など）
+	Details map[string]string `json:"details,omitempty"`
+	// Session - /auth/challenge にそのまま渡す不透明な値
+	Session   string    `json:"session"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ChallengeSession - クライアントに渡すチャレンジセッションの中身（暗号化して渡す）
+type ChallengeSession struct {
+	Name           string    `json:"name"`
+	CognitoSession string    `json:"cognito_session"`
+	Username       string    `json:"username"`
+	Parameters     []string  `json:"parameters"`
+	Options        []string  `json:"options,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+func (s *ChallengeSession) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// AWSCredentials - IDプールの認証済みロールの一時的な認証情報（STS）
+// 権限は infra/iam.tf の認証済みロールのポリシー（自分のIDのプレフィックスへのS3アップロードなど）に限られる
+type AWSCredentials struct {
+	// IdentityID - IDプールのID（S3のキーのプレフィックスに使う）
+	IdentityID      string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
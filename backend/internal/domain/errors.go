@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
 // 認証に関するドメインエラー
@@ -23,6 +25,9 @@ const (
 	AuthErrorTypeValidation  AuthErrorType = "validation_error"
 	AuthErrorTypeRequest     AuthErrorType = "request_error"
 	AuthErrorTypeParse       AuthErrorType = "parse_error"
+	AuthErrorTypeConflict    AuthErrorType = "conflict_error"
+	AuthErrorTypeNotFound    AuthErrorType = "not_found_error"
+	AuthErrorTypeRateLimit   AuthErrorType = "rate_limit_error"
 )
 
 func NewAuthError(errorType AuthErrorType, message string, err error) *AuthError {
@@ -52,6 +57,39 @@ func (e *AuthError) Unwrap() error {
 	return e.Err
 }
 
+// ReasonCode - クライアントが分岐に使える詳細なエラーコード
+func (e *AuthError) ReasonCode() string {
+	switch {
+	case errors.Is(e.Err, ErrOAuthStateExpired):
+		return "STATE_EXPIRED"
+	case errors.Is(e.Err, ErrOAuthStateReused):
+		return "STATE_REUSED"
+	case errors.Is(e.Err, ErrOAuthStateNotFound):
+		return "STATE_INVALID"
+	case errors.Is(e.Err, ErrInvalidCredentials):
+		return "INVALID_CREDENTIALS"
+	case errors.Is(e.Err, ErrUserNotConfirmed):
+		return "USER_NOT_CONFIRMED"
+	case errors.Is(e.Err, ErrUserAlreadyExists):
+		return "USER_ALREADY_EXISTS"
+	case errors.Is(e.Err, ErrInvalidPassword):
+		return "INVALID_PASSWORD"
+	case errors.Is(e.Err, ErrCodeMismatch):
+		return "CODE_MISMATCH"
+	case errors.Is(e.Err, ErrCodeExpired):
+		return "CODE_EXPIRED"
+	case errors.Is(e.Err, ErrPasswordResetRequired):
+		return "PASSWORD_RESET_REQUIRED"
+	case errors.Is(e.Err, ErrChallengeSessionExpired):
+		return "CHALLENGE_EXPIRED"
+	case errors.Is(e.Err, ErrChallengeSessionInvalid):
+		return "CHALLENGE_INVALID"
+	case errors.Is(e.Err, ErrReauthenticationRequired):
+		return "REAUTHENTICATION_REQUIRED"
+	}
+	return strings.ToUpper(string(e.Type))
+}
+
 func (e *AuthError) IsRetriable() bool {
 	return (e.Code >= 500 && e.Code < 600) || e.Type == AuthErrorTypeNetwork
 }
@@ -66,8 +104,57 @@ func (e *AuthError) UserMessage() string {
 	case AuthErrorTypeClient:
 		return "リクエストが正しくありません"
 	case AuthErrorTypeServer:
+		if errors.Is(e.Err, ErrCodeDeliveryFailed) {
+			return "確認コードを送信できませんでした"
+		}
 		return "認証サーバーエラーが発生しました"
+	case AuthErrorTypeValidation:
+		switch {
+		case errors.Is(e.Err, ErrInvalidPassword):
+			return "パスワードが要件を満たしていません"
+		case errors.Is(e.Err, ErrCodeMismatch):
+			return "確認コードが正しくありません"
+		case errors.Is(e.Err, ErrCodeExpired):
+			return "確認コードの有効期限が切れました。コードを再送信してください"
+		}
+		return "入力内容が正しくありません"
+	case AuthErrorTypeRateLimit:
+		return "試行回数が多すぎます。しばらくしてから再度お試しください"
+	case AuthErrorTypeConflict:
+		switch {
+		case errors.Is(e.Err, ErrUserAlreadyExists):
+			return "このメールアドレスは既に登録されています"
+		case errors.Is(e.Err, ErrUserEmailConflict):
+			return "このメールアドレスは別のアカウントで登録済みです"
+		case errors.Is(e.Err, ErrUsernameConflict):
+			return "このユーザー名は既に使用されています"
+		case errors.Is(e.Err, ErrIdentityAlreadyLinked):
+			return "このログイン方法は既に別のアカウントに連携されています"
+		case errors.Is(e.Err, ErrLastIdentity):
+			return "最後のログイン方法は解除できません"
+		}
+		return "既に登録されています"
+	case AuthErrorTypeNotFound:
+		return "対象が見つかりません"
 	case AuthErrorTypeSecurity:
+		switch {
+		case errors.Is(e.Err, ErrOAuthStateExpired):
+			return "ログインの有効期限が切れました。もう一度ログインしてください"
+		case errors.Is(e.Err, ErrOAuthStateReused):
+			return "このログインリクエストは既に使用されています。もう一度ログインしてください"
+		case errors.Is(e.Err, ErrInvalidCredentials):
+			return "メールアドレスまたはパスワードが正しくありません"
+		case errors.Is(e.Err, ErrUserNotConfirmed):
+			return "メールアドレスの確認が完了していません"
+		case errors.Is(e.Err, ErrPasswordResetRequired):
+			return "パスワードのリセットが必要です"
+		case errors.Is(e.Err, ErrChallengeSessionExpired):
+			return "認証の有効期限が切れました。もう一度ログインしてください"
+		case errors.Is(e.Err, ErrChallengeSessionInvalid):
+			return "認証セッションが無効です。もう一度ログインしてください"
+		case errors.Is(e.Err, ErrReauthenticationRequired):
+			return "この操作を行うには、もう一度ログインしてください"
+		}
 		return "セキュリティエラーが発生しました"
 	default:
 		return "認証に失敗しました"
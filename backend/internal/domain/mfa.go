@@ -0,0 +1,14 @@
+package domain
+
+// TOTPEnrollment - 認証アプリの登録情報
+type TOTPEnrollment struct {
+	SecretCode string `json:"secret_code"`
+	// OTPAuthURI - QRコードに変換して認証アプリで読み取る otpauth:// URI
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAPreference - MFAの有効化と優先設定
+type MFAPreference struct {
+	TOTPEnabled   bool `json:"totp_enabled"`
+	TOTPPreferred bool `json:"totp_preferred"`
+}
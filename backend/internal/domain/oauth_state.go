@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrOAuthStateNotFound - 発行していない（または削除済みの）state
+	ErrOAuthStateNotFound = errors.New("oauth state not found")
+	// ErrOAuthStateExpired - 有効期限切れのstate
+	ErrOAuthStateExpired = errors.New("oauth state expired")
+	// ErrOAuthStateReused - 既に使用済みのstate（リプレイの可能性）
+	ErrOAuthStateReused = errors.New("oauth state already used")
+	// ErrOAuthStateNotBound - stateが認可リクエストを開始したブラウザのCookieと一致しない
+	ErrOAuthStateNotBound = errors.New("oauth state is not bound to this browser")
+)
+
+// OAuthState - 認可リクエストごとにサーバー側で保持するstate・nonce・PKCE情報
+type OAuthState struct {
+	ID       uint   `json:"-" gorm:"primarykey"`
+	State    string `json:"state" gorm:"uniqueIndex;not null"`
+	Nonce    string `json:"nonce" gorm:"not null"`
+	Provider string `json:"provider" gorm:"not null"`
+	// RedirectURI - 認可リクエストで使用したredirect_uri（トークン交換時にも同じ値が必要）
+	RedirectURI string `json:"redirect_uri" gorm:"not null"`
+	// CodeVerifier - SPAがcode_challengeを指定した場合は空
+	CodeVerifier  string     `json:"-" gorm:"not null"`
+	CodeChallenge string     `json:"code_challenge" gorm:"not null"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"index;not null"`
+	UsedAt        *time.Time `json:"used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (s *OAuthState) IsExpired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
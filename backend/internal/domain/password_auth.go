@@ -0,0 +1,46 @@
+package domain
+
+import "errors"
+
+// パスワードログインの方式
+const (
+	// PasswordLoginModePassword - USER_PASSWORD_AUTH（パスワードをそのままCognitoに送信する）
+	PasswordLoginModePassword = "password"
+	// PasswordLoginModeSRP - USER_SRP_AUTH（パスワードをCognitoに送信しない）
+	PasswordLoginModeSRP = "srp"
+)
+
+var (
+	// ErrInvalidCredentials - メールアドレスまたはパスワードが一致しない（ユーザーの存在有無は区別しない）
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrUserNotConfirmed - サインアップ後の確認コードが未入力
+	ErrUserNotConfirmed = errors.New("user is not confirmed")
+	// ErrUserAlreadyExists - 同じメールアドレスのユーザーがユーザープールに存在する
+	ErrUserAlreadyExists = errors.New("user already exists")
+	// ErrInvalidPassword - パスワードがユーザープールのポリシーを満たさない
+	ErrInvalidPassword = errors.New("password does not satisfy policy")
+	// ErrCodeMismatch - 確認コードが一致しない
+	ErrCodeMismatch = errors.New("verification code mismatch")
+	// ErrCodeExpired - 確認コードの有効期限切れ
+	ErrCodeExpired = errors.New("verification code expired")
+	// ErrCodeDeliveryFailed - 確認コードを送信できなかった
+	ErrCodeDeliveryFailed = errors.New("verification code delivery failed")
+	// ErrPasswordResetRequired - 管理者によりパスワードのリセットが要求されている
+	ErrPasswordResetRequired = errors.New("password reset required")
+	// ErrTooManyAttempts - 試行回数の上限に達した
+	ErrTooManyAttempts = errors.New("too many attempts")
+)
+
+// SignUpResult - サインアップの結果
+type SignUpResult struct {
+	UserSub       string               `json:"user_sub"`
+	UserConfirmed bool                 `json:"user_confirmed"`
+	CodeDelivery  *CodeDeliveryDetails `json:"code_delivery,omitempty"`
+}
+
+// CodeDeliveryDetails - 確認コードの送信先（宛先はCognitoによりマスクされている）
+type CodeDeliveryDetails struct {
+	Destination    string `json:"destination"`
+	DeliveryMedium string `json:"delivery_medium"`
+	AttributeName  string `json:"attribute_name"`
+}
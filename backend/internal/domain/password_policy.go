@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// passwordSymbols - Cognitoが記号として扱う文字
+const passwordSymbols = "^$*.[]{}()?-\"!@#%&/\\,><':;|_~`+= "
+
+// パスワードポリシー違反のコード
+const (
+	PasswordViolationMinLength  = "min_length"
+	PasswordViolationLowercase  = "require_lowercase"
+	PasswordViolationUppercase  = "require_uppercase"
+	PasswordViolationNumber     = "require_number"
+	PasswordViolationSymbol     = "require_symbol"
+	PasswordViolationWhitespace = "leading_trailing_whitespace"
+	// PasswordViolationRejected - ローカルのポリシーは満たすがCognitoに拒否された（過去のパスワードの再利用など）
+	PasswordViolationRejected = "rejected"
+)
+
+// PasswordPolicy - ユーザープールのパスワードポリシー（infra/cognito.tf の password_policy と一致させる）
+type PasswordPolicy struct {
+	MinLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireNumbers   bool
+	RequireSymbols   bool
+}
+
+// PasswordPolicyViolation - 満たしていない要件
+type PasswordPolicyViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError - パスワードポリシー違反の一覧（ErrInvalidPassword としても判定できる）
+type PasswordPolicyError struct {
+	Violations []PasswordPolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	codes := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		codes = append(codes, v.Code)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidPassword, strings.Join(codes, ","))
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrInvalidPassword
+}
+
+// DefaultPasswordPolicy - infra/cognito.tf のユーザープールと同じポリシー
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireNumbers:   true,
+		RequireSymbols:   true,
+	}
+}
+
+// Validate - 満たしていない要件を返す（すべて満たす場合は空）
+func (p PasswordPolicy) Validate(password string) []PasswordPolicyViolation {
+	var hasLower, hasUpper, hasNumber, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case strings.ContainsRune(passwordSymbols, r):
+			hasSymbol = true
+		}
+	}
+
+	var violations []PasswordPolicyViolation
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordPolicyViolation{
+			Code:    PasswordViolationMinLength,
+			Message: fmt.Sprintf("%d文字以上で入力してください", p.MinLength),
+		})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, PasswordPolicyViolation{Code: PasswordViolationLowercase, Message: "小文字を含めてください"})
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, PasswordPolicyViolation{Code: PasswordViolationUppercase, Message: "大文字を含めてください"})
+	}
+	if p.RequireNumbers && !hasNumber {
+		violations = append(violations, PasswordPolicyViolation{Code: PasswordViolationNumber, Message: "数字を含めてください"})
+	}
+	if p.RequireSymbols && !hasSymbol {
+		violations = append(violations, PasswordPolicyViolation{Code: PasswordViolationSymbol, Message: "記号を含めてください"})
+	}
+	if password != strings.TrimSpace(password) {
+		violations = append(violations, PasswordPolicyViolation{Code: PasswordViolationWhitespace, Message: "先頭と末尾に空白は使用できません"})
+	}
+	return violations
+}
+
+// Check - ポリシー違反がある場合は PasswordPolicyError を返す
+func (p PasswordPolicy) Check(password string) error {
+	if violations := p.Validate(password); len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
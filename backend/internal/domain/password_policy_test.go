@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"すべて満たす", "P@ssw0rd", nil},
+		{"短い", "P@ssw0r", []string{PasswordViolationMinLength}},
+		{"小文字がない", "P@SSW0RD", []string{PasswordViolationLowercase}},
+		{"大文字がない", "p@ssw0rd", []string{PasswordViolationUppercase}},
+		{"数字がない", "P@ssword", []string{PasswordViolationNumber}},
+		{"記号がない", "Passw0rdX", []string{PasswordViolationSymbol}},
+		// 空白は記号として数えるが、先頭と末尾には使えない
+		{"末尾の空白", "Passw0rd ", []string{PasswordViolationWhitespace}},
+		{"複数の違反", "abc", []string{PasswordViolationMinLength, PasswordViolationUppercase, PasswordViolationNumber, PasswordViolationSymbol}},
+		// 文字数はバイト数ではなく文字で数える
+		{"マルチバイト文字", "Pああa1!", []string{PasswordViolationMinLength}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range policy.Validate(tt.password) {
+				got = append(got, v.Code)
+				if v.Message == "" {
+					t.Errorf("%s: message is empty", v.Code)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 6}
+
+	// 要件を無効にした項目は確認しない
+	if err := policy.Check("abcdef"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := policy.Check("abc")
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) || len(policyErr.Violations) != 1 {
+		t.Fatalf("expected a PasswordPolicyError, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("expected ErrInvalidPassword, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// 管理者APIの権限
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersManage = "users:manage"
+)
+
+// AdminPermissions - 管理者ロールに初期設定する権限
+// 後から追加される権限まで自動で許可しないよう、全権限（*）ではなく個別に列挙する
+var AdminPermissions = []Permission{
+	{Name: PermissionUsersRead, Description: "List and view users"},
+	{Name: PermissionUsersManage, Description: "Disable, enable, sign out and change groups of users"},
+}
+
+// Permission - "リソース:操作" 形式の権限（例: billing:read, users:invite）
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Role - 権限の集合
+// Cognitoのグループと同じ名前のロールは、そのグループのユーザーにも適用される
+// ローカルで割り当てたロールもCognitoのグループと同じくロール階層で展開する
+type Role struct {
+	ID          uint         `json:"id" gorm:"primarykey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// UserRole - ユーザーへのロールの割り当て
+type UserRole struct {
+	UserID    uint      `json:"user_id" gorm:"primarykey;autoIncrement:false"`
+	RoleID    uint      `json:"role_id" gorm:"primarykey;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PermissionSet - ユーザーの実効ロールと実効権限
+type PermissionSet struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// Allows - permissionを許可するか。"billing:*" はbillingのすべての操作、"*" はすべてを許可する
+func (s *PermissionSet) Allows(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range s.Permissions {
+		if granted == permission || granted == "*" || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyRole - 実効ロールにrolesのいずれかが含まれるか
+func (s *PermissionSet) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		for _, granted := range s.Roles {
+			if granted == role {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestPermissionSet_Allows(t *testing.T) {
+	set := &PermissionSet{Permissions: []string{"billing:*", PermissionUsersRead}}
+
+	for permission, want := range map[string]bool{
+		"billing:read":        true,
+		PermissionUsersRead:   true,
+		PermissionUsersManage: false,
+	} {
+		if got := set.Allows(permission); got != want {
+			t.Errorf("Allows(%s) = %v, want %v", permission, got, want)
+		}
+	}
+}
+
+func TestPermissionSet_HasAnyRole(t *testing.T) {
+	set := &PermissionSet{Roles: []string{"admin", "member"}}
+
+	if !set.HasAnyRole("support", "member") {
+		t.Error("expected member to match")
+	}
+	if set.HasAnyRole("support") || set.HasAnyRole() {
+		t.Error("unexpected match")
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// ProfileUpdate - ユーザー自身が変更するプロフィール項目（nilの項目は変更しない）
+type ProfileUpdate struct {
+	Name     *string
+	Picture  *string
+	Username *string
+}
+
+// ProfileSyncStatus - ローカルDB・Cognitoそれぞれの更新結果
+type ProfileSyncStatus string
+
+const (
+	ProfileSyncUpdated        ProfileSyncStatus = "updated"
+	ProfileSyncSkipped        ProfileSyncStatus = "skipped"
+	ProfileSyncFailed         ProfileSyncStatus = "failed"
+	ProfileSyncRolledBack     ProfileSyncStatus = "rolled_back"
+	ProfileSyncRollbackFailed ProfileSyncStatus = "rollback_failed"
+)
+
+// ProfileSyncResult - プロフィール更新の反映先ごとの結果
+type ProfileSyncResult struct {
+	Local   ProfileSyncStatus `json:"local"`
+	Cognito ProfileSyncStatus `json:"cognito"`
+}
+
+// ProfileSyncError - ローカルDBとCognitoの一方の更新に失敗した
+// Resultでどちらに反映済みかをクライアントに返す
+type ProfileSyncError struct {
+	Result ProfileSyncResult
+	Err    error
+}
+
+func (e *ProfileSyncError) Error() string {
+	return fmt.Sprintf("profile sync failed (local=%s, cognito=%s): %v", e.Result.Local, e.Result.Cognito, e.Err)
+}
+
+func (e *ProfileSyncError) Unwrap() error {
+	return e.Err
+}
+
+// AttributeVerification - Cognito属性の変更・確認の結果
+type AttributeVerification struct {
+	Attribute string `json:"attribute"`
+	// Verified - 確認コードによる検証が完了したか（falseの場合はCodeDeliveryに送信先が入る）
+	Verified     bool                 `json:"verified"`
+	CodeDelivery *CodeDeliveryDetails `json:"code_delivery,omitempty"`
+	Sync         *ProfileSyncResult   `json:"sync,omitempty"`
+}
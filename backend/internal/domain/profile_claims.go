@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// プロフィール項目
+const (
+	ProfileFieldEmail    = "email"
+	ProfileFieldName     = "name"
+	ProfileFieldPicture  = "picture"
+	ProfileFieldUsername = "username"
+)
+
+// defaultClaimProvider - プロバイダー個別の指定がない項目に使う対応
+const defaultClaimProvider = "*"
+
+var profileFields = []string{ProfileFieldEmail, ProfileFieldName, ProfileFieldPicture, ProfileFieldUsername}
+
+// ProfileClaimMappings - プロバイダーごとの、プロフィール項目を取り出すクレームの候補（先頭から順に使う）
+// 候補は "given_name+family_name" で空白区切りの連結、"picture.data.url" で入れ子のクレームを表す
+type ProfileClaimMappings map[string]map[string][]string
+
+// ProfileClaimConfig - IDトークンのクレームからプロフィールを組み立てる設定
+type ProfileClaimConfig struct {
+	Mappings ProfileClaimMappings
+	// Required - IDトークンから取り出せない場合にuserInfoエンドポイントで補う項目
+	Required []string
+}
+
+// DefaultProfileClaimMappings - 標準クレーム（OIDC Core 5.1）による対応
+func DefaultProfileClaimMappings() ProfileClaimMappings {
+	return ProfileClaimMappings{
+		defaultClaimProvider: {
+			ProfileFieldEmail:    {"email"},
+			ProfileFieldName:     {"name", "given_name+family_name"},
+			ProfileFieldPicture:  {"picture"},
+			ProfileFieldUsername: {"preferred_username", "name"},
+		},
+	}
+}
+
+// ParseProfileClaimMappings - "github:picture=picture|avatar_url,username=preferred_username;*:name=name" 形式の設定を
+// 既定の対応に上書きする（指定しなかった項目は既定のまま）
+func ParseProfileClaimMappings(value string) (ProfileClaimMappings, error) {
+	mappings := DefaultProfileClaimMappings()
+
+	for _, section := range strings.Split(value, ";") {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			continue
+		}
+
+		provider, entries, ok := strings.Cut(section, ":")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid claim mapping %q", section)
+		}
+
+		for _, entry := range strings.Split(entries, ",") {
+			field, candidates, ok := strings.Cut(entry, "=")
+			field = strings.TrimSpace(field)
+			if !ok || !slices.Contains(profileFields, field) {
+				return nil, fmt.Errorf("invalid claim mapping %q for %s", entry, provider)
+			}
+
+			var claims []string
+			for _, claim := range strings.Split(candidates, "|") {
+				if claim = strings.TrimSpace(claim); claim != "" {
+					claims = append(claims, claim)
+				}
+			}
+			if len(claims) == 0 {
+				return nil, fmt.Errorf("no claims for %s.%s", provider, field)
+			}
+
+			if mappings[provider] == nil {
+				mappings[provider] = make(map[string][]string)
+			}
+			mappings[provider][field] = claims
+		}
+	}
+
+	return mappings, nil
+}
+
+// ParseProfileFields - "email,name" 形式の項目一覧を解析する
+func ParseProfileFields(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(profileFields, field) {
+			return nil, fmt.Errorf("unknown profile field %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Resolve - providerの対応（なければ既定の対応）で項目の値を取り出す
+func (m ProfileClaimMappings) Resolve(provider, field string, claims map[string]interface{}) string {
+	candidates, ok := m[provider][field]
+	if !ok {
+		candidates = m[defaultClaimProvider][field]
+	}
+
+	for _, candidate := range candidates {
+		parts := make([]string, 0, 2)
+		for _, path := range strings.Split(candidate, "+") {
+			if value, _ := LookupClaim(claims, path).(string); strings.TrimSpace(value) != "" {
+				parts = append(parts, strings.TrimSpace(value))
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, " ")
+		}
+	}
+	return ""
+}
+
+// LookupClaim - "realm_access.roles" のように . 区切りで入れ子のクレームを取り出す
+// . を含む名前のクレーム（Auth0の名前空間付きクレームなど）はそのまま一致するものを優先する
+func LookupClaim(claims map[string]interface{}, path string) interface{} {
+	if value, ok := claims[path]; ok {
+		return value
+	}
+
+	var current interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
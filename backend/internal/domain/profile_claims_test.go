@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseProfileClaimMappings(t *testing.T) {
+	mappings, err := ParseProfileClaimMappings(" GitHub : picture = picture | avatar_url , username=login ; *:name=nickname ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		provider string
+		field    string
+		want     []string
+	}{
+		// プロバイダー名は小文字にし、前後の空白は無視する
+		{"github", ProfileFieldPicture, []string{"picture", "avatar_url"}},
+		{"github", ProfileFieldUsername, []string{"login"}},
+		// 既定の対応は指定した項目だけ上書きされる
+		{defaultClaimProvider, ProfileFieldName, []string{"nickname"}},
+		{defaultClaimProvider, ProfileFieldEmail, []string{"email"}},
+		{defaultClaimProvider, ProfileFieldUsername, []string{"preferred_username", "name"}},
+	}
+	for _, tt := range tests {
+		if got := mappings[tt.provider][tt.field]; !slices.Equal(got, tt.want) {
+			t.Errorf("%s.%s = %v, want %v", tt.provider, tt.field, got, tt.want)
+		}
+	}
+
+	// 指定しなかった項目はプロバイダーの対応に含めない（Resolveで既定の対応を使う）
+	if _, ok := mappings["github"][ProfileFieldEmail]; ok {
+		t.Error("github.email should fall back to the default mapping")
+	}
+}
+
+func TestParseProfileClaimMappings_Empty(t *testing.T) {
+	mappings, err := ParseProfileClaimMappings("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := mappings[defaultClaimProvider][ProfileFieldName], []string{"name", "given_name+family_name"}; !slices.Equal(got, want) {
+		t.Errorf("default name mapping = %v, want %v", got, want)
+	}
+}
+
+func TestParseProfileClaimMappings_Rejects(t *testing.T) {
+	for _, value := range []string{
+		"picture=avatar_url",        // プロバイダーがない
+		":picture=avatar_url",       // プロバイダー名が空
+		"github:avatar=avatar_url",  // 未知の項目
+		"github:picture",            // = がない
+		"github:picture= | ",        // クレームがない
+		"github:picture=a,,email=b", // 空の指定
+	} {
+		if _, err := ParseProfileClaimMappings(value); err == nil {
+			t.Errorf("%q: expected error, got nil", value)
+		}
+	}
+}
+
+func TestParseProfileFields(t *testing.T) {
+	fields, err := ParseProfileFields(" email , ,name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{ProfileFieldEmail, ProfileFieldName}; !slices.Equal(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+
+	if _, err := ParseProfileFields("email,avatar"); err == nil {
+		t.Error("expected error for unknown field, got nil")
+	}
+}
+
+func TestProfileClaimMappings_Resolve(t *testing.T) {
+	mappings := ProfileClaimMappings{
+		defaultClaimProvider: {
+			ProfileFieldEmail: {"email"},
+			ProfileFieldName:  {"name", "given_name+family_name"},
+		},
+		"facebook": {
+			ProfileFieldPicture: {"picture.data.url"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		field    string
+		claims   map[string]interface{}
+		want     string
+	}{
+		{
+			name:     "既定の対応",
+			provider: "google",
+			field:    ProfileFieldEmail,
+			claims:   map[string]interface{}{"email": "user@example.com"},
+			want:     "user@example.com",
+		},
+		{
+			name:     "空の候補は次の候補を使う",
+			provider: "google",
+			field:    ProfileFieldName,
+			claims:   map[string]interface{}{"name": "  ", "given_name": "Taro", "family_name": "Yamada"},
+			want:     "Taro Yamada",
+		},
+		{
+			name:     "連結の一部がない場合はある部分のみ",
+			provider: "google",
+			field:    ProfileFieldName,
+			claims:   map[string]interface{}{"given_name": " Taro "},
+			want:     "Taro",
+		},
+		{
+			name:     "プロバイダー個別の入れ子のクレーム",
+			provider: "facebook",
+			field:    ProfileFieldPicture,
+			claims: map[string]interface{}{
+				"picture": map[string]interface{}{"data": map[string]interface{}{"url": "https://example.com/a.png"}},
+			},
+			want: "https://example.com/a.png",
+		},
+		{
+			name:     "プロバイダー個別の指定がない項目は既定の対応",
+			provider: "facebook",
+			field:    ProfileFieldEmail,
+			claims:   map[string]interface{}{"email": "fb@example.com"},
+			want:     "fb@example.com",
+		},
+		{
+			name:     "文字列以外のクレームは使わない",
+			provider: "google",
+			field:    ProfileFieldEmail,
+			claims:   map[string]interface{}{"email": true},
+			want:     "",
+		},
+		{
+			name:     "対応のない項目",
+			provider: "google",
+			field:    ProfileFieldPicture,
+			claims:   map[string]interface{}{"picture": "https://example.com/a.png"},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mappings.Resolve(tt.provider, tt.field, tt.claims); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupClaim(t *testing.T) {
+	claims := map[string]interface{}{
+		"realm_access":              map[string]interface{}{"roles": []interface{}{"admin"}},
+		"https://example.com/roles": "namespaced",
+		"https://example":           map[string]interface{}{"com/roles": "nested"},
+		"email":                     "user@example.com",
+	}
+
+	if roles, _ := LookupClaim(claims, "realm_access.roles").([]interface{}); len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("realm_access.roles = %v", LookupClaim(claims, "realm_access.roles"))
+	}
+	// . を含む名前のクレームは入れ子より優先する
+	if got := LookupClaim(claims, "https://example.com/roles"); got != "namespaced" {
+		t.Errorf("namespaced claim = %v, want namespaced", got)
+	}
+	// 途中がオブジェクトでない場合・存在しない場合はnil
+	for _, path := range []string{"email.domain", "realm_access.groups", "missing", "missing.child"} {
+		if got := LookupClaim(claims, path); got != nil {
+			t.Errorf("%s = %v, want nil", path, got)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// RateLimitCounter - 固定ウィンドウのレート制限カウンター（キーは呼び出し側で決める）
+type RateLimitCounter struct {
+	Key         string    `json:"key" gorm:"primarykey"`
+	Count       int       `json:"count" gorm:"not null"`
+	WindowStart time.Time `json:"window_start" gorm:"not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index;not null"`
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RoleHierarchy - ロールと、そのロールが包含する下位ロール（例: admin → member）
+// ロールはCognitoのグループ名、またはローカルで割り当てたロール名に対応する
+type RoleHierarchy map[string][]string
+
+// ParseRoleHierarchy - "admin=member,support;support=member" 形式の設定を読み込む
+// 空文字の場合は階層なし（グループ名がそのままロールになる）
+func ParseRoleHierarchy(spec string) (RoleHierarchy, error) {
+	hierarchy := RoleHierarchy{}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, implied, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid role hierarchy entry %q", entry)
+		}
+
+		for _, child := range strings.Split(implied, ",") {
+			child = strings.TrimSpace(child)
+			if child == "" {
+				continue
+			}
+			hierarchy[role] = append(hierarchy[role], child)
+		}
+	}
+	return hierarchy, nil
+}
+
+// Expand - 所属グループから、包含されるロールを含めた実効ロールを求める（循環があっても停止する）
+func (h RoleHierarchy) Expand(groups []string) map[string]bool {
+	roles := make(map[string]bool, len(groups))
+	queue := append([]string(nil), groups...)
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if roles[role] {
+			continue
+		}
+		roles[role] = true
+		queue = append(queue, h[role]...)
+	}
+	return roles
+}
+
+// EffectiveRoles - 実効ロールを名前順で返す
+func (h RoleHierarchy) EffectiveRoles(groups []string) []string {
+	expanded := h.Expand(groups)
+	roles := make([]string, 0, len(expanded))
+	for role := range expanded {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// HasAnyRole - 実効ロールにrolesのいずれかが含まれるか
+func (h RoleHierarchy) HasAnyRole(groups []string, roles ...string) bool {
+	effective := h.Expand(groups)
+	for _, role := range roles {
+		if effective[role] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseRoleHierarchy(t *testing.T) {
+	hierarchy, err := ParseRoleHierarchy(" admin = member, support ; support=member;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := hierarchy["admin"]; len(got) != 2 || got[0] != "member" || got[1] != "support" {
+		t.Errorf("unexpected admin roles: %v", got)
+	}
+	if got := hierarchy["support"]; len(got) != 1 || got[0] != "member" {
+		t.Errorf("unexpected support roles: %v", got)
+	}
+
+	for _, spec := range []string{"admin", "=member"} {
+		if _, err := ParseRoleHierarchy(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestRoleHierarchy_HasAnyRole(t *testing.T) {
+	hierarchy, err := ParseRoleHierarchy("admin=support;support=member;member=admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		groups []string
+		roles  []string
+		want   bool
+	}{
+		{"direct", []string{"support"}, []string{"support"}, true},
+		{"transitive", []string{"admin"}, []string{"member"}, true},
+		{"cycle terminates", []string{"member"}, []string{"support"}, true},
+		{"unknown group", []string{"guest"}, []string{"member"}, false},
+		{"no groups", nil, []string{"member"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hierarchy.HasAnyRole(tt.groups, tt.roles...); got != tt.want {
+				t.Errorf("HasAnyRole(%v, %v) = %v, want %v", tt.groups, tt.roles, got, tt.want)
+			}
+		})
+	}
+
+	if got := hierarchy.EffectiveRoles([]string{"support"}); len(got) != 3 || got[0] != "admin" || got[1] != "member" || got[2] != "support" {
+		t.Errorf("unexpected effective roles: %v", got)
+	}
+
+	flat := RoleHierarchy{}
+	if flat.HasAnyRole([]string{"admin"}, "member") {
+		t.Error("empty hierarchy should not imply roles")
+	}
+}
@@ -1,22 +1,40 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrUserEmailConflict - 別のアカウントが同じメールアドレスを使用している
+	ErrUserEmailConflict = errors.New("email is already registered to another user")
+	// ErrUsernameConflict - 別のアカウントが同じユーザー名を使用している
+	ErrUsernameConflict = errors.New("username is already taken")
+)
+
 type User struct {
 	ID uint `json:"id" gorm:"primarykey"`
 	Email string `json:"email" gorm:"uniqueIndex;not null"`
+	// EmailVerified - 最後にログインしたときのIdPでメールアドレスが確認済みだったか（自動紐付けの条件）
+	EmailVerified bool `json:"email_verified" gorm:"not null;default:false"`
 	Username string `json:"username" gorm:"uniqueIndex;not null"`
 	Name string `json:"name"`
 	Picture string `json:"picture"`
-	Provider string `json:"provider" gorm:"not null"`
-	SubjectID string `json:"subject_id" gorm:"not null"`
+	Provider string `json:"provider" gorm:"not null;uniqueIndex:idx_users_provider_subject"`
+	SubjectID string `json:"subject_id" gorm:"not null;uniqueIndex:idx_users_provider_subject"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	// Groups - ログイン時のトークンに含まれるCognitoのグループ（DBには保存しない）
+	Groups []string `json:"groups,omitempty" gorm:"-"`
+}
+
+// CanLinkByEmail - incomingのIDを同じメールアドレスのこのユーザーに自動で紐付けてよいか
+// 未確認のメールアドレスで先にアカウントを作り、後から本人のログインを乗っ取る攻撃を防ぐため、双方が確認済みの場合のみ
+func (u *User) CanLinkByEmail(incoming *User) bool {
+	return u.EmailVerified && incoming.EmailVerified && u.Email == incoming.Email
 }
 
 type AuthTokens struct {
@@ -28,10 +46,18 @@ type AuthTokens struct {
 
 type UserClaims struct {
 	UserID uint `json:"user_id"`
+	Sub string `json:"sub"`
 	Email string `json:"email"`
 	Username string `json:"username"`
 	Name string `json:"name"`
 	Picture string `json:"picture"`
 	Provider string `json:"provider"`
+	TokenUse string `json:"token_use"`
 	Exp int64 `json:"exp"`
+	// AuthTime - ユーザーがログインした時刻（トークン更新では変わらない）
+	AuthTime int64 `json:"auth_time"`
+	// Groups - 所属するCognitoのグループ（cognito:groups）
+	Groups []string `json:"groups,omitempty"`
+	// Roles - グループをロール階層で展開した実効ロール
+	Roles []string `json:"roles,omitempty"`
 }
\ No newline at end of file
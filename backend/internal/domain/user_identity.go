@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrIdentityAlreadyLinked - 同じプロバイダーのsubが既に別のユーザーに紐付いている
+	ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+	// ErrIdentityNotFound - 指定のIDがユーザーに紐付いていない
+	ErrIdentityNotFound = errors.New("identity not found")
+	// ErrLastIdentity - 最後のIDは解除できない（ログイン手段がなくなるため）
+	ErrLastIdentity = errors.New("cannot unlink the last identity")
+)
+
+// UserIdentity - ユーザーに紐付くIdPのsub（1ユーザーに複数）
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UserID    uint      `json:"user_id" gorm:"index;not null"`
+	Provider  string    `json:"provider" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	SubjectID string    `json:"subject_id" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
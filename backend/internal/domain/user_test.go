@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestUser_CanLinkByEmail(t *testing.T) {
+	tests := []struct {
+		name             string
+		existingVerified bool
+		incomingVerified bool
+		incomingEmail    string
+		want             bool
+	}{
+		{"both verified", true, true, "alice@example.com", true},
+		{"existing unverified (pre-account takeover)", false, true, "alice@example.com", false},
+		{"incoming unverified", true, false, "alice@example.com", false},
+		{"neither verified", false, false, "alice@example.com", false},
+		{"different email", true, true, "bob@example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &User{Email: "alice@example.com", EmailVerified: tt.existingVerified}
+			incoming := &User{Email: tt.incomingEmail, EmailVerified: tt.incomingVerified}
+			if got := existing.CanLinkByEmail(incoming); got != tt.want {
+				t.Errorf("CanLinkByEmail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
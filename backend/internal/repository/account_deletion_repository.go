@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAccountDeletionRepository interface {
+	ScheduleDeletion(ctx context.Context, user *domain.User, purgeAt time.Time) (*domain.AccountDeletion, error)
+	FindPendingDeletion(ctx context.Context, provider, subjectID string) (*domain.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID uint) error
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]domain.AccountDeletion, error)
+	ListIdentitiesForPurge(ctx context.Context, userID uint) ([]domain.UserIdentity, error)
+	PurgeUser(ctx context.Context, userID uint) error
+}
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) IAccountDeletionRepository {
+	return &accountDeletionRepository{db: db}
+}
+
+// ScheduleDeletion - ユーザーを論理削除し、物理削除の予定を登録する
+// メールアドレスとsubject_idは削除予定に退避し、ユーザー行では使われない値に置き換えて一意キーを解放する
+func (r *accountDeletionRepository) ScheduleDeletion(ctx context.Context, user *domain.User, purgeAt time.Time) (*domain.AccountDeletion, error) {
+	deletion := &domain.AccountDeletion{
+		UserID:      user.ID,
+		Email:       user.Email,
+		SubjectID:   user.SubjectID,
+		RequestedAt: time.Now(),
+		PurgeAt:     purgeAt,
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(deletion).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&domain.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"email":      domain.DeletedUserEmail(user.ID),
+			"subject_id": domain.DeletedUserSubjectID(user.ID),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&domain.User{}, user.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// FindPendingDeletion - 削除待ちのユーザーを、紐付いたIDで探す
+func (r *accountDeletionRepository) FindPendingDeletion(ctx context.Context, provider, subjectID string) (*domain.AccountDeletion, error) {
+	var deletion domain.AccountDeletion
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = account_deletions.user_id").
+		Where("user_identities.provider = ? AND user_identities.subject_id = ?", provider, subjectID).
+		First(&deletion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CancelDeletion - 論理削除を取り消し、退避したメールアドレスとsubject_idを戻して削除予定を破棄する
+// 猶予期間中に別のアカウントが同じメールアドレスを使った場合は ErrUserEmailConflict を返し、削除予定は残す
+func (r *accountDeletionRepository) CancelDeletion(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var deletion domain.AccountDeletion
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&deletion).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// 物理削除ジョブが先に処理した、または別のログインで取り消し済み
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Delete(&deletion).Error; err != nil {
+			return err
+		}
+
+		restored := map[string]interface{}{"deleted_at": nil}
+		// SubjectIDが空の削除予定はユーザー行の一意キーを置き換えていない
+		if deletion.SubjectID != "" {
+			restored["email"] = deletion.Email
+			restored["subject_id"] = deletion.SubjectID
+		}
+		err = tx.Unscoped().Model(&domain.User{}).Where("id = ?", userID).Updates(restored).Error
+		return translateUserConstraintError(err)
+	})
+}
+
+// ListDueDeletions - 猶予期間が終了した削除予定
+func (r *accountDeletionRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]domain.AccountDeletion, error) {
+	var deletions []domain.AccountDeletion
+	err := r.db.WithContext(ctx).Where("purge_at <= ?", now).Order("purge_at").Limit(limit).Find(&deletions).Error
+	return deletions, err
+}
+
+// ListIdentitiesForPurge - 物理削除するユーザーに残っているID
+func (r *accountDeletionRepository) ListIdentitiesForPurge(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// PurgeUser - ユーザーと関連データを物理削除する
+// 削除予定が取り消されていた場合は何もしない
+func (r *accountDeletionRepository) PurgeUser(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&domain.AccountDeletion{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		// IDを使い回したユーザーに以前のロールが引き継がれないよう、ロールの割り当ても削除する
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", userID).Delete(&domain.User{}).Error
+	})
+}
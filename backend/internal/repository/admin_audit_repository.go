@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+)
+
+type IAdminAuditRepository interface {
+	Start(ctx context.Context, entry *domain.AdminAuditLog) error
+	Finish(ctx context.Context, entry *domain.AdminAuditLog, actionErr error) error
+}
+
+type adminAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminAuditRepository(db *gorm.DB) IAdminAuditRepository {
+	return &adminAuditRepository{db: db}
+}
+
+// Start - 操作の開始を記録する（記録できない場合は操作を行わない）
+func (r *adminAuditRepository) Start(ctx context.Context, entry *domain.AdminAuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// Finish - 操作の結果を記録する
+func (r *adminAuditRepository) Finish(ctx context.Context, entry *domain.AdminAuditLog, actionErr error) error {
+	now := time.Now()
+	entry.FinishedAt = &now
+	entry.Succeeded = actionErr == nil
+	if actionErr != nil {
+		entry.Error = actionErr.Error()
+	}
+
+	return r.db.WithContext(ctx).Model(entry).Updates(map[string]interface{}{
+		"succeeded":   entry.Succeeded,
+		"error":       entry.Error,
+		"finished_at": entry.FinishedAt,
+	}).Error
+}
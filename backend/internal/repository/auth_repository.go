@@ -2,77 +2,87 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"path"
-	"strings"
-	"time"
 
 	"github.com/matthewyuh246/aws-cognito/internal/domain"
-	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
-	"github.com/matthewyuh246/aws-cognito/pkg/logger"
 	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 )
 
 type IAuthRepository interface {
-	ExchangeCodeForTokens(ctx context.Context, authCode string) (*domain.AuthTokens, error)
+	ExchangeCodeForTokens(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error)
+	ResolveRedirectURI(redirectURI string) (string, error)
+	BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error)
+	RevokeToken(ctx context.Context, refreshToken string) error
+	BuildLogoutURL(ctx context.Context, logoutURI string) (string, error)
 }
 
-// 
+// authRepository - リダイレクト先の検証を行い、認可コードフローをIdPに委譲する
 type authRepository struct {
-	httpClient       *httpclient.Client
-	logger           *logger.Logger
-	cognitoDomain    string
-	userPoolClientID string
+	identityProvider IIdentityProvider
 	allowedDomains   []string
+	logoutURLs       []string
 }
 
 type AuthConfig struct {
-	CognitoDomain    string
-	UserPoolClientID string
+	IdentityProvider IIdentityProvider
 	AllowedDomains   []string
+	// LogoutURLs - IdPに登録済みのサインアウトURL（infra/cognito.tf の logout_urls）
+	LogoutURLs []string
 }
 
 func NewAuthRepository(config AuthConfig) IAuthRepository {
-	httpConfig := httpclient.Config{
-		Timeout:     30 * time.Second,
-		MaxRetries:  3,
-		BaseBackoff: 1 * time.Second,
-		MaxBackoff:  30 * time.Second,
-		JitterMax:   1 * time.Second,
-	}
-
-	authLogger := logger.New("AUTH")
-	client := httpclient.NewClient(httpConfig, authLogger)
-
 	return &authRepository{
-		httpClient:       client,
-		logger:           authLogger,
-		cognitoDomain:    config.CognitoDomain,
-		userPoolClientID: config.UserPoolClientID,
+		identityProvider: config.IdentityProvider,
 		allowedDomains:   config.AllowedDomains,
+		logoutURLs:       config.LogoutURLs,
+	}
+}
+
+func (r *authRepository) ExchangeCodeForTokens(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error) {
+	// 認可リクエストと同じredirect_uriでなければIdPが拒否する
+	redirectURI, err := r.ResolveRedirectURI(redirectURI)
+	if err != nil {
+		return nil, err
 	}
+
+	return r.identityProvider.ExchangeCode(ctx, authCode, codeVerifier, redirectURI)
 }
 
-func (r *authRepository) ExchangeCodeForTokens(ctx context.Context, authCode string) (*domain.AuthTokens, error) {
-	// 開発環境のモック処理
-	if strings.Contains(r.cognitoDomain, "dummy-domain") {
-		return &domain.AuthTokens{
-			AccessToken:  "mock_access_token",
-			RefreshToken: "mock_refresh_token",
-			IdToken:      "mock_id_token",
-			ExpiresIn:    3600,
-		}, nil
+// ResolveRedirectURI - redirect_uriを検証して正規化する。空の場合はFE_URLから構築する
+func (r *authRepository) ResolveRedirectURI(redirectURI string) (string, error) {
+	if redirectURI == "" {
+		return r.buildAndValidateRedirectURI()
 	}
 
-	redirectURI, err := r.buildAndValidateRedirectURI()
+	parsedURL, err := url.Parse(redirectURI)
 	if err != nil {
-		return nil, err
+		return "", domain.NewAuthError(domain.AuthErrorTypeValidation, "redirect_uriの形式が正しくありません", err)
 	}
 
-	return r.performTokenExchange(ctx, authCode, redirectURI)
+	if (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.User != nil || parsedURL.Fragment != "" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "許可されていないredirect_uriです", nil)
+	}
+
+	// ホワイトリスト検証（オリジン単位）
+	origin := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+	if !r.isAllowedDomain(origin) {
+		return "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "許可されていないredirect_uriです", nil)
+	}
+
+	// コールバックパスはアプリクライアントの callback_urls と一致させる
+	if path.Clean(parsedURL.Path) != "/auth/callback" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "許可されていないredirect_uriです", nil)
+	}
+
+	return fmt.Sprintf("%s/auth/callback", origin), nil
+}
+
+// BuildAuthorizeURL - IdPの認可エンドポイントのURLを構築する
+func (r *authRepository) BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error) {
+	return r.identityProvider.BuildAuthorizeURL(ctx, state)
 }
 
 func (r *authRepository) buildAndValidateRedirectURI() (string, error) {
@@ -105,95 +115,40 @@ func (r *authRepository) isAllowedDomain(domain string) bool {
 	return false
 }
 
-func (r *authRepository) performTokenExchange(ctx context.Context, authCode, redirectURI string) (*domain.AuthTokens, error) {
-	tokenURL := fmt.Sprintf("%s/oauth2/token", r.cognitoDomain)
-
-	data := url.Values{}
-	data.Set("grant_type", "authorization_code")
-	data.Set("client_id", r.userPoolClientID)
-	data.Set("code", authCode)
-	data.Set("redirect_uri", url.QueryEscape(redirectURI))
-
-	maskedCode := utils.MaskSensitiveData(authCode, 4, 4)
-	r.logger.Debug("トークン交換リクエスト開始", map[string]interface{}{
-		"url":         tokenURL,
-		"code_masked": maskedCode,
-		"client_id":   r.userPoolClientID,
-	})
-
-	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return nil, domain.NewAuthError(domain.AuthErrorTypeRequest, "リクエスト作成に失敗しました", err)
-	}
+// RefreshTokens - リフレッシュトークンで新しいアクセストークン・IDトークンを取得
+func (r *authRepository) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	return r.identityProvider.RefreshTokens(ctx, refreshToken)
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+// RevokeToken - リフレッシュトークンと、それに紐づくアクセストークンを失効させる
+func (r *authRepository) RevokeToken(ctx context.Context, refreshToken string) error {
+	return r.identityProvider.RevokeToken(ctx, refreshToken)
+}
 
-	resp, err := r.httpClient.DoWithRetry(ctx, req)
-	if err != nil {
-		return nil, domain.NewAuthError(domain.AuthErrorTypeNetwork, "ネットワーク接続に失敗しました", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, domain.NewAuthErrorWithCode(
-			r.categorizeHTTPError(resp.StatusCode),
-			resp.StatusCode,
-			"認証サーバーエラー",
-		)
+// BuildLogoutURL - IdPのログアウトURLを構築する
+// logoutURIが空の場合は登録済みの先頭のURLを使用する
+func (r *authRepository) BuildLogoutURL(ctx context.Context, logoutURI string) (string, error) {
+	if len(r.logoutURLs) == 0 {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "ログアウトURLが設定されていません", nil)
 	}
 
-	var tokenResponse struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		IdToken      string `json:"id_token"`
-		ExpiresIn    int    `json:"expires_in"`
+	if logoutURI == "" {
+		logoutURI = r.logoutURLs[0]
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
-		return nil, domain.NewAuthError(domain.AuthErrorTypeParse, "レスポンス解析に失敗しました", err)
+	// IdPは登録済みのログアウトURLと完全一致しない場合エラー画面を表示する
+	if !r.isAllowedLogoutURL(logoutURI) {
+		return "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "許可されていないログアウトURLです", nil)
 	}
 
-	if err := r.validateTokenResponse(&tokenResponse); err != nil {
-		return nil, err
-	}
-
-	r.logger.Info("トークン交換成功", map[string]interface{}{
-		"client_id": r.userPoolClientID,
-	})
-
-	return &domain.AuthTokens{
-		AccessToken:  tokenResponse.AccessToken,
-		RefreshToken: tokenResponse.RefreshToken,
-		IdToken:      tokenResponse.IdToken,
-		ExpiresIn:    tokenResponse.ExpiresIn,
-	}, nil
+	return r.identityProvider.BuildLogoutURL(ctx, logoutURI)
 }
 
-func (r *authRepository) categorizeHTTPError(statusCode int) domain.AuthErrorType {
-	switch {
-	case statusCode >= 400 && statusCode < 500:
-		return domain.AuthErrorTypeClient
-	case statusCode >= 500 && statusCode < 600:
-		return domain.AuthErrorTypeServer
-	default:
-		return domain.AuthErrorTypeRequest
+func (r *authRepository) isAllowedLogoutURL(logoutURI string) bool {
+	for _, allowed := range r.logoutURLs {
+		if logoutURI == allowed {
+			return true
+		}
 	}
+	return false
 }
-
-func (r *authRepository) validateTokenResponse(response *struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	IdToken      string `json:"id_token"`
-	ExpiresIn    int    `json:"expires_in"`
-}) error {
-	if response.AccessToken == "" {
-		return domain.NewAuthError(domain.AuthErrorTypeValidation, "アクセストークンが空です", nil)
-	}
-	if response.IdToken == "" {
-		return domain.NewAuthError(domain.AuthErrorTypeValidation, "IDトークンが空です", nil)
-	}
-	if response.ExpiresIn <= 0 {
-		return domain.NewAuthError(domain.AuthErrorTypeValidation, "無効な有効期限です", nil)
-	}
-	return nil
-}
\ No newline at end of file
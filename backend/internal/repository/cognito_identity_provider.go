@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+// cognitoAdminScope - ユーザー自身のCognito API（GlobalSignOut・GetUserなど）を呼ぶためのスコープ
+// ディスカバリードキュメントの scopes_supported には含まれない
+const cognitoAdminScope = "aws.cognito.signin.user.admin"
+
+// cognitoIdentityProviderNames - APIのプロバイダー名とCognitoのidentity_provider名の対応
+// infra/cognito.tf の supported_identity_providers に登録済みのもののみ
+var cognitoIdentityProviderNames = map[string]string{
+	"google":            "Google",
+	"facebook":          "Facebook",
+	"github":            "GitHub",
+	directLoginProvider: "COGNITO",
+}
+
+type CognitoProviderConfig struct {
+	// Domain - Hosted UIのドメイン（COGNITO_DOMAIN_URL）。ディスカバリーにない失効・ログアウトのエンドポイントに使う
+	Domain string
+	// Issuer - https://cognito-idp.{region}.amazonaws.com/{userPoolId}
+	Issuer   string
+	ClientID string
+	// Scopes - 認可リクエストで要求するスコープ（allowed_oauth_scopes の範囲内）
+	Scopes []string
+	// DiscoveryCacheFile / DiscoveryRefreshInterval - OIDCDiscoveryConfig を参照
+	DiscoveryCacheFile       string
+	DiscoveryRefreshInterval time.Duration
+}
+
+type cognitoIdentityProvider struct {
+	ITokenVerifier
+	client    *oauthClient
+	discovery IOIDCDiscovery
+	domain    string
+	scopes    []string
+}
+
+func NewCognitoIdentityProvider(config CognitoProviderConfig) IIdentityProvider {
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{
+		Issuer:          config.Issuer,
+		CacheFile:       config.DiscoveryCacheFile,
+		RefreshInterval: config.DiscoveryRefreshInterval,
+	})
+
+	return &cognitoIdentityProvider{
+		ITokenVerifier: NewTokenVerifier(TokenVerifierConfig{
+			Issuer:    config.Issuer,
+			ClientID:  config.ClientID,
+			Discovery: discovery,
+		}),
+		// アプリクライアントはシークレットなし（generate_secret = false）
+		client:    newOAuthClient("AUTH", config.ClientID, ""),
+		discovery: discovery,
+		domain:    strings.TrimSuffix(config.Domain, "/"),
+		scopes:    config.Scopes,
+	}
+}
+
+func (p *cognitoIdentityProvider) Name() string {
+	return IdentityProviderCognito
+}
+
+func (p *cognitoIdentityProvider) Discover(ctx context.Context) error {
+	return p.discovery.Load(ctx)
+}
+
+// BuildAuthorizeURL - Hosted UIの /oauth2/authorize のURLを構築する
+func (p *cognitoIdentityProvider) BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return "", err
+	}
+	// ユーザープールにドメインがない場合、ディスカバリーに authorization_endpoint が含まれない
+	if document.AuthorizationEndpoint == "" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "ユーザープールにHosted UIのドメインが設定されていません", nil)
+	}
+
+	identityProvider, ok := cognitoIdentityProviderNames[state.Provider]
+	if !ok {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "このプロバイダーは設定されていません", nil)
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.client.clientID)
+	query.Set("redirect_uri", state.RedirectURI)
+	query.Set("identity_provider", identityProvider)
+	query.Set("scope", strings.Join(p.supportedScopes(document), " "))
+	query.Set("state", state.State)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", state.CodeChallenge)
+	query.Set("code_challenge_method", utils.PKCEMethodS256)
+
+	return withQuery(document.AuthorizationEndpoint, query), nil
+}
+
+func (p *cognitoIdentityProvider) ExchangeCode(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.exchangeCode(ctx, document.TokenEndpoint, authCode, codeVerifier, redirectURI)
+}
+
+// RefreshTokens - リフレッシュトークンで新しいアクセストークン・IDトークンを取得
+func (p *cognitoIdentityProvider) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.refreshTokens(ctx, document.TokenEndpoint, refreshToken)
+}
+
+// RevokeToken - /oauth2/revoke でリフレッシュトークンと、それに紐づくアクセストークンを失効させる
+func (p *cognitoIdentityProvider) RevokeToken(ctx context.Context, refreshToken string) error {
+	revokeURL, err := p.hostedUIEndpoint(ctx, func(d *OIDCDiscoveryDocument) string { return d.RevocationEndpoint }, "/oauth2/revoke")
+	if err != nil {
+		return err
+	}
+	return p.client.revokeToken(ctx, revokeURL, refreshToken)
+}
+
+// GetUserInfo - /oauth2/userInfo（openidスコープを含むアクセストークンが必要）
+func (p *cognitoIdentityProvider) GetUserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.getUserInfo(ctx, document.UserInfoEndpoint, accessToken)
+}
+
+// BuildLogoutURL - Hosted UIのログアウトURLを構築する
+// Cognitoは登録済みのlogout_uriと完全一致しない場合エラー画面を表示する
+func (p *cognitoIdentityProvider) BuildLogoutURL(ctx context.Context, logoutURI string) (string, error) {
+	logoutURL, err := p.hostedUIEndpoint(ctx, func(d *OIDCDiscoveryDocument) string { return d.EndSessionEndpoint }, "/logout")
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("client_id", p.client.clientID)
+	query.Set("logout_uri", logoutURI)
+
+	return withQuery(logoutURL, query), nil
+}
+
+// supportedScopes - scopes_supported にないCognito独自のスコープは設定されていれば送る
+func (p *cognitoIdentityProvider) supportedScopes(document *OIDCDiscoveryDocument) []string {
+	scopes := document.SupportedScopes(p.scopes)
+	if containsString(p.scopes, cognitoAdminScope) && !containsString(scopes, cognitoAdminScope) {
+		scopes = append(scopes, cognitoAdminScope)
+	}
+	return scopes
+}
+
+// hostedUIEndpoint - Cognitoのディスカバリードキュメントは失効・ログアウトのエンドポイントを含まないため、
+// ドキュメントにない場合はHosted UIのドメインから構築する
+func (p *cognitoIdentityProvider) hostedUIEndpoint(ctx context.Context, fromDocument func(*OIDCDiscoveryDocument) string, path string) (string, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return "", err
+	}
+	if endpoint := fromDocument(document); endpoint != "" {
+		return endpoint, nil
+	}
+	if p.domain == "" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "COGNITO_DOMAIN_URL環境変数が設定されていません", nil)
+	}
+	return fmt.Sprintf("%s%s", p.domain, path), nil
+}
+
+// ProviderFromClaims - フェデレーションユーザーはidentitiesクレームにIdP名を持つ
+func (p *cognitoIdentityProvider) ProviderFromClaims(claims map[string]interface{}) string {
+	if identities, ok := claims["identities"].([]interface{}); ok && len(identities) > 0 {
+		if identity, ok := identities[0].(map[string]interface{}); ok {
+			if name, _ := identity["providerName"].(string); name != "" {
+				return strings.ToLower(name)
+			}
+		}
+	}
+	return directLoginProvider
+}
+
+func (p *cognitoIdentityProvider) GroupsFromClaims(claims map[string]interface{}) []string {
+	return stringSliceClaim(claims, "cognito:groups")
+}
+
+// stringSliceClaim - cognito:groups などの文字列配列クレーム
+func stringSliceClaim(claims map[string]interface{}, key string) []string {
+	values, _ := claims[key].([]interface{})
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
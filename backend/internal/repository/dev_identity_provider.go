@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	devIssuer   = "urn:dev-identity-provider"
+	devClientID = "dev-client"
+
+	// devTokenUseCode / devTokenUseRefresh - 開発用IdPだけが発行する認可コード・リフレッシュトークン
+	devTokenUseCode    = "code"
+	devTokenUseRefresh = "refresh"
+
+	devCodeTTL    = 5 * time.Minute
+	devTokenTTL   = time.Hour
+	devRefreshTTL = 24 * time.Hour
+)
+
+// DevProviderConfig - ローカル開発用IdPでログインするユーザー
+type DevProviderConfig struct {
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+}
+
+// devIdentityProvider - Cognitoを使わずに認可コードフローを通すローカル開発専用のIdP
+// 起動ごとに生成した鍵でHS256のトークンを発行・検証するため、このプロセスが発行したトークン以外は受け付けない
+type devIdentityProvider struct {
+	config DevProviderConfig
+	key    []byte
+	now    func() time.Time
+}
+
+func NewDevIdentityProvider(config DevProviderConfig) (IIdentityProvider, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &devIdentityProvider{config: config, key: key, now: time.Now}, nil
+}
+
+func (p *devIdentityProvider) Name() string {
+	return IdentityProviderDev
+}
+
+func (p *devIdentityProvider) Discover(ctx context.Context) error {
+	if p.config.Subject == "" || p.config.Email == "" {
+		return domain.NewAuthError(domain.AuthErrorTypeConfig, "開発用IdPのユーザーが設定されていません", nil)
+	}
+	return nil
+}
+
+// BuildAuthorizeURL - ログイン画面を経由せず、認可コードを付けてredirect_uriへ直接戻す
+func (p *devIdentityProvider) BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error) {
+	code, err := p.sign(jwt.MapClaims{
+		"token_use":      devTokenUseCode,
+		"nonce":          state.Nonce,
+		"code_challenge": state.CodeChallenge,
+		"redirect_uri":   state.RedirectURI,
+	}, devCodeTTL)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("code", code)
+	query.Set("state", state.State)
+
+	return withQuery(state.RedirectURI, query), nil
+}
+
+func (p *devIdentityProvider) ExchangeCode(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error) {
+	claims, err := p.parse(authCode, devTokenUseCode)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, _ := claims["code_challenge"].(string)
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(utils.CodeChallengeS256(codeVerifier))) != 1 {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "code_verifierが一致しません", nil)
+	}
+	if uri, _ := claims["redirect_uri"].(string); uri != redirectURI {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "redirect_uriが一致しません", nil)
+	}
+
+	nonce, _ := claims["nonce"].(string)
+	return p.issueTokens(nonce)
+}
+
+func (p *devIdentityProvider) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	if _, err := p.parse(refreshToken, devTokenUseRefresh); err != nil {
+		return nil, err
+	}
+	return p.issueTokens("")
+}
+
+// RevokeToken - 発行済みトークンを記録しないため、失効は期限切れを待つ
+func (p *devIdentityProvider) RevokeToken(ctx context.Context, refreshToken string) error {
+	return nil
+}
+
+func (p *devIdentityProvider) GetUserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	if _, err := p.VerifyAccessToken(ctx, accessToken); err != nil {
+		return nil, err
+	}
+	return p.profileClaims(), nil
+}
+
+// BuildLogoutURL - IdP側のセッションがないため、logout_uriへそのまま戻す
+func (p *devIdentityProvider) BuildLogoutURL(ctx context.Context, logoutURI string) (string, error) {
+	return logoutURI, nil
+}
+
+func (p *devIdentityProvider) ProviderFromClaims(claims map[string]interface{}) string {
+	return directLoginProvider
+}
+
+func (p *devIdentityProvider) GroupsFromClaims(claims map[string]interface{}) []string {
+	return stringSliceClaim(claims, "cognito:groups")
+}
+
+func (p *devIdentityProvider) VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	return p.parse(token, tokenUseID, jwt.WithAudience(devClientID))
+}
+
+func (p *devIdentityProvider) VerifyAccessToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	return p.parse(token, tokenUseAccess)
+}
+
+func (p *devIdentityProvider) VerifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := p.parse(token, "")
+	if err != nil {
+		return nil, err
+	}
+	switch use, _ := claims["token_use"].(string); use {
+	case tokenUseID, tokenUseAccess:
+		return claims, nil
+	default:
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "不明なtoken_useです", nil)
+	}
+}
+
+func (p *devIdentityProvider) issueTokens(nonce string) (*domain.AuthTokens, error) {
+	idClaims := p.profileClaims()
+	idClaims["token_use"] = tokenUseID
+	idClaims["aud"] = devClientID
+	if nonce != "" {
+		idClaims["nonce"] = nonce
+	}
+	idToken, err := p.sign(idClaims, devTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := p.sign(jwt.MapClaims{
+		"sub":            p.config.Subject,
+		"token_use":      tokenUseAccess,
+		"client_id":      devClientID,
+		"cognito:groups": p.groups(),
+	}, devTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := p.sign(jwt.MapClaims{
+		"sub":       p.config.Subject,
+		"token_use": devTokenUseRefresh,
+	}, devRefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		IdToken:      idToken,
+		ExpiresIn:    int(devTokenTTL.Seconds()),
+	}, nil
+}
+
+func (p *devIdentityProvider) profileClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":            p.config.Subject,
+		"email":          p.config.Email,
+		"email_verified": true,
+		"name":           p.config.Name,
+		"cognito:groups": p.groups(),
+	}
+}
+
+func (p *devIdentityProvider) groups() []interface{} {
+	groups := make([]interface{}, 0, len(p.config.Groups))
+	for _, g := range p.config.Groups {
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+func (p *devIdentityProvider) sign(claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := p.now()
+	claims["iss"] = devIssuer
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.key)
+	if err != nil {
+		return "", domain.NewAuthError(domain.AuthErrorTypeServer, "トークンの発行に失敗しました", err)
+	}
+	return signed, nil
+}
+
+// parse - 署名・iss・expを検証し、tokenUseが指定された場合はtoken_useの一致も確認する
+func (p *devIdentityProvider) parse(token, tokenUse string, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
+	opts = append(opts,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(devIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(p.now),
+	)
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return p.key, nil
+	}, opts...); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンの検証に失敗しました", err)
+	}
+
+	if use, _ := claims["token_use"].(string); tokenUse != "" && use != tokenUse {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンの種類が一致しません", nil)
+	}
+	return claims, nil
+}
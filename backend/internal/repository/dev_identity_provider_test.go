@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+func newTestDevProvider(t *testing.T) *devIdentityProvider {
+	t.Helper()
+	provider, err := NewDevIdentityProvider(DevProviderConfig{
+		Subject: "dev-user",
+		Email:   "dev@example.com",
+		Name:    "Dev User",
+		Groups:  []string{"admin"},
+	})
+	if err != nil {
+		t.Fatalf("NewDevIdentityProvider: %v", err)
+	}
+	return provider.(*devIdentityProvider)
+}
+
+// devAuthorize - 認可URLからredirect_uriに付けられた認可コードを取り出す
+func devAuthorize(t *testing.T, provider *devIdentityProvider, state *domain.OAuthState) string {
+	t.Helper()
+	authorizeURL, err := provider.BuildAuthorizeURL(context.Background(), state)
+	if err != nil {
+		t.Fatalf("BuildAuthorizeURL: %v", err)
+	}
+	parsed, err := url.Parse(authorizeURL)
+	if err != nil {
+		t.Fatalf("parse authorize URL: %v", err)
+	}
+	if got := parsed.Query().Get("state"); got != state.State {
+		t.Fatalf("state = %q, want %q", got, state.State)
+	}
+	return parsed.Query().Get("code")
+}
+
+func TestDevIdentityProvider_AuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestDevProvider(t)
+	verifier := "dev-code-verifier-0123456789-0123456789-0123456789"
+	state := &domain.OAuthState{
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: utils.CodeChallengeS256(verifier),
+		RedirectURI:   "http://localhost:3000/callback",
+	}
+	code := devAuthorize(t, provider, state)
+
+	tokens, err := provider.ExchangeCode(ctx, code, verifier, state.RedirectURI)
+	if err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IdToken)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims["sub"] != "dev-user" || claims["email"] != "dev@example.com" || claims["nonce"] != "nonce-1" {
+		t.Errorf("unexpected ID token claims: %v", claims)
+	}
+	if groups := provider.GroupsFromClaims(claims); len(groups) != 1 || groups[0] != "admin" {
+		t.Errorf("groups = %v, want [admin]", groups)
+	}
+
+	if _, err := provider.VerifyToken(ctx, tokens.AccessToken); err != nil {
+		t.Errorf("VerifyToken(access): %v", err)
+	}
+	userInfo, err := provider.GetUserInfo(ctx, tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if userInfo["sub"] != "dev-user" {
+		t.Errorf("userInfo sub = %v", userInfo["sub"])
+	}
+
+	refreshed, err := provider.RefreshTokens(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(ctx, refreshed.IdToken); err != nil {
+		t.Errorf("VerifyIDToken(refreshed): %v", err)
+	}
+}
+
+func TestDevIdentityProvider_RejectsInvalidCode(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestDevProvider(t)
+	verifier := "dev-code-verifier-0123456789-0123456789-0123456789"
+	state := &domain.OAuthState{
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: utils.CodeChallengeS256(verifier),
+		RedirectURI:   "http://localhost:3000/callback",
+	}
+	code := devAuthorize(t, provider, state)
+
+	t.Run("code_verifierが一致しない", func(t *testing.T) {
+		_, err := provider.ExchangeCode(ctx, code, verifier+"x", state.RedirectURI)
+		assertSecurityError(t, err)
+	})
+
+	t.Run("redirect_uriが一致しない", func(t *testing.T) {
+		_, err := provider.ExchangeCode(ctx, code, verifier, "http://localhost:5173/callback")
+		assertSecurityError(t, err)
+	})
+
+	t.Run("固定文字列のトークン", func(t *testing.T) {
+		_, err := provider.ExchangeCode(ctx, "mock_code", verifier, state.RedirectURI)
+		assertSecurityError(t, err)
+	})
+
+	t.Run("期限切れ", func(t *testing.T) {
+		provider.now = func() time.Time { return time.Now().Add(devCodeTTL + time.Minute) }
+		defer func() { provider.now = time.Now }()
+		_, err := provider.ExchangeCode(ctx, code, verifier, state.RedirectURI)
+		assertSecurityError(t, err)
+	})
+}
+
+func TestDevIdentityProvider_RejectsOtherTokens(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestDevProvider(t)
+	tokens, err := provider.issueTokens("")
+	if err != nil {
+		t.Fatalf("issueTokens: %v", err)
+	}
+
+	t.Run("固定文字列のIDトークン", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(ctx, "mock_id_token")
+		assertSecurityError(t, err)
+	})
+
+	t.Run("別のプロセスが発行したトークン", func(t *testing.T) {
+		_, err := newTestDevProvider(t).VerifyIDToken(ctx, tokens.IdToken)
+		assertSecurityError(t, err)
+	})
+
+	t.Run("アクセストークンをIDトークンとして使う", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(ctx, tokens.AccessToken)
+		assertSecurityError(t, err)
+	})
+
+	t.Run("リフレッシュトークンをAPIの認証に使う", func(t *testing.T) {
+		_, err := provider.VerifyToken(ctx, tokens.RefreshToken)
+		assertSecurityError(t, err)
+	})
+
+	t.Run("IDトークンでリフレッシュする", func(t *testing.T) {
+		_, err := provider.RefreshTokens(ctx, tokens.IdToken)
+		assertSecurityError(t, err)
+	})
+}
+
+func TestDevIdentityProvider_DiscoverRequiresUser(t *testing.T) {
+	provider, err := NewDevIdentityProvider(DevProviderConfig{})
+	if err != nil {
+		t.Fatalf("NewDevIdentityProvider: %v", err)
+	}
+	if err := provider.Discover(context.Background()); err == nil {
+		t.Fatal("expected error for missing user, got nil")
+	}
+}
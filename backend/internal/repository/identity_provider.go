@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	IdentityProviderCognito = "cognito"
+	IdentityProviderOIDC    = "oidc"
+	// IdentityProviderDev - ローカル開発専用（GO_ENV=development の場合のみ起動できる）
+	IdentityProviderDev = "dev"
+
+	// directLoginProvider - IdP自身のアカウントでのログインを表すAPIのプロバイダー名
+	directLoginProvider = "cognito"
+)
+
+// IIdentityProvider - 認可コードフローのトークン発行・検証を行うIdP
+// Cognito（Hosted UI）と汎用OIDC（Keycloak・Auth0など）の実装があり、IDENTITY_PROVIDER で切り替える
+type IIdentityProvider interface {
+	ITokenVerifier
+
+	// Name - 実装名（IdentityProviderCognito / IdentityProviderOIDC / IdentityProviderDev）
+	Name() string
+	// Discover - 起動時に発行者のディスカバリードキュメントを取得する（失敗した場合は起動を中止する）
+	Discover(ctx context.Context) error
+	// BuildAuthorizeURL - 認可エンドポイントのURLを構築する（state.Provider で外部IdPを指定する）
+	BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error)
+	ExchangeCode(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error)
+	RevokeToken(ctx context.Context, refreshToken string) error
+	GetUserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error)
+	// BuildLogoutURL - logoutURIは登録済みのものであることを呼び出し側で検証する
+	BuildLogoutURL(ctx context.Context, logoutURI string) (string, error)
+	// ProviderFromClaims - ログインに使われたAPIのプロバイダー名（google・githubなど）
+	ProviderFromClaims(claims map[string]interface{}) string
+	// GroupsFromClaims - ロールの元になるグループ
+	GroupsFromClaims(claims map[string]interface{}) []string
+}
+
+// oauthClient - OAuth 2.0のトークン・失効・userinfoエンドポイントへのリクエスト
+// clientSecretがある場合は client_secret_basic、ない場合は公開クライアントとして client_id を送る
+type oauthClient struct {
+	httpClient   *httpclient.Client
+	logger       *logger.Logger
+	clientID     string
+	clientSecret string
+}
+
+func newOAuthClient(name, clientID, clientSecret string) *oauthClient {
+	httpConfig := httpclient.Config{
+		Timeout:     30 * time.Second,
+		MaxRetries:  3,
+		BaseBackoff: 1 * time.Second,
+		MaxBackoff:  30 * time.Second,
+		JitterMax:   1 * time.Second,
+	}
+
+	clientLogger := logger.New(name)
+
+	return &oauthClient{
+		httpClient:   httpclient.NewClient(httpConfig, clientLogger),
+		logger:       clientLogger,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
+func (c *oauthClient) exchangeCode(ctx context.Context, tokenURL, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", authCode)
+	data.Set("redirect_uri", redirectURI)
+	data.Set("code_verifier", codeVerifier)
+
+	c.logger.Debug("トークン交換リクエスト開始", map[string]interface{}{
+		"code_masked": utils.MaskSensitiveData(authCode, 4, 4, ""),
+		"client_id":   c.clientID,
+	})
+
+	tokens, err := c.requestTokens(ctx, tokenURL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("トークン交換成功", map[string]interface{}{
+		"client_id": c.clientID,
+	})
+
+	return tokens, nil
+}
+
+func (c *oauthClient) refreshTokens(ctx context.Context, tokenURL, refreshToken string) (*domain.AuthTokens, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+
+	c.logger.Debug("トークン更新リクエスト開始", map[string]interface{}{
+		"refresh_token_masked": utils.MaskSensitiveData(refreshToken, 4, 4, ""),
+		"client_id":            c.clientID,
+	})
+
+	tokens, err := c.requestTokens(ctx, tokenURL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cognitoなど、refresh_tokenグラントで新しいリフレッシュトークンを返さないIdPがある
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = refreshToken
+	}
+
+	c.logger.Info("トークン更新成功", map[string]interface{}{
+		"client_id": c.clientID,
+	})
+
+	return tokens, nil
+}
+
+// requestTokens - トークンエンドポイントへのリクエスト送信とレスポンス検証（リトライ・エラー分類込み）
+func (c *oauthClient) requestTokens(ctx context.Context, tokenURL string, data url.Values) (*domain.AuthTokens, error) {
+	req, err := c.newFormRequest(ctx, tokenURL, data)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeNetwork, "ネットワーク接続に失敗しました", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errorResponse)
+
+		return nil, domain.NewAuthErrorWithCode(
+			categorizeTokenError(resp.StatusCode, errorResponse.Error),
+			resp.StatusCode,
+			"認証サーバーエラー",
+		)
+	}
+
+	var tokenResponse tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeParse, "レスポンス解析に失敗しました", err)
+	}
+
+	if err := tokenResponse.validate(); err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		IdToken:      tokenResponse.IdToken,
+		ExpiresIn:    tokenResponse.ExpiresIn,
+	}, nil
+}
+
+// revokeToken - RFC 7009 の失効エンドポイントでリフレッシュトークンを失効させる
+func (c *oauthClient) revokeToken(ctx context.Context, revokeURL, refreshToken string) error {
+	data := url.Values{}
+	data.Set("token", refreshToken)
+
+	req, err := c.newFormRequest(ctx, revokeURL, data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeNetwork, "ネットワーク接続に失敗しました", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errorResponse)
+
+		c.logger.Error("トークン失効失敗", map[string]interface{}{
+			"status": resp.StatusCode,
+			"error":  errorResponse.Error,
+		})
+
+		return domain.NewAuthErrorWithCode(
+			categorizeRevokeError(resp.StatusCode, errorResponse.Error),
+			resp.StatusCode,
+			"トークンの失効に失敗しました",
+		)
+	}
+
+	c.logger.Info("トークン失効成功", map[string]interface{}{
+		"client_id": c.clientID,
+	})
+
+	return nil
+}
+
+// getUserInfo - userinfoエンドポイントからアクセストークンの利用者のクレームを取得する
+func (c *oauthClient) getUserInfo(ctx context.Context, userInfoURL, accessToken string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", userInfoURL, nil)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeRequest, "リクエスト作成に失敗しました", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeNetwork, "ネットワーク接続に失敗しました", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, domain.NewAuthErrorWithCode(domain.AuthErrorTypeSecurity, resp.StatusCode, "アクセストークンが無効です")
+	case resp.StatusCode != http.StatusOK:
+		return nil, domain.NewAuthErrorWithCode(categorizeHTTPError(resp.StatusCode), resp.StatusCode, "ユーザー情報の取得に失敗しました")
+	}
+
+	var claims map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeParse, "レスポンス解析に失敗しました", err)
+	}
+
+	return claims, nil
+}
+
+func (c *oauthClient) newFormRequest(ctx context.Context, endpoint string, data url.Values) (*http.Request, error) {
+	if c.clientSecret == "" {
+		data.Set("client_id", c.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeRequest, "リクエスト作成に失敗しました", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	}
+
+	return req, nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IdToken      string `json:"id_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func (r *tokenResponse) validate() error {
+	if r.AccessToken == "" {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "アクセストークンが空です", nil)
+	}
+	if r.IdToken == "" {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "IDトークンが空です", nil)
+	}
+	if r.ExpiresIn <= 0 {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "無効な有効期限です", nil)
+	}
+	return nil
+}
+
+// categorizeTokenError - トークンエンドポイントのエラーコードを分類する
+func categorizeTokenError(statusCode int, errorCode string) domain.AuthErrorType {
+	// 認可コードの再利用やcode_verifierの不一致
+	if errorCode == "invalid_grant" {
+		return domain.AuthErrorTypeSecurity
+	}
+	return categorizeHTTPError(statusCode)
+}
+
+// categorizeRevokeError - 失効エンドポイントのエラーコードを分類する
+func categorizeRevokeError(statusCode int, errorCode string) domain.AuthErrorType {
+	switch errorCode {
+	case "invalid_client", "unauthorized_client":
+		// アプリクライアントでトークン失効が無効化されている等
+		return domain.AuthErrorTypeConfig
+	case "unsupported_token_type":
+		return domain.AuthErrorTypeValidation
+	case "invalid_request":
+		return domain.AuthErrorTypeClient
+	}
+	return categorizeHTTPError(statusCode)
+}
+
+func categorizeHTTPError(statusCode int) domain.AuthErrorType {
+	switch {
+	case statusCode >= 400 && statusCode < 500:
+		return domain.AuthErrorTypeClient
+	case statusCode >= 500 && statusCode < 600:
+		return domain.AuthErrorTypeServer
+	default:
+		return domain.AuthErrorTypeRequest
+	}
+}
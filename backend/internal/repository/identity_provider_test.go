@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+)
+
+// fakeDiscovery - 固定のディスカバリードキュメントを返す
+type fakeDiscovery struct {
+	document *OIDCDiscoveryDocument
+}
+
+func (d *fakeDiscovery) Load(ctx context.Context) error {
+	return nil
+}
+
+func (d *fakeDiscovery) Document(ctx context.Context) (*OIDCDiscoveryDocument, error) {
+	return d.document, nil
+}
+
+// newTestOAuthClient - 5xxの再試行でテストが遅くならないようにする
+func newTestOAuthClient(clientID string) *oauthClient {
+	clientLogger := logger.New("TEST")
+	return &oauthClient{
+		httpClient: httpclient.NewClient(httpclient.Config{Timeout: time.Second}, clientLogger),
+		logger:     clientLogger,
+		clientID:   clientID,
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType domain.AuthErrorType
+	}{
+		{"成功", http.StatusOK, "", ""},
+		{"トークン失効が無効なクライアント", http.StatusBadRequest, `{"error":"invalid_client"}`, domain.AuthErrorTypeConfig},
+		{"許可されていないクライアント", http.StatusBadRequest, `{"error":"unauthorized_client"}`, domain.AuthErrorTypeConfig},
+		{"リフレッシュトークン以外", http.StatusBadRequest, `{"error":"unsupported_token_type"}`, domain.AuthErrorTypeValidation},
+		{"不正なリクエスト", http.StatusBadRequest, `{"error":"invalid_request"}`, domain.AuthErrorTypeClient},
+		{"IdPの障害", http.StatusServiceUnavailable, "", domain.AuthErrorTypeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("failed to parse form: %v", err)
+				}
+				form = r.PostForm
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := newTestOAuthClient("client-id").revokeToken(context.Background(), server.URL, "refresh-token")
+
+			// 公開クライアントはclient_idを送る
+			if form.Get("token") != "refresh-token" || form.Get("client_id") != "client-id" {
+				t.Errorf("unexpected revoke request: %v", form)
+			}
+			if tt.wantType == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) {
+				t.Fatalf("expected *domain.AuthError, got %v", err)
+			}
+			if authErr.Type != tt.wantType || authErr.Code != tt.status {
+				t.Errorf("error = %s (%d), want %s (%d)", authErr.Type, authErr.Code, tt.wantType, tt.status)
+			}
+		})
+	}
+}
+
+func TestCognitoBuildLogoutURL(t *testing.T) {
+	provider := &cognitoIdentityProvider{
+		client:    newTestOAuthClient("client-id"),
+		discovery: &fakeDiscovery{document: &OIDCDiscoveryDocument{}},
+		domain:    "https://auth.example.com",
+	}
+
+	// Cognitoのディスカバリードキュメントにはログアウトのエンドポイントがないため、Hosted UIのドメインから構築する
+	logoutURL, err := provider.BuildLogoutURL(context.Background(), "http://localhost:5173")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(logoutURL)
+	if err != nil {
+		t.Fatalf("invalid logout URL: %v", err)
+	}
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != "https://auth.example.com/logout" {
+		t.Errorf("endpoint = %s", got)
+	}
+	if parsed.Query().Get("client_id") != "client-id" || parsed.Query().Get("logout_uri") != "http://localhost:5173" {
+		t.Errorf("unexpected query: %v", parsed.Query())
+	}
+
+	provider.domain = ""
+	_, err = provider.BuildLogoutURL(context.Background(), "http://localhost:5173")
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeConfig {
+		t.Errorf("expected config error without COGNITO_DOMAIN_URL, got %v", err)
+	}
+}
+
+func TestOIDCBuildLogoutURL(t *testing.T) {
+	provider := &oidcIdentityProvider{
+		discovery: &fakeDiscovery{document: &OIDCDiscoveryDocument{EndSessionEndpoint: "https://idp.example.com/logout?ui_locales=ja"}},
+		config:    OIDCProviderConfig{ClientID: "client-id"},
+	}
+
+	logoutURL, err := provider.BuildLogoutURL(context.Background(), "http://localhost:5173")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(logoutURL)
+	if err != nil {
+		t.Fatalf("invalid logout URL: %v", err)
+	}
+	// エンドポイントの既存のクエリは残す
+	query := parsed.Query()
+	if query.Get("ui_locales") != "ja" || query.Get("client_id") != "client-id" || query.Get("post_logout_redirect_uri") != "http://localhost:5173" {
+		t.Errorf("unexpected query: %v", query)
+	}
+}
+
+func TestAuthRepository_BuildLogoutURL(t *testing.T) {
+	repo := NewAuthRepository(AuthConfig{
+		IdentityProvider: &cognitoIdentityProvider{
+			client:    newTestOAuthClient("client-id"),
+			discovery: &fakeDiscovery{document: &OIDCDiscoveryDocument{}},
+			domain:    "https://auth.example.com",
+		},
+		LogoutURLs: []string{"http://localhost:5173", "https://app.example.com"},
+	})
+
+	// 省略した場合は最初の登録済みURL
+	logoutURL, err := repo.BuildLogoutURL(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(logoutURL, "logout_uri="+url.QueryEscape("http://localhost:5173")) {
+		t.Errorf("expected default logout URI, got %s", logoutURL)
+	}
+
+	// 登録されていないURLへはリダイレクトさせない
+	for _, logoutURI := range []string{"https://attacker.example.com", "https://app.example.com/path"} {
+		_, err := repo.BuildLogoutURL(context.Background(), logoutURI)
+		assertSecurityError(t, err)
+	}
+}
+
+func TestCognitoBuildAuthorizeURL_KeepsAdminScope(t *testing.T) {
+	provider := &cognitoIdentityProvider{
+		client: newTestOAuthClient("client-id"),
+		discovery: &fakeDiscovery{document: &OIDCDiscoveryDocument{
+			AuthorizationEndpoint: "https://auth.example.com/oauth2/authorize",
+			// Cognitoの scopes_supported には aws.cognito.signin.user.admin が含まれない
+			ScopesSupported: []string{"openid", "email", "phone", "profile"},
+		}},
+		scopes: []string{"openid", "email", "profile", "aws.cognito.signin.user.admin", "unknown"},
+	}
+
+	authorizeURL, err := provider.BuildAuthorizeURL(context.Background(), &domain.OAuthState{
+		State:         "state",
+		Nonce:         "nonce",
+		Provider:      "google",
+		CodeChallenge: "challenge",
+		RedirectURI:   "http://localhost:5173/auth/callback",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(authorizeURL)
+	if err != nil {
+		t.Fatalf("invalid authorize URL: %v", err)
+	}
+	if got, want := parsed.Query().Get("scope"), "openid email profile aws.cognito.signin.user.admin"; got != want {
+		t.Errorf("scope = %q, want %q", got, want)
+	}
+}
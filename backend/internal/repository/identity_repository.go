@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const constraintUserIdentitiesProviderSubject = "idx_user_identities_provider_subject"
+
+type IIdentityRepository interface {
+	GetIdentity(ctx context.Context, provider, subjectID string) (*domain.UserIdentity, error)
+	ListIdentitiesByUserID(ctx context.Context, userID uint) ([]domain.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *domain.UserIdentity) error
+	DeleteIdentity(ctx context.Context, userID, identityID uint) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IIdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) GetIdentity(ctx context.Context, provider, subjectID string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject_id = ?", provider, subjectID).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) ListIdentitiesByUserID(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// CreateIdentity - 既に別ユーザーに紐付いている場合は domain.ErrIdentityAlreadyLinked を返す
+func (r *identityRepository) CreateIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	err := r.db.WithContext(ctx).Create(identity).Error
+	if uniqueViolationConstraint(err) == constraintUserIdentitiesProviderSubject {
+		return domain.ErrIdentityAlreadyLinked
+	}
+	return err
+}
+
+// DeleteIdentity - ユーザーのIDを解除する。最後の1件は解除できない
+func (r *identityRepository) DeleteIdentity(ctx context.Context, userID, identityID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 同時解除で0件にならないよう、ユーザーのID行をロックしてから数える
+		var identities []domain.UserIdentity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Find(&identities).Error; err != nil {
+			return err
+		}
+
+		found := false
+		for _, identity := range identities {
+			if identity.ID == identityID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return domain.ErrIdentityNotFound
+		}
+		if len(identities) <= 1 {
+			return domain.ErrLastIdentity
+		}
+
+		return tx.Where("id = ? AND user_id = ?", identityID, userID).Delete(&domain.UserIdentity{}).Error
+	})
+}
+
+// BackfillUserIdentities - identitiesテーブル導入前のユーザーの (provider, subject_id) を移行する
+func BackfillUserIdentities(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject_id, email, created_at, updated_at)
+		SELECT id, provider, subject_id, email, created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		ON CONFLICT DO NOTHING
+	`).Error
+}
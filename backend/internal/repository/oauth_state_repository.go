@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IOAuthStateRepository interface {
+	CreateState(ctx context.Context, state *domain.OAuthState) error
+	ConsumeState(ctx context.Context, state string) (*domain.OAuthState, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type oauthStateRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthStateRepository(db *gorm.DB) IOAuthStateRepository {
+	return &oauthStateRepository{db: db}
+}
+
+func (r *oauthStateRepository) CreateState(ctx context.Context, state *domain.OAuthState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState - 未使用のstateを使用済みにして返す（1回限り）
+// 使用済みの場合は domain.ErrOAuthStateReused、存在しない場合は domain.ErrOAuthStateNotFound を返す
+func (r *oauthStateRepository) ConsumeState(ctx context.Context, state string) (*domain.OAuthState, error) {
+	var consumed domain.OAuthState
+	result := r.db.WithContext(ctx).
+		Model(&consumed).
+		Clauses(clause.Returning{}).
+		Where("state = ? AND used_at IS NULL", state).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &consumed, nil
+	}
+
+	var existing domain.OAuthState
+	err := r.db.WithContext(ctx).Where("state = ?", state).First(&existing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOAuthStateNotFound
+		}
+		return nil, err
+	}
+	return nil, domain.ErrOAuthStateReused
+}
+
+// DeleteExpired - 有効期限切れのstateを削除する（使用済みも含む）
+func (r *oauthStateRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&domain.OAuthState{})
+	return result.RowsAffected, result.Error
+}
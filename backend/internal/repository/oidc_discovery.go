@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultDiscoveryRefreshInterval = time.Hour
+	// discoveryRetryInterval - 再取得に失敗した場合、前回のドキュメントを使いながら再試行する間隔
+	discoveryRetryInterval = time.Minute
+	// discoveryRefreshKey - 同時に発生した再取得を1回にまとめるためのキー
+	discoveryRefreshKey = "refresh"
+)
+
+// OIDCDiscoveryDocument - /.well-known/openid-configuration の使用する項目
+type OIDCDiscoveryDocument struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint"`
+	RevocationEndpoint               string   `json:"revocation_endpoint"`
+	EndSessionEndpoint               string   `json:"end_session_endpoint"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	ScopesSupported                  []string `json:"scopes_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// SupportedScopes - scopesのうちIdPが対応しているもの（scopes_supported がない場合はすべて）
+func (d *OIDCDiscoveryDocument) SupportedScopes(scopes []string) []string {
+	if len(d.ScopesSupported) == 0 {
+		return scopes
+	}
+	supported := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if containsString(d.ScopesSupported, scope) {
+			supported = append(supported, scope)
+		}
+	}
+	return supported
+}
+
+// IOIDCDiscovery - 発行者のディスカバリードキュメントを取得・キャッシュする
+type IOIDCDiscovery interface {
+	// Load - 起動時に取得する。取得できない場合はキャッシュファイルを使い、どちらもなければエラーを返す
+	Load(ctx context.Context) error
+	// Document - 取得済みのドキュメント。更新間隔を過ぎていればバックグラウンドで再取得し、その間は前回のものを返す
+	Document(ctx context.Context) (*OIDCDiscoveryDocument, error)
+}
+
+type OIDCDiscoveryConfig struct {
+	Issuer string
+	// CacheFile - 最後に取得したドキュメントの保存先（空の場合は保存しない）
+	CacheFile string
+	// RefreshInterval - 再取得の間隔（既定は1時間）
+	RefreshInterval time.Duration
+}
+
+type oidcDiscovery struct {
+	httpClient      *httpclient.Client
+	logger          *logger.Logger
+	issuer          string
+	cacheFile       string
+	refreshInterval time.Duration
+	// refreshGroup - 再取得はここでまとめて行い、mu はドキュメントの読み書きの間だけ保持する
+	refreshGroup singleflight.Group
+
+	mu          sync.Mutex
+	document    *OIDCDiscoveryDocument
+	nextRefresh time.Time
+}
+
+func NewOIDCDiscovery(config OIDCDiscoveryConfig) IOIDCDiscovery {
+	httpConfig := httpclient.Config{
+		Timeout:     10 * time.Second,
+		MaxRetries:  2,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		JitterMax:   500 * time.Millisecond,
+	}
+
+	discoveryLogger := logger.New("OIDC_DISCOVERY")
+
+	refreshInterval := config.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultDiscoveryRefreshInterval
+	}
+
+	return &oidcDiscovery{
+		httpClient:      httpclient.NewClient(httpConfig, discoveryLogger),
+		logger:          discoveryLogger,
+		issuer:          strings.TrimSuffix(config.Issuer, "/"),
+		cacheFile:       config.CacheFile,
+		refreshInterval: refreshInterval,
+	}
+}
+
+func (d *oidcDiscovery) Load(ctx context.Context) error {
+	_, fetchErr := d.refresh(ctx)
+	if fetchErr == nil {
+		return nil
+	}
+
+	document, err := d.readCache()
+	if err != nil {
+		return fmt.Errorf("discovery for %s is unreachable and no cached copy is available: %w (cache: %v)", d.issuer, fetchErr, err)
+	}
+
+	d.logger.Warn("ディスカバリーに失敗したためキャッシュを使用します", map[string]interface{}{
+		"issuer": d.issuer,
+		"error":  fetchErr.Error(),
+	})
+	d.mu.Lock()
+	d.document = document
+	d.nextRefresh = time.Now().Add(discoveryRetryInterval)
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *oidcDiscovery) Document(ctx context.Context) (*OIDCDiscoveryDocument, error) {
+	d.mu.Lock()
+	document, nextRefresh := d.document, d.nextRefresh
+	d.mu.Unlock()
+
+	// 取得済みであれば再取得を待たずに返す
+	if document != nil {
+		if !time.Now().Before(nextRefresh) {
+			d.refreshInBackground(ctx)
+		}
+		return document, nil
+	}
+
+	// 未取得の場合のみ、他のリクエストと同じ取得の完了を待つ
+	select {
+	case result := <-d.refreshGroup.DoChan(discoveryRefreshKey, d.refreshFunc(ctx)):
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*OIDCDiscoveryDocument), nil
+	case <-ctx.Done():
+		return nil, domain.NewAuthError(domain.AuthErrorTypeNetwork, "ディスカバリードキュメントの取得に失敗しました", ctx.Err())
+	}
+}
+
+// refreshInBackground - 実行中の再取得があればそれにまとめる（結果は refresh が保存する）
+func (d *oidcDiscovery) refreshInBackground(ctx context.Context) {
+	d.refreshGroup.DoChan(discoveryRefreshKey, d.refreshFunc(ctx))
+}
+
+// refreshFunc - 呼び出し元のリクエストが終わっても取得を続けるよう、キャンセルを引き継がない
+func (d *oidcDiscovery) refreshFunc(ctx context.Context) func() (interface{}, error) {
+	ctx = context.WithoutCancel(ctx)
+	return func() (interface{}, error) {
+		return d.refresh(ctx)
+	}
+}
+
+// refresh - ドキュメントを取得して検証し、保存する
+// 失敗した場合は前回のドキュメントを使い続け、discoveryRetryInterval 後に再試行する
+func (d *oidcDiscovery) refresh(ctx context.Context) (*OIDCDiscoveryDocument, error) {
+	document, err := d.fetch(ctx)
+	if err != nil {
+		d.mu.Lock()
+		if d.document != nil {
+			d.nextRefresh = time.Now().Add(discoveryRetryInterval)
+			d.logger.Warn("ディスカバリーの更新に失敗したため前回のドキュメントを使用します", map[string]interface{}{
+				"issuer": d.issuer,
+				"error":  err.Error(),
+			})
+		}
+		d.mu.Unlock()
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.document = document
+	d.nextRefresh = time.Now().Add(d.refreshInterval)
+	d.mu.Unlock()
+	d.writeCache(document)
+
+	d.logger.Info("ディスカバリードキュメントを更新しました", map[string]interface{}{
+		"issuer":   document.Issuer,
+		"jwks_uri": document.JWKSURI,
+	})
+
+	return document, nil
+}
+
+// fetch - 発行者の /.well-known/openid-configuration を取得して検証する
+func (d *oidcDiscovery) fetch(ctx context.Context) (*OIDCDiscoveryDocument, error) {
+	if d.issuer == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeConfig, "発行者が設定されていません", nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", d.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeRequest, "ディスカバリーリクエスト作成に失敗しました", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeNetwork, "ディスカバリードキュメントの取得に失敗しました", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, domain.NewAuthErrorWithCode(categorizeHTTPError(resp.StatusCode), resp.StatusCode, "ディスカバリードキュメントの取得に失敗しました")
+	}
+
+	var document OIDCDiscoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeParse, "ディスカバリードキュメントの解析に失敗しました", err)
+	}
+	if err := d.validate(&document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// validate - なりすましを防ぐため issuer は設定値と完全一致しなければならない
+func (d *oidcDiscovery) validate(document *OIDCDiscoveryDocument) error {
+	if strings.TrimSuffix(document.Issuer, "/") != d.issuer {
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, fmt.Sprintf("ディスカバリードキュメントのissuerが一致しません: %s", document.Issuer), nil)
+	}
+	if document.JWKSURI == "" {
+		return domain.NewAuthError(domain.AuthErrorTypeConfig, "ディスカバリードキュメントにjwks_uriがありません", nil)
+	}
+	return nil
+}
+
+func (d *oidcDiscovery) readCache() (*OIDCDiscoveryDocument, error) {
+	if d.cacheFile == "" {
+		return nil, fmt.Errorf("cache file is not configured")
+	}
+
+	data, err := os.ReadFile(d.cacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var document OIDCDiscoveryDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if err := d.validate(&document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// writeCache - 次回の起動時にIdPへ到達できない場合に備えて保存する（失敗しても処理は続ける）
+func (d *oidcDiscovery) writeCache(document *OIDCDiscoveryDocument) {
+	if d.cacheFile == "" {
+		return
+	}
+
+	data, err := json.Marshal(document)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(d.cacheFile), 0o755); err == nil {
+			err = os.WriteFile(d.cacheFile, data, 0o644)
+		}
+	}
+	if err != nil {
+		d.logger.Warn("ディスカバリードキュメントの保存に失敗しました", map[string]interface{}{
+			"file":  d.cacheFile,
+			"error": err.Error(),
+		})
+	}
+}
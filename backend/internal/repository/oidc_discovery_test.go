@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
+)
+
+func newTestDiscoveryServer(t *testing.T, issuer *string, fetches *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(fetches, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(OIDCDiscoveryDocument{
+			Issuer:                           *issuer,
+			AuthorizationEndpoint:            *issuer + "/authorize",
+			TokenEndpoint:                    *issuer + "/token",
+			JWKSURI:                          *issuer + "/jwks",
+			ScopesSupported:                  []string{"openid", "email"},
+			IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		})
+	}))
+	t.Cleanup(server.Close)
+	*issuer = server.URL
+	return server
+}
+
+// withoutRetry - 到達できないIdPへのリトライでテストが遅くならないようにする
+func withoutRetry(discovery IOIDCDiscovery) {
+	d := discovery.(*oidcDiscovery)
+	d.httpClient = httpclient.NewClient(httpclient.Config{Timeout: time.Second}, d.logger)
+}
+
+func TestOIDCDiscovery_LoadAndRefresh(t *testing.T) {
+	var issuer string
+	var fetches int32
+	newTestDiscoveryServer(t, &issuer, &fetches)
+
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer, RefreshInterval: time.Hour})
+	if err := discovery.Load(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	document, err := discovery.Document(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if document.TokenEndpoint != issuer+"/token" {
+		t.Errorf("unexpected token endpoint: %s", document.TokenEndpoint)
+	}
+	if got := document.SupportedScopes([]string{"openid", "email", "profile"}); len(got) != 2 {
+		t.Errorf("unexpected supported scopes: %v", got)
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("expected 1 fetch, got %d", got)
+	}
+
+	// 更新間隔を過ぎたらバックグラウンドで再取得する
+	d := discovery.(*oidcDiscovery)
+	d.mu.Lock()
+	d.nextRefresh = time.Now().Add(-time.Second)
+	d.mu.Unlock()
+	if _, err := discovery.Document(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForDiscoveryRefresh(t, d)
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Errorf("expected 2 fetches, got %d", got)
+	}
+}
+
+// waitForDiscoveryRefresh - バックグラウンドの再取得が終わり、次の更新時刻が先に進むまで待つ
+func waitForDiscoveryRefresh(t *testing.T, d *oidcDiscovery) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.Lock()
+		refreshed := time.Now().Before(d.nextRefresh)
+		d.mu.Unlock()
+		if refreshed {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("background refresh did not finish")
+}
+
+func TestOIDCDiscovery_ServesCachedDocumentDuringRefresh(t *testing.T) {
+	var issuer string
+	var fetches int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 2回目以降の取得は release が閉じられるまで応答しない
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(OIDCDiscoveryDocument{Issuer: issuer, JWKSURI: issuer + "/jwks"})
+	}))
+	t.Cleanup(server.Close)
+	// server.Close は応答中のリクエストを待つため、先に解放する
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	issuer = server.URL
+
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer, RefreshInterval: time.Hour})
+	if err := discovery.Load(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := discovery.(*oidcDiscovery)
+	d.mu.Lock()
+	d.nextRefresh = time.Now().Add(-time.Second)
+	d.mu.Unlock()
+
+	// 再取得が止まっている間も、同時のリクエストは待たずに前回のドキュメントを受け取る
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			document, err := discovery.Document(ctx)
+			if err == nil && document.JWKSURI != issuer+"/jwks" {
+				err = errors.New("unexpected document: " + document.JWKSURI)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// 再取得は1回にまとめられる
+	close(release)
+	waitForDiscoveryRefresh(t, d)
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Errorf("expected 2 fetches, got %d", got)
+	}
+}
+
+func TestOIDCDiscovery_DocumentBeforeLoad(t *testing.T) {
+	var issuer string
+	var fetches int32
+	server := newTestDiscoveryServer(t, &issuer, &fetches)
+
+	// 取得済みのドキュメントがない場合は取得を待つ
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer})
+	document, err := discovery.Document(context.Background())
+	if err != nil || document.TokenEndpoint != issuer+"/token" {
+		t.Fatalf("expected fetched document, got %v, %v", document, err)
+	}
+
+	// 取得できなければエラーを返す
+	server.Close()
+	unreachable := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer})
+	withoutRetry(unreachable)
+	if _, err := unreachable.Document(context.Background()); err == nil {
+		t.Fatal("expected error when discovery is unreachable and nothing is cached")
+	}
+}
+
+func TestOIDCDiscovery_RejectsIssuerMismatch(t *testing.T) {
+	var issuer string
+	var fetches int32
+	server := newTestDiscoveryServer(t, &issuer, &fetches)
+	issuer = "https://attacker.example.com"
+
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: server.URL})
+	assertSecurityError(t, discovery.Load(context.Background()))
+}
+
+func TestOIDCDiscovery_FallsBackToCacheFile(t *testing.T) {
+	var issuer string
+	var fetches int32
+	server := newTestDiscoveryServer(t, &issuer, &fetches)
+	cacheFile := filepath.Join(t.TempDir(), "discovery.json")
+
+	if err := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer, CacheFile: cacheFile}).Load(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.Close()
+
+	// IdPに到達できなくても保存済みのドキュメントで起動できる
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer, CacheFile: cacheFile})
+	withoutRetry(discovery)
+	if err := discovery.Load(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	document, err := discovery.Document(context.Background())
+	if err != nil || document.JWKSURI != issuer+"/jwks" {
+		t.Fatalf("expected cached document, got %v, %v", document, err)
+	}
+
+	// 保存済みのものもなければ起動できない
+	withoutCache := NewOIDCDiscovery(OIDCDiscoveryConfig{Issuer: issuer})
+	withoutRetry(withoutCache)
+	if err := withoutCache.Load(context.Background()); err == nil {
+		t.Fatal("expected error when discovery is unreachable and no cache exists")
+	}
+}
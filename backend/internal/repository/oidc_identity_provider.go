@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+// OIDCProviderConfig - 汎用OIDCプロバイダー（Keycloak・Auth0など）の設定
+// エンドポイントはすべて Issuer のディスカバリードキュメントから取得する
+type OIDCProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Audience - アクセストークンのaud（Auth0のAPI識別子など。空の場合は azp で確認する）
+	Audience string
+	// DiscoveryCacheFile / DiscoveryRefreshInterval - OIDCDiscoveryConfig を参照
+	DiscoveryCacheFile       string
+	DiscoveryRefreshInterval time.Duration
+
+	// Scopes - 要求するスコープ（scopes_supported にないものは送らない）
+	Scopes []string
+	// ProviderHintParam - 外部IdPを指定する認可パラメータ（Keycloak: kc_idp_hint、Auth0: connection）
+	ProviderHintParam string
+	// ProviderHints - APIのプロバイダー名とIdP側の外部IdP名の対応（google=google-oauth2 など）
+	ProviderHints map[string]string
+	// ProviderClaim - ログインに使われた外部IdP名を持つクレーム（Keycloakのマッパーで追加した identity_provider など）
+	ProviderClaim string
+	// GroupsClaim - ロールの元になるグループのクレーム（realm_access.roles のように . で入れ子を指定できる）
+	GroupsClaim string
+}
+
+type oidcIdentityProvider struct {
+	ITokenVerifier
+	client    *oauthClient
+	discovery IOIDCDiscovery
+	config    OIDCProviderConfig
+}
+
+func NewOIDCIdentityProvider(config OIDCProviderConfig) IIdentityProvider {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	discovery := NewOIDCDiscovery(OIDCDiscoveryConfig{
+		Issuer:          config.Issuer,
+		CacheFile:       config.DiscoveryCacheFile,
+		RefreshInterval: config.DiscoveryRefreshInterval,
+	})
+
+	return &oidcIdentityProvider{
+		ITokenVerifier: NewTokenVerifier(TokenVerifierConfig{
+			Issuer:    config.Issuer,
+			ClientID:  config.ClientID,
+			Discovery: discovery,
+			OIDC:      true,
+			Audience:  config.Audience,
+		}),
+		client:    newOAuthClient("OIDC", config.ClientID, config.ClientSecret),
+		discovery: discovery,
+		config:    config,
+	}
+}
+
+func (p *oidcIdentityProvider) Name() string {
+	return IdentityProviderOIDC
+}
+
+func (p *oidcIdentityProvider) Discover(ctx context.Context) error {
+	return p.discovery.Load(ctx)
+}
+
+func (p *oidcIdentityProvider) BuildAuthorizeURL(ctx context.Context, state *domain.OAuthState) (string, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return "", err
+	}
+	if document.AuthorizationEndpoint == "" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "IdPのディスカバリードキュメントにauthorization_endpointがありません", nil)
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", state.RedirectURI)
+	query.Set("scope", strings.Join(document.SupportedScopes(p.config.Scopes), " "))
+	query.Set("state", state.State)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", state.CodeChallenge)
+	query.Set("code_challenge_method", utils.PKCEMethodS256)
+	if p.config.Audience != "" {
+		query.Set("audience", p.config.Audience)
+	}
+
+	// IdP自身のログイン画面以外は、対応する外部IdPが設定されている場合のみ受け付ける
+	if state.Provider != directLoginProvider {
+		hint, ok := p.config.ProviderHints[state.Provider]
+		if !ok || p.config.ProviderHintParam == "" {
+			return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "このプロバイダーは設定されていません", nil)
+		}
+		query.Set(p.config.ProviderHintParam, hint)
+	}
+
+	return withQuery(document.AuthorizationEndpoint, query), nil
+}
+
+func (p *oidcIdentityProvider) ExchangeCode(ctx context.Context, authCode, codeVerifier, redirectURI string) (*domain.AuthTokens, error) {
+	tokenEndpoint, err := p.endpoint(ctx, "token_endpoint", func(d *OIDCDiscoveryDocument) string { return d.TokenEndpoint })
+	if err != nil {
+		return nil, err
+	}
+	return p.client.exchangeCode(ctx, tokenEndpoint, authCode, codeVerifier, redirectURI)
+}
+
+func (p *oidcIdentityProvider) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	tokenEndpoint, err := p.endpoint(ctx, "token_endpoint", func(d *OIDCDiscoveryDocument) string { return d.TokenEndpoint })
+	if err != nil {
+		return nil, err
+	}
+	return p.client.refreshTokens(ctx, tokenEndpoint, refreshToken)
+}
+
+// RevokeToken - 失効エンドポイントを持たないIdPではリフレッシュトークンの期限切れを待つ
+func (p *oidcIdentityProvider) RevokeToken(ctx context.Context, refreshToken string) error {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return err
+	}
+	if document.RevocationEndpoint == "" {
+		p.client.logger.Warn("IdPが失効エンドポイントを公開していないためトークンを失効させません", nil)
+		return nil
+	}
+	return p.client.revokeToken(ctx, document.RevocationEndpoint, refreshToken)
+}
+
+func (p *oidcIdentityProvider) GetUserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	userInfoEndpoint, err := p.endpoint(ctx, "userinfo_endpoint", func(d *OIDCDiscoveryDocument) string { return d.UserInfoEndpoint })
+	if err != nil {
+		return nil, err
+	}
+	return p.client.getUserInfo(ctx, userInfoEndpoint, accessToken)
+}
+
+// BuildLogoutURL - RP-Initiated Logout の end_session_endpoint のURLを構築する
+func (p *oidcIdentityProvider) BuildLogoutURL(ctx context.Context, logoutURI string) (string, error) {
+	endSessionEndpoint, err := p.endpoint(ctx, "end_session_endpoint", func(d *OIDCDiscoveryDocument) string { return d.EndSessionEndpoint })
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("client_id", p.config.ClientID)
+	query.Set("post_logout_redirect_uri", logoutURI)
+
+	return withQuery(endSessionEndpoint, query), nil
+}
+
+// endpoint - ディスカバリードキュメントのエンドポイント（公開されていない場合は設定エラー）
+func (p *oidcIdentityProvider) endpoint(ctx context.Context, name string, fromDocument func(*OIDCDiscoveryDocument) string) (string, error) {
+	document, err := p.discovery.Document(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint := fromDocument(document)
+	if endpoint == "" {
+		return "", domain.NewAuthError(domain.AuthErrorTypeConfig, fmt.Sprintf("IdPのディスカバリードキュメントに%sがありません", name), nil)
+	}
+	return endpoint, nil
+}
+
+// ProviderFromClaims - ProviderClaim の外部IdP名をAPIのプロバイダー名に戻す
+// クレームがない場合はIdP自身のアカウントでのログインとみなす
+func (p *oidcIdentityProvider) ProviderFromClaims(claims map[string]interface{}) string {
+	if p.config.ProviderClaim == "" {
+		return directLoginProvider
+	}
+
+	value, _ := domain.LookupClaim(claims, p.config.ProviderClaim).(string)
+	if value == "" {
+		return directLoginProvider
+	}
+	for provider, hint := range p.config.ProviderHints {
+		if hint == value {
+			return provider
+		}
+	}
+	return strings.ToLower(value)
+}
+
+func (p *oidcIdentityProvider) GroupsFromClaims(claims map[string]interface{}) []string {
+	values, _ := domain.LookupClaim(claims, p.config.GroupsClaim).([]interface{})
+	groups := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			groups = append(groups, strings.TrimPrefix(s, "/"))
+		}
+	}
+	return groups
+}
+
+// ParseProviderHints - "google=google-oauth2,github=github" 形式の対応表を解析する
+func ParseProviderHints(value string) (map[string]string, error) {
+	hints := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, hint, ok := strings.Cut(entry, "=")
+		provider, hint = strings.TrimSpace(provider), strings.TrimSpace(hint)
+		if !ok || provider == "" || hint == "" {
+			return nil, fmt.Errorf("invalid provider hint %q", entry)
+		}
+		hints[provider] = hint
+	}
+	return hints, nil
+}
+
+// withQuery - エンドポイントにクエリが含まれている場合も壊さずにパラメータを追加する
+func withQuery(endpoint string, query url.Values) string {
+	separator := "?"
+	if strings.Contains(endpoint, "?") {
+		separator = "&"
+	}
+	return endpoint + separator + query.Encode()
+}
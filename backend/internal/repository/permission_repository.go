@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+)
+
+type IPermissionRepository interface {
+	ListRoleNames(ctx context.Context, userID uint) ([]string, error)
+	ListPermissionNames(ctx context.Context, userID uint, roleNames []string) ([]string, error)
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) IPermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+// ListRoleNames - ユーザーに割り当てられたローカルのロール
+func (r *permissionRepository) ListRoleNames(ctx context.Context, userID uint) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).
+		Table("roles").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Pluck("roles.name", &names).Error
+	return names, err
+}
+
+// ListPermissionNames - ユーザーに割り当てられたロールと、roleNamesのロールが持つ権限
+func (r *permissionRepository) ListPermissionNames(ctx context.Context, userID uint, roleNames []string) ([]string, error) {
+	roles := r.db.Table("user_roles").Select("role_id").Where("user_id = ?", userID)
+	if len(roleNames) > 0 {
+		roles = r.db.Table("roles").Select("id").
+			Where("id IN (?) OR name IN ?", roles, roleNames)
+	}
+
+	var names []string
+	err := r.db.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id IN (?)", roles).
+		Order("permissions.name").
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+// SeedDefaultRoles - 管理者APIの権限（domain.AdminPermissions）を持つロールを作成する（既存の場合は不足分のみ追加）
+// 管理者グループと同名にすることで、管理者はローカルの割り当てなしで管理者APIを使える
+// 以前のバージョンが付与した全権限（*）は管理者ロールから取り消す
+func SeedDefaultRoles(db *gorm.DB, adminRole string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		role := domain.Role{Name: adminRole, Description: "Administrators"}
+		if err := tx.Where(domain.Role{Name: role.Name}).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+
+		for _, permission := range domain.AdminPermissions {
+			if err := tx.Where(domain.Permission{Name: permission.Name}).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+
+			err := tx.Exec(`
+				INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)
+				ON CONFLICT DO NOTHING
+			`, role.ID, permission.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`
+			DELETE FROM role_permissions
+			WHERE role_id = ? AND permission_id IN (SELECT id FROM permissions WHERE name = ?)
+		`, role.ID, "*").Error
+	})
+}
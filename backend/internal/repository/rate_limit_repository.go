@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/gorm"
+)
+
+type IRateLimitRepository interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type rateLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitRepository(db *gorm.DB) IRateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// Allow - keyのカウンターを1つ進め、ウィンドウ内の回数がlimit以下ならtrueを返す
+// 複数インスタンスから同時に呼ばれても1回のUPSERTで加算するため数え漏れがない
+// 制限を超えた場合は次のウィンドウまでの残り時間も返す
+func (r *rateLimitRepository) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now()
+	windowStartedBefore := now.Add(-window)
+
+	var counter domain.RateLimitCounter
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_counters (key, count, window_start, expires_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.window_start <= ? THEN 1 ELSE rate_limit_counters.count + 1 END,
+			window_start = CASE WHEN rate_limit_counters.window_start <= ? THEN EXCLUDED.window_start ELSE rate_limit_counters.window_start END,
+			expires_at = CASE WHEN rate_limit_counters.window_start <= ? THEN EXCLUDED.expires_at ELSE rate_limit_counters.expires_at END
+		RETURNING key, count, window_start, expires_at`,
+		key, now, now.Add(window),
+		windowStartedBefore, windowStartedBefore, windowStartedBefore,
+	).Scan(&counter).Error
+	if err != nil {
+		return false, 0, err
+	}
+
+	if counter.Count > limit {
+		return false, time.Until(counter.ExpiresAt), nil
+	}
+	return true, 0, nil
+}
+
+// DeleteExpired - ウィンドウが終了したカウンターを削除する
+func (r *rateLimitRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&domain.RateLimitCounter{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/httpclient"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	tokenUseID     = "id"
+	tokenUseAccess = "access"
+
+	// jwksRefreshKey - 同時に発生したJWKSの再取得を1回にまとめるためのキー
+	jwksRefreshKey = "jwks"
+)
+
+// ITokenVerifier - IdPが発行したJWTの署名とクレームを検証する
+type ITokenVerifier interface {
+	VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error)
+	VerifyAccessToken(ctx context.Context, token string) (map[string]interface{}, error)
+	VerifyToken(ctx context.Context, token string) (map[string]interface{}, error)
+}
+
+type TokenVerifierConfig struct {
+	// Issuer - https://cognito-idp.{region}.amazonaws.com/{userPoolId}
+	Issuer   string
+	ClientID string
+	// JWKSURL - 空の場合は Issuer + "/.well-known/jwks.json"（Discoveryがある場合は使用しない）
+	JWKSURL string
+	// Discovery - 指定した場合はJWKSのURLと署名アルゴリズムをディスカバリードキュメントから取得する
+	Discovery IOIDCDiscovery
+	// MinRefreshInterval - 未知のkidによるJWKS再取得の最小間隔
+	MinRefreshInterval time.Duration
+	// OIDC - token_useクレームを持たない汎用OIDCプロバイダーのトークンとして検証する
+	OIDC bool
+	// Audience - 汎用OIDCのアクセストークンのaud（空の場合は azp・client_id がクライアントIDと一致するものを受け付ける）
+	Audience string
+}
+
+type tokenVerifier struct {
+	httpClient         *httpclient.Client
+	logger             *logger.Logger
+	issuer             string
+	clientID           string
+	jwksURL            string
+	discovery          IOIDCDiscovery
+	minRefreshInterval time.Duration
+	oidc               bool
+	audience           string
+
+	// refreshGroup - JWKSの取得はここでまとめて行い、mu は鍵の読み書きの間だけ保持する
+	refreshGroup singleflight.Group
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+	// lastAttempt - 最後にJWKSの取得を始めた時刻（失敗した場合も更新し、IdPの障害中の再取得の連打を防ぐ）
+	lastAttempt time.Time
+}
+
+func NewTokenVerifier(config TokenVerifierConfig) ITokenVerifier {
+	httpConfig := httpclient.Config{
+		Timeout:     10 * time.Second,
+		MaxRetries:  2,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		JitterMax:   500 * time.Millisecond,
+	}
+
+	verifierLogger := logger.New("TOKEN_VERIFIER")
+
+	jwksURL := config.JWKSURL
+	if jwksURL == "" {
+		jwksURL = strings.TrimSuffix(config.Issuer, "/") + "/.well-known/jwks.json"
+	}
+
+	minRefreshInterval := config.MinRefreshInterval
+	if minRefreshInterval <= 0 {
+		minRefreshInterval = time.Minute
+	}
+
+	return &tokenVerifier{
+		httpClient:         httpclient.NewClient(httpConfig, verifierLogger),
+		logger:             verifierLogger,
+		issuer:             config.Issuer,
+		clientID:           config.ClientID,
+		jwksURL:            jwksURL,
+		discovery:          config.Discovery,
+		minRefreshInterval: minRefreshInterval,
+		oidc:               config.OIDC,
+		audience:           config.Audience,
+		keys:               make(map[string]*rsa.PublicKey),
+	}
+}
+
+// VerifyIDToken - IDトークンを検証する（aud = クライアントID）
+func (v *tokenVerifier) VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := v.parse(ctx, token, jwt.WithAudience(v.clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	if v.oidc {
+		return claims, nil
+	}
+
+	if use, _ := claims["token_use"].(string); use != tokenUseID {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "IDトークンではありません", nil)
+	}
+
+	return claims, nil
+}
+
+// VerifyAccessToken - アクセストークンを検証する（audを持たないためclient_idを確認）
+func (v *tokenVerifier) VerifyAccessToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := v.parse(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.oidc {
+		if !v.isOIDCAccessToken(claims) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンのaudが一致しません", nil)
+		}
+		return claims, nil
+	}
+
+	if use, _ := claims["token_use"].(string); use != tokenUseAccess {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "アクセストークンではありません", nil)
+	}
+
+	if clientID, _ := claims["client_id"].(string); clientID != v.clientID {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンのクライアントIDが一致しません", nil)
+	}
+
+	return claims, nil
+}
+
+// VerifyToken - token_useに応じてIDトークンまたはアクセストークンとして検証する
+func (v *tokenVerifier) VerifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := v.parse(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.oidc {
+		aud, _ := claims.GetAudience()
+		if !containsString(aud, v.clientID) && !v.isOIDCAccessToken(claims) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンのaudが一致しません", nil)
+		}
+		return claims, nil
+	}
+
+	switch use, _ := claims["token_use"].(string); use {
+	case tokenUseID:
+		aud, err := claims.GetAudience()
+		if err != nil || !containsString(aud, v.clientID) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンのaudが一致しません", err)
+		}
+	case tokenUseAccess:
+		if clientID, _ := claims["client_id"].(string); clientID != v.clientID {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンのクライアントIDが一致しません", nil)
+		}
+	default:
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "不明なtoken_useです", nil)
+	}
+
+	return claims, nil
+}
+
+// isOIDCAccessToken - 汎用OIDCのアクセストークンがこのクライアント向けかを確認する
+func (v *tokenVerifier) isOIDCAccessToken(claims jwt.MapClaims) bool {
+	if v.audience != "" {
+		aud, _ := claims.GetAudience()
+		return containsString(aud, v.audience)
+	}
+	azp, _ := claims["azp"].(string)
+	clientID, _ := claims["client_id"].(string)
+	return azp == v.clientID || clientID == v.clientID
+}
+
+func (v *tokenVerifier) parse(ctx context.Context, token string, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
+	if v.issuer == "" || v.clientID == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeConfig, "トークン検証の設定が不足しています", nil)
+	}
+
+	methods, err := v.signingMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts,
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("kid header is missing")
+		}
+		return v.getKey(ctx, kid)
+	}, opts...)
+	if err != nil {
+		// JWKS取得失敗などのドメインエラーはそのまま返す
+		var authErr *domain.AuthError
+		if errors.As(err, &authErr) {
+			return nil, authErr
+		}
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンの検証に失敗しました", err)
+	}
+
+	return claims, nil
+}
+
+// signingMethods - 受け付ける署名アルゴリズム
+// ディスカバリーの id_token_signing_alg_values_supported のうち、JWKSから読み込めるRSAのもののみ
+func (v *tokenVerifier) signingMethods(ctx context.Context) ([]string, error) {
+	if v.discovery == nil {
+		return []string{"RS256"}, nil
+	}
+
+	document, err := v.discovery.Document(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []string
+	for _, alg := range document.IDTokenSigningAlgValuesSupported {
+		if alg == "RS256" || alg == "RS384" || alg == "RS512" {
+			methods = append(methods, alg)
+		}
+	}
+	if len(methods) == 0 {
+		return []string{"RS256"}, nil
+	}
+	return methods, nil
+}
+
+// getKey - kidに対応する公開鍵を返す。未知のkidの場合はJWKSを再取得する
+func (v *tokenVerifier) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	v.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := v.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	v.mu.RLock()
+	key, ok = v.keys[kid]
+	v.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown kid: %s", kid)
+	}
+	return key, nil
+}
+
+// refreshKeys - 実行中の再取得があればその完了を待つ
+// 呼び出し元のリクエストが終わっても取得を続けるよう、キャンセルを引き継がない
+func (v *tokenVerifier) refreshKeys(ctx context.Context) error {
+	fetchCtx := context.WithoutCancel(ctx)
+	select {
+	case result := <-v.refreshGroup.DoChan(jwksRefreshKey, func() (interface{}, error) {
+		return nil, v.fetchKeys(fetchCtx)
+	}):
+		return result.Err
+	case <-ctx.Done():
+		return domain.NewAuthError(domain.AuthErrorTypeNetwork, "JWKSの取得に失敗しました", ctx.Err())
+	}
+}
+
+// fetchKeys - JWKSを取得して鍵を入れ替える
+// 任意のkidによる再取得の連打を防ぐため、前回の取得（失敗を含む）から minRefreshInterval の間は取得しない
+func (v *tokenVerifier) fetchKeys(ctx context.Context) error {
+	v.mu.Lock()
+	if !v.lastAttempt.IsZero() && time.Since(v.lastAttempt) < v.minRefreshInterval {
+		v.mu.Unlock()
+		return nil
+	}
+	v.lastAttempt = time.Now()
+	v.mu.Unlock()
+
+	jwksURL := v.jwksURL
+	if v.discovery != nil {
+		document, err := v.discovery.Document(ctx)
+		if err != nil {
+			return err
+		}
+		jwksURL = document.JWKSURI
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", jwksURL, nil)
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeRequest, "JWKSリクエスト作成に失敗しました", err)
+	}
+
+	resp, err := v.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeNetwork, "JWKSの取得に失敗しました", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.NewAuthErrorWithCode(domain.AuthErrorTypeServer, resp.StatusCode, "JWKSの取得に失敗しました")
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeParse, "JWKSの解析に失敗しました", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := parseRSAPublicKey(k.N, k.E)
+		if err != nil {
+			v.logger.Error("JWKの解析に失敗しました", map[string]interface{}{
+				"kid":   k.Kid,
+				"error": err.Error(),
+			})
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	v.logger.Info("JWKSを更新しました", map[string]interface{}{
+		"url":       jwksURL,
+		"key_count": len(keys),
+	})
+
+	return nil
+}
+
+func parseRSAPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("exponent is too large")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
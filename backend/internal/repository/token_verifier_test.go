@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	testIssuer   = "https://cognito-idp.ap-northeast-1.amazonaws.com/ap-northeast-1_TEST"
+	testClientID = "test-client-id"
+)
+
+type testJWKSServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches int32
+	// hold - 設定した場合、応答の前に閉じられるまで待つ
+	hold chan struct{}
+}
+
+func newTestJWKSServer(t *testing.T) *testJWKSServer {
+	t.Helper()
+	s := &testJWKSServer{keys: make(map[string]*rsa.PrivateKey)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.fetches, 1)
+		s.mu.Lock()
+		hold := s.hold
+		s.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		type jwk struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}
+		var body struct {
+			Keys []jwk `json:"keys"`
+		}
+		for kid, key := range s.keys {
+			body.Keys = append(body.Keys, jwk{
+				Kid: kid,
+				Kty: "RSA",
+				Alg: "RS256",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testJWKSServer) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.mu.Unlock()
+	return key
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func validIDTokenClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":       "user-sub",
+		"email":     "user@example.com",
+		"iss":       testIssuer,
+		"aud":       testClientID,
+		"token_use": "id",
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestVerifier(server *testJWKSServer, refresh time.Duration) ITokenVerifier {
+	return NewTokenVerifier(TokenVerifierConfig{
+		Issuer:             testIssuer,
+		ClientID:           testClientID,
+		JWKSURL:            server.URL,
+		MinRefreshInterval: refresh,
+	})
+}
+
+func assertSecurityError(t *testing.T, err error) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected *domain.AuthError, got %T: %v", err, err)
+	}
+	if authErr.Type != domain.AuthErrorTypeSecurity {
+		t.Fatalf("expected %s, got %s", domain.AuthErrorTypeSecurity, authErr.Type)
+	}
+}
+
+func TestVerifyIDToken_Valid(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	claims, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "kid-1", validIDTokenClaims()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims["email"] != "user@example.com" {
+		t.Errorf("unexpected email claim: %v", claims["email"])
+	}
+
+	// 2回目はキャッシュされた鍵を使う
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "kid-1", validIDTokenClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&server.fetches); got != 1 {
+		t.Errorf("expected 1 JWKS fetch, got %d", got)
+	}
+}
+
+func TestVerifyIDToken_RejectsInvalidClaims(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+	}{
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"access token", func(c jwt.MapClaims) { c["token_use"] = "access" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validIDTokenClaims()
+			tt.mutate(claims)
+			_, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "kid-1", claims))
+			assertSecurityError(t, err)
+		})
+	}
+}
+
+func TestVerifyIDToken_RejectsForgedSignature(t *testing.T) {
+	server := newTestJWKSServer(t)
+	server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	_, err = verifier.VerifyIDToken(context.Background(), signTestToken(t, forger, "kid-1", validIDTokenClaims()))
+	assertSecurityError(t, err)
+}
+
+func TestVerifyIDToken_RejectsUnsignedToken(t *testing.T) {
+	server := newTestJWKSServer(t)
+	server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, validIDTokenClaims())
+	token.Header["kid"] = "kid-1"
+	unsigned, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to build token: %v", err)
+	}
+
+	_, err = verifier.VerifyIDToken(context.Background(), unsigned)
+	assertSecurityError(t, err)
+}
+
+func TestVerifyIDToken_RefetchesOnUnknownKid(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key1 := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Nanosecond)
+
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key1, "kid-1", validIDTokenClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 鍵のローテーション
+	key2 := server.addKey(t, "kid-2")
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key2, "kid-2", validIDTokenClaims())); err != nil {
+		t.Fatalf("unexpected error after rotation: %v", err)
+	}
+	if got := atomic.LoadInt32(&server.fetches); got != 2 {
+		t.Errorf("expected 2 JWKS fetches, got %d", got)
+	}
+}
+
+func TestVerifyIDToken_ThrottlesRefetch(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Hour)
+
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "kid-1", validIDTokenClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "unknown-kid", validIDTokenClaims()))
+		assertSecurityError(t, err)
+	}
+	if got := atomic.LoadInt32(&server.fetches); got != 1 {
+		t.Errorf("expected 1 JWKS fetch, got %d", got)
+	}
+}
+
+func TestVerifyIDToken_KnownKidDuringSlowRefetch(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Nanosecond)
+
+	token := signTestToken(t, key, "kid-1", validIDTokenClaims())
+	if _, err := verifier.VerifyIDToken(context.Background(), token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 未知のkidによる再取得を止めておく
+	hold := make(chan struct{})
+	server.mu.Lock()
+	server.hold = hold
+	server.mu.Unlock()
+	t.Cleanup(func() {
+		select {
+		case <-hold:
+		default:
+			close(hold)
+		}
+	})
+
+	unknown := make(chan error, 1)
+	go func() {
+		_, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "unknown-kid", validIDTokenClaims()))
+		unknown <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&server.fetches) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("refetch did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 再取得中も既知のkidのトークンは待たずに検証できる
+	done := make(chan error, 1)
+	go func() {
+		_, err := verifier.VerifyIDToken(context.Background(), token)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("verification of a known kid was blocked by the refetch")
+	}
+
+	close(hold)
+	assertSecurityError(t, <-unknown)
+}
+
+func TestVerifyIDToken_ThrottlesFailedFetch(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier := NewTokenVerifier(TokenVerifierConfig{
+		Issuer:             testIssuer,
+		ClientID:           testClientID,
+		JWKSURL:            server.URL,
+		MinRefreshInterval: time.Hour,
+	})
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	// IdPの障害中、ランダムなkidのトークンごとに再取得しない
+	for i := 0; i < 3; i++ {
+		_, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, fmt.Sprintf("random-kid-%d", i), validIDTokenClaims()))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("expected 1 JWKS fetch, got %d", got)
+	}
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	now := time.Now()
+	accessClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":       "user-sub",
+			"iss":       testIssuer,
+			"client_id": testClientID,
+			"token_use": "access",
+			"iat":       now.Unix(),
+			"exp":       now.Add(time.Hour).Unix(),
+		}
+	}
+
+	if _, err := verifier.VerifyAccessToken(context.Background(), signTestToken(t, key, "kid-1", accessClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrongClient := accessClaims()
+	wrongClient["client_id"] = "other-client"
+	_, err := verifier.VerifyAccessToken(context.Background(), signTestToken(t, key, "kid-1", wrongClient))
+	assertSecurityError(t, err)
+
+	_, err = verifier.VerifyAccessToken(context.Background(), signTestToken(t, key, "kid-1", validIDTokenClaims()))
+	assertSecurityError(t, err)
+}
+
+func TestVerifyToken_AcceptsBothTokenUses(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := newTestVerifier(server, time.Minute)
+
+	if _, err := verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", validIDTokenClaims())); err != nil {
+		t.Fatalf("id token: unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	access := jwt.MapClaims{
+		"sub":       "user-sub",
+		"iss":       testIssuer,
+		"client_id": testClientID,
+		"token_use": "access",
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+	}
+	if _, err := verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", access)); err != nil {
+		t.Fatalf("access token: unexpected error: %v", err)
+	}
+
+	wrongAud := validIDTokenClaims()
+	wrongAud["aud"] = "other-client"
+	_, err := verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", wrongAud))
+	assertSecurityError(t, err)
+
+	unknownUse := validIDTokenClaims()
+	unknownUse["token_use"] = "refresh"
+	_, err = verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", unknownUse))
+	assertSecurityError(t, err)
+}
+
+func TestVerifyToken_OIDC(t *testing.T) {
+	server := newTestJWKSServer(t)
+	key := server.addKey(t, "kid-1")
+	verifier := NewTokenVerifier(TokenVerifierConfig{
+		Issuer:   testIssuer,
+		ClientID: testClientID,
+		JWKSURL:  server.URL,
+		OIDC:     true,
+	})
+
+	// 汎用OIDCのIDトークンは token_use を持たない
+	idToken := validIDTokenClaims()
+	delete(idToken, "token_use")
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestToken(t, key, "kid-1", idToken)); err != nil {
+		t.Fatalf("id token: unexpected error: %v", err)
+	}
+
+	// Keycloakのアクセストークンは aud が別のクライアントで azp がこのクライアント
+	now := time.Now()
+	access := jwt.MapClaims{
+		"sub": "user-sub",
+		"iss": testIssuer,
+		"aud": "account",
+		"azp": testClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if _, err := verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", access)); err != nil {
+		t.Fatalf("access token: unexpected error: %v", err)
+	}
+
+	access["azp"] = "other-client"
+	_, err := verifier.VerifyAccessToken(context.Background(), signTestToken(t, key, "kid-1", access))
+	assertSecurityError(t, err)
+	_, err = verifier.VerifyToken(context.Background(), signTestToken(t, key, "kid-1", access))
+	assertSecurityError(t, err)
+}
@@ -4,16 +4,32 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 	"gorm.io/gorm"
 )
 
+const (
+	// maxUpsertAttempts - 同時ログインやusernameの重複時に再試行する回数
+	maxUpsertAttempts = 5
+
+	pgUniqueViolation = "23505"
+
+	constraintUsersEmail    = "idx_users_email"
+	constraintUsersUsername = "idx_users_username"
+)
+
 type IUserRepository interface {
-	createUser(ctx context.Context, user *domain.User) error
-	getUserByEmail(ctx context.Context, email string) (*domain.User, error)
-	getUserByID(ctx context.Context, id uint) (*domain.User, error) 
+	CreateUser(ctx context.Context, user *domain.User) error
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByID(ctx context.Context, id uint) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
-	getUserByProviderAndSubjectID(ctx context.Context, provider, subjectID string) (*domain.User, error)
+	GetUserByProviderAndSubjectID(ctx context.Context, provider, subjectID string) (*domain.User, error)
+	GetUserBySubjectID(ctx context.Context, subjectID string) (*domain.User, error)
+	GetUsersBySubjectIDs(ctx context.Context, subjectIDs []string) (map[string]*domain.User, error)
+	UpsertByIdentity(ctx context.Context, user *domain.User) (*domain.User, error)
 }
 
 type userRepository struct {
@@ -24,11 +40,11 @@ func NewUserRepository(db *gorm.DB) IUserRepository {
 	return &userRepository{db:db}
 }
 
-func (r *userRepository) createUser(ctx context.Context, user *domain.User) error {
+func (r *userRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *userRepository) getUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
@@ -40,7 +56,7 @@ func (r *userRepository) getUserByEmail(ctx context.Context, email string) (*dom
 	return &user, nil
 }
 
-func (r *userRepository) getUserByID(ctx context.Context, id uint) (*domain.User, error) {
+func (r *userRepository) GetUserByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
 	if err != nil {
@@ -52,9 +68,41 @@ func (r *userRepository) getUserByID(ctx context.Context, id uint) (*domain.User
 	return &user, nil
 }
 
-func (r *userRepository) getUserByProviderAndSubjectID(ctx context.Context, provider, subjectID string) (*domain.User, error) {
+func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByProviderAndSubjectID - 紐付いたIDからユーザーを検索する
+func (r *userRepository) GetUserByProviderAndSubjectID(ctx context.Context, provider, subjectID string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject_id = ?", provider, subjectID).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserBySubjectID - Cognitoのsubはユーザープール内で一意のため、プロバイダーなしでも検索できる
+func (r *userRepository) GetUserBySubjectID(ctx context.Context, subjectID string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("provider = ? AND subject_id = ?", provider, subjectID).First(&user).Error
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.subject_id = ?", subjectID).
+		First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -64,6 +112,193 @@ func (r *userRepository) getUserByProviderAndSubjectID(ctx context.Context, prov
 	return &user, nil
 }
 
+// GetUsersBySubjectIDs - subごとのユーザー（削除待ちのユーザーも含む）。見つからないsubは含まない
+func (r *userRepository) GetUsersBySubjectIDs(ctx context.Context, subjectIDs []string) (map[string]*domain.User, error) {
+	users := make(map[string]*domain.User, len(subjectIDs))
+	if len(subjectIDs) == 0 {
+		return users, nil
+	}
+
+	var identities []domain.UserIdentity
+	if err := r.db.WithContext(ctx).Where("subject_id IN ?", subjectIDs).Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return users, nil
+	}
+
+	userIDs := make([]uint, 0, len(identities))
+	for _, identity := range identities {
+		userIDs = append(userIDs, identity.UserID)
+	}
+
+	var found []domain.User
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", userIDs).Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*domain.User, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+	for _, identity := range identities {
+		if user, ok := byID[identity.UserID]; ok {
+			users[identity.SubjectID] = user
+		}
+	}
+	return users, nil
+}
+
 func (r *userRepository) UpdateUser(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
-}
\ No newline at end of file
+	err := r.db.WithContext(ctx).Save(user).Error
+	return translateUserConstraintError(err)
+}
+
+// UpsertByIdentity - user.Provider / user.SubjectID のIDに紐付くユーザーを作成または更新する
+// user.EmailVerified がtrueの場合、同じメールアドレスの確認済みの既存ユーザーにIDを紐付ける
+// 同時ログインで作成が競合した場合は既存行の更新にフォールバックし、
+// usernameが他ユーザーと重複した場合はサフィックスを付けて再試行する
+func (r *userRepository) UpsertByIdentity(ctx context.Context, user *domain.User) (*domain.User, error) {
+	baseUsername := user.Username
+
+	for attempt := 0; attempt < maxUpsertAttempts; attempt++ {
+		existing, err := r.GetUserByProviderAndSubjectID(ctx, user.Provider, user.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing != nil {
+			if err := r.refreshProfile(ctx, existing, user); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}
+
+		if user.EmailVerified {
+			linked, err := r.linkIdentityByEmail(ctx, user)
+			if err != nil {
+				if errors.Is(err, domain.ErrIdentityAlreadyLinked) {
+					// 同じIDの同時ログイン。次のループで既存行を更新する
+					continue
+				}
+				return nil, err
+			}
+			if linked != nil {
+				return linked, nil
+			}
+		}
+
+		candidate := *user
+		candidate.Username = baseUsername
+		if attempt > 0 {
+			suffix, err := utils.GenerateRandomString(3)
+			if err != nil {
+				return nil, err
+			}
+			candidate.Username = baseUsername + "_" + suffix
+		}
+
+		err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&candidate).Error; err != nil {
+				return err
+			}
+			return tx.Create(&domain.UserIdentity{
+				UserID:    candidate.ID,
+				Provider:  candidate.Provider,
+				SubjectID: candidate.SubjectID,
+				Email:     candidate.Email,
+			}).Error
+		})
+		if err == nil {
+			return &candidate, nil
+		}
+
+		switch uniqueViolationConstraint(err) {
+		case "":
+			return nil, err
+		case constraintUsersEmail:
+			// 確認済みメールなら次のループで紐付け、未確認なら別アカウントとの衝突
+			if !user.EmailVerified {
+				return nil, domain.ErrUserEmailConflict
+			}
+		}
+		// IDの競合は次のループで既存行を更新、usernameの競合はサフィックスを付けて再試行
+	}
+
+	return nil, domain.ErrUsernameConflict
+}
+
+// refreshProfile - IdP側のプロフィールを反映する（usernameはユーザーが変更できるため上書きしない）
+// アカウント作成時のID以外からのログインでは、空の項目のみ補完する
+func (r *userRepository) refreshProfile(ctx context.Context, existing, incoming *domain.User) error {
+	if existing.Provider == incoming.Provider && existing.SubjectID == incoming.SubjectID {
+		existing.Email = incoming.Email
+		existing.EmailVerified = incoming.EmailVerified
+		existing.Name = incoming.Name
+		existing.Picture = incoming.Picture
+	} else {
+		if existing.Name == "" {
+			existing.Name = incoming.Name
+		}
+		if existing.Picture == "" {
+			existing.Picture = incoming.Picture
+		}
+	}
+
+	if err := r.UpdateUser(ctx, existing); err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&domain.UserIdentity{}).
+		Where("provider = ? AND subject_id = ?", incoming.Provider, incoming.SubjectID).
+		Update("email", incoming.Email).Error
+}
+
+// linkIdentityByEmail - 同じメールアドレスのユーザーが存在すればIDを紐付けて返す
+// 既存ユーザーのメールアドレスが未確認の場合は紐付けず、別アカウントとの衝突として扱う
+func (r *userRepository) linkIdentityByEmail(ctx context.Context, user *domain.User) (*domain.User, error) {
+	existing, err := r.GetUserByEmail(ctx, user.Email)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if !existing.CanLinkByEmail(user) {
+		return nil, domain.ErrUserEmailConflict
+	}
+
+	err = r.db.WithContext(ctx).Create(&domain.UserIdentity{
+		UserID:    existing.ID,
+		Provider:  user.Provider,
+		SubjectID: user.SubjectID,
+		Email:     user.Email,
+	}).Error
+	if err != nil {
+		if uniqueViolationConstraint(err) == constraintUserIdentitiesProviderSubject {
+			return nil, domain.ErrIdentityAlreadyLinked
+		}
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func uniqueViolationConstraint(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		if pgErr.ConstraintName == "" {
+			return "unknown"
+		}
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
+func translateUserConstraintError(err error) error {
+	switch uniqueViolationConstraint(err) {
+	case constraintUsersEmail:
+		return domain.ErrUserEmailConflict
+	case constraintUsersUsername:
+		return domain.ErrUsernameConflict
+	}
+	return err
+}
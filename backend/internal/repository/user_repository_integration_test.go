@@ -0,0 +1,232 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 一意制約の違反（23505）はPostgreSQLでしか再現できないため、実DBで確認する
+//
+//	TEST_DATABASE_DSN="host=localhost port=5445 user=... password=... dbname=... sslmode=disable" \
+//	  go test -tags integration ./internal/repository/
+//
+// 既存の行は削除せず、実行ごとに異なるメールアドレス・sub・usernameを使う
+
+func newIntegrationUserRepository(t *testing.T) (*userRepository, string) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&domain.User{}, &domain.UserIdentity{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return &userRepository{db: db}, fmt.Sprintf("it%d", time.Now().UnixNano())
+}
+
+// upsertConcurrently - 同じユーザーのログインを同時に処理する
+func upsertConcurrently(t *testing.T, repo *userRepository, n int, newUser func() *domain.User) []*domain.User {
+	t.Helper()
+
+	users := make([]*domain.User, n)
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			users[i], errs[i] = repo.UpsertByIdentity(context.Background(), newUser())
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("upsert %d: unexpected error: %v", i, err)
+		}
+	}
+	return users
+}
+
+func countIdentities(t *testing.T, repo *userRepository, provider, subjectID string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := repo.db.Model(&domain.UserIdentity{}).
+		Where("provider = ? AND subject_id = ?", provider, subjectID).
+		Count(&count).Error; err != nil {
+		t.Fatalf("failed to count identities: %v", err)
+	}
+	return count
+}
+
+func TestUpsertByIdentity_ConcurrentCreate(t *testing.T) {
+	repo, run := newIntegrationUserRepository(t)
+
+	// 初回ログインの同時リクエスト。作成に負けた側は既存行の更新にフォールバックする
+	users := upsertConcurrently(t, repo, 8, func() *domain.User {
+		return &domain.User{
+			Email:         run + "@example.com",
+			EmailVerified: true,
+			Username:      run,
+			Name:          "Alice",
+			Provider:      "google",
+			SubjectID:     run + "-sub",
+		}
+	})
+
+	for _, user := range users {
+		if user.ID != users[0].ID {
+			t.Fatalf("expected a single user, got ids %d and %d", users[0].ID, user.ID)
+		}
+	}
+	var count int64
+	if err := repo.db.Model(&domain.User{}).Where("email = ?", run+"@example.com").Count(&count).Error; err != nil {
+		t.Fatalf("failed to count users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("users = %d, want 1", count)
+	}
+	if got := countIdentities(t, repo, "google", run+"-sub"); got != 1 {
+		t.Errorf("identities = %d, want 1", got)
+	}
+}
+
+func TestUpsertByIdentity_ConcurrentLinkByEmail(t *testing.T) {
+	repo, run := newIntegrationUserRepository(t)
+	ctx := context.Background()
+
+	existing, err := repo.UpsertByIdentity(ctx, &domain.User{
+		Email:         run + "@example.com",
+		EmailVerified: true,
+		Username:      run,
+		Provider:      "google",
+		SubjectID:     run + "-google",
+	})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// 別のIdPから同時にログインした場合も、IDの紐付けは1件だけ作成する（ErrIdentityAlreadyLinked からの再試行）
+	users := upsertConcurrently(t, repo, 8, func() *domain.User {
+		return &domain.User{
+			Email:         run + "@example.com",
+			EmailVerified: true,
+			Username:      run + "-gh",
+			Provider:      "github",
+			SubjectID:     run + "-github",
+		}
+	})
+
+	for _, user := range users {
+		if user.ID != existing.ID {
+			t.Fatalf("expected the existing user %d, got %d", existing.ID, user.ID)
+		}
+	}
+	if got := countIdentities(t, repo, "github", run+"-github"); got != 1 {
+		t.Errorf("identities = %d, want 1", got)
+	}
+}
+
+func TestUpsertByIdentity_EmailConflict(t *testing.T) {
+	repo, run := newIntegrationUserRepository(t)
+	ctx := context.Background()
+
+	// 未確認のメールアドレスで先に作られたアカウント
+	if _, err := repo.UpsertByIdentity(ctx, &domain.User{
+		Email:     run + "@example.com",
+		Username:  run,
+		Provider:  "github",
+		SubjectID: run + "-github",
+	}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		emailVerified bool
+	}{
+		// 既存ユーザーが未確認のため紐付けない
+		{"確認済みのメールアドレス", true},
+		// 作成時の idx_users_email の違反
+		{"未確認のメールアドレス", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subjectID := fmt.Sprintf("%s-google-%t", run, tt.emailVerified)
+			_, err := repo.UpsertByIdentity(ctx, &domain.User{
+				Email:         run + "@example.com",
+				EmailVerified: tt.emailVerified,
+				Username:      run + "-google",
+				Provider:      "google",
+				SubjectID:     subjectID,
+			})
+			if !errors.Is(err, domain.ErrUserEmailConflict) {
+				t.Fatalf("expected ErrUserEmailConflict, got %v", err)
+			}
+			if got := countIdentities(t, repo, "google", subjectID); got != 0 {
+				t.Errorf("identities = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestUpsertByIdentity_UsernameCollision(t *testing.T) {
+	repo, run := newIntegrationUserRepository(t)
+	ctx := context.Background()
+
+	if _, err := repo.UpsertByIdentity(ctx, &domain.User{
+		Email:     run + "-1@example.com",
+		Username:  run,
+		Provider:  "google",
+		SubjectID: run + "-1",
+	}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// 別のユーザーが同じusernameで登録した場合は、サフィックスを付けて作成する
+	user, err := repo.UpsertByIdentity(ctx, &domain.User{
+		Email:     run + "-2@example.com",
+		Username:  run,
+		Provider:  "google",
+		SubjectID: run + "-2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(user.Username, run+"_") || len(user.Username) != len(run)+5 {
+		t.Errorf("username = %q, want %q with a 4-character suffix", user.Username, run)
+	}
+	if got := countIdentities(t, repo, "google", run+"-2"); got != 1 {
+		t.Errorf("identities = %d, want 1", got)
+	}
+}
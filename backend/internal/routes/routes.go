@@ -3,11 +3,22 @@ package routes
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/matthewyuh246/aws-cognito/internal/controller"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
 	"github.com/matthewyuh246/aws-cognito/pkg/middleware"
 )
 
 // SetupRoutes - APIルートを設定
-func SetupRoutes(e *echo.Echo, authController *controller.AuthController) {
+// cognitoAPIsがfalse（汎用OIDCのIdP）の場合、Cognitoのユーザープールを直接操作するルートは登録しない
+func SetupRoutes(
+	e *echo.Echo,
+	authController *controller.AuthController,
+	accountController *controller.AccountController,
+	adminController *controller.AdminController,
+	authMiddleware *middleware.AuthMiddleware,
+	permissionMiddleware *middleware.PermissionMiddleware,
+	adminRole string,
+	cognitoAPIs bool,
+) {
 	// CORS設定
 	corsConfig := middleware.NewCORSConifg()
 	middleware.SetupCommonMiddleware(e, corsConfig)
@@ -21,7 +32,91 @@ func SetupRoutes(e *echo.Echo, authController *controller.AuthController) {
 	// 認証関連のルート
 	auth := v1.Group("/auth")
 	{
+		// 認可リクエスト（state・nonce・PKCEの発行）
+		auth.GET("/authorize/:provider", authController.CreateAuthorizationRequest)
+
 		// ソーシャルログイン
 		auth.POST("/login", authController.LoginWithSocialProvider)
+
+		if cognitoAPIs {
+			// メールアドレス・パスワードでのサインアップとログイン
+			auth.POST("/signup", authController.SignUp)
+			auth.POST("/confirm", authController.ConfirmSignUp)
+			auth.POST("/resend-code", authController.ResendConfirmationCode)
+			auth.POST("/login/password", authController.LoginWithPassword)
+
+			// パスワードレスログイン（メールのワンタイムコード）
+			auth.POST("/passwordless/start", authController.StartPasswordlessLogin)
+			auth.POST("/passwordless/verify", authController.VerifyPasswordlessLogin)
+
+			// 認証チャレンジ（MFAなど）への応答
+			auth.POST("/challenge", authController.RespondToChallenge)
+
+			// パスワードの再設定
+			auth.POST("/password/forgot", authController.ForgotPassword)
+			auth.POST("/password/reset", authController.ResetPassword)
+		}
+
+		// トークン更新
+		auth.POST("/refresh", authController.RefreshTokens)
+
+		// ログアウト（グローバルサインアウト時はアクセストークンを利用）
+		auth.POST("/logout", authController.Logout, authMiddleware.OptionalAuth())
+
+		// 認証済みセッションの確認
+		auth.GET("/session", authController.GetSession, authMiddleware.RequireAuth())
+
+		if cognitoAPIs {
+			// IDプールの一時的なAWS認証情報（BearerトークンはIDトークン）
+			auth.POST("/aws-credentials", authController.GetAWSCredentials, authMiddleware.RequireAuth())
+		}
+	}
+
+	// ログイン中のユーザー自身のリソース
+	me := v1.Group("/me", authMiddleware.RequireAuth())
+	{
+		me.GET("", accountController.GetProfile)
+
+		// 実効ロールと権限（フロントエンドの表示制御用）
+		me.GET("/permissions", accountController.GetPermissions)
+
+		// ログイン方法（ID）の連携
+		me.GET("/identities", accountController.ListIdentities)
+		me.POST("/identities", accountController.LinkIdentity)
+		me.DELETE("/identities/:id", accountController.UnlinkIdentity)
+
+		if cognitoAPIs {
+			// プロフィールの変更（name・pictureの変更はアクセストークンが必要）
+			me.PATCH("", accountController.UpdateProfile)
+			me.POST("/attributes/verify", accountController.VerifyAttribute)
+
+			// アカウント削除（猶予期間内に再ログインすると取り消し可能）
+			me.DELETE("", accountController.DeleteAccount)
+
+			// 認証アプリ（TOTP）によるMFA（アクセストークンが必要）
+			me.POST("/mfa/totp", authController.AssociateTOTP)
+			me.POST("/mfa/totp/verify", authController.VerifyTOTP)
+			me.PUT("/mfa/preference", authController.SetMFAPreference)
+		}
+	}
+
+	if cognitoAPIs {
+		// 管理者向けAPI（操作はすべて監査ログに記録される）
+		admin := v1.Group("/admin", authMiddleware.RequireAuth(), permissionMiddleware.RequireRoles(adminRole))
+		{
+			canRead := permissionMiddleware.RequirePermission(domain.PermissionUsersRead)
+			canManage := permissionMiddleware.RequirePermission(domain.PermissionUsersManage)
+
+			admin.GET("/users", adminController.ListUsers, canRead)
+			admin.GET("/users/:username", adminController.GetUser, canRead)
+			admin.POST("/users/:username/disable", adminController.DisableUser, canManage)
+			admin.POST("/users/:username/enable", adminController.EnableUser, canManage)
+			admin.POST("/users/:username/reset-password", adminController.ResetUserPassword, canManage)
+			admin.POST("/users/:username/sign-out", adminController.GlobalSignOutUser, canManage)
+
+			// グループ（ロール）の管理
+			admin.POST("/users/:username/groups", adminController.AddUserToGroup, canManage)
+			admin.DELETE("/users/:username/groups/:group", adminController.RemoveUserFromGroup, canManage)
+		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	// reauthenticationMaxAge - アカウント削除は直近にログインしたセッションでのみ受け付ける
+	reauthenticationMaxAge = 10 * time.Minute
+	// purgeBatchSize - 物理削除ジョブが1回に処理する件数
+	purgeBatchSize = 100
+)
+
+// DeleteAccount - アカウントの削除を受け付ける
+// ユーザーを論理削除してからCognitoのセッションをすべて無効にし（失敗した場合は論理削除を取り消す）、
+// 猶予期間の経過後に PurgeDeletedAccounts がCognitoのユーザーと関連データを物理削除する
+// Cognitoのユーザーは猶予期間中も残すため、同じIDで再ログインすると削除を取り消せる
+func (u *accountUsecase) DeleteAccount(ctx context.Context, claims *domain.UserClaims, accessToken string) (*domain.AccountDeletion, error) {
+	if claims == nil || claims.AuthTime == 0 || time.Since(time.Unix(claims.AuthTime, 0)) > reauthenticationMaxAge {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "直近のログインが必要です", domain.ErrReauthenticationRequired)
+	}
+
+	// トークンの失効（グローバルサインアウトなど）をCognitoに問い合わせて確認する
+	out, err := u.cognitoClient.GetUserWithContext(ctx, &cognitoidentityprovider.GetUserInput{
+		AccessToken: aws.String(accessToken),
+	})
+	if err != nil {
+		return nil, categorizeCognitoError(err, "セッションの確認に失敗しました")
+	}
+	if cognitoSub(out.UserAttributes) != claims.Sub {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "セッションが一致しません", nil)
+	}
+
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	deletion, err := u.deletionRepo.ScheduleDeletion(ctx, user, time.Now().Add(u.deletionGracePeriod))
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "アカウントの削除に失敗しました", err)
+	}
+
+	_, err = u.cognitoClient.GlobalSignOutWithContext(ctx, &cognitoidentityprovider.GlobalSignOutInput{
+		AccessToken: aws.String(accessToken),
+	})
+	if err != nil {
+		if cancelErr := u.deletionRepo.CancelDeletion(ctx, user.ID); cancelErr != nil {
+			log.Printf("ERROR: Failed to cancel account deletion for user %d: %v", user.ID, cancelErr)
+		}
+		return nil, categorizeCognitoError(err, "アカウントの削除に失敗しました")
+	}
+
+	// 論理削除したユーザーのローカルのロールをキャッシュから使わせない
+	u.permissionUsecase.InvalidatePermissions(claims.Sub)
+
+	return deletion, nil
+}
+
+// PurgeDeletedAccounts - 猶予期間が終了したアカウントを物理削除する
+// 紐付いたCognitoユーザーをすべて削除してから（削除済みのものは無視）、ユーザーとIDの行を削除する
+func (u *accountUsecase) PurgeDeletedAccounts(ctx context.Context, now time.Time) (int64, error) {
+	deletions, err := u.deletionRepo.ListDueDeletions(ctx, now, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, deletion := range deletions {
+		if err := u.purgeAccount(ctx, deletion.UserID); err != nil {
+			log.Printf("ERROR: Failed to purge user %d: %v", deletion.UserID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (u *accountUsecase) purgeAccount(ctx context.Context, userID uint) error {
+	identities, err := u.deletionRepo.ListIdentitiesForPurge(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		if err := u.adminDeleteCognitoUser(ctx, identity.SubjectID); err != nil {
+			return fmt.Errorf("delete cognito user %s: %w", identity.SubjectID, err)
+		}
+	}
+
+	if err := u.deletionRepo.PurgeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		u.permissionUsecase.InvalidatePermissions(identity.SubjectID)
+	}
+	return nil
+}
+
+// adminDeleteCognitoUser - subでCognitoのユーザーを探して削除する（既に存在しない場合は何もしない）
+func (u *accountUsecase) adminDeleteCognitoUser(ctx context.Context, sub string) error {
+	out, err := u.cognitoClient.ListUsersWithContext(ctx, &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: aws.String(u.userPoolID),
+		Filter:     aws.String(fmt.Sprintf("sub = %q", sub)),
+		Limit:      aws.Int64(1),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, cognitoUser := range out.Users {
+		_, err := u.cognitoClient.AdminDeleteUserWithContext(ctx, &cognitoidentityprovider.AdminDeleteUserInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   cognitoUser.Username,
+		})
+		if err != nil && !isCognitoErrorCode(err, cognitoidentityprovider.ErrCodeUserNotFoundException) {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePendingDeletion - 削除待ちのアカウントに紐付いていたIDで再ログインした場合、削除を取り消す
+// メールアドレスでは照合しない（削除後に同じメールアドレスで登録した別人に、元のアカウントを引き継がせないため）
+func (u *authUsecase) restorePendingDeletion(ctx context.Context, provider string, userInfo map[string]interface{}) error {
+	deletion, err := u.deletionRepo.FindPendingDeletion(ctx, provider, getString(userInfo, "sub"))
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+	}
+	if deletion == nil {
+		return nil
+	}
+
+	if err := u.deletionRepo.CancelDeletion(ctx, deletion.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserEmailConflict) {
+			return domain.NewAuthError(domain.AuthErrorTypeConflict, "メールアドレスが別のアカウントで使用されているため、アカウントを復元できません", err)
+		}
+		return domain.NewAuthError(domain.AuthErrorTypeServer, "アカウント削除の取り消しに失敗しました", err)
+	}
+
+	log.Printf("INFO: Account deletion cancelled by sign-in: user=%d", deletion.UserID)
+	return nil
+}
+
+// cognitoSub - GetUserの属性からsubを取り出す
+func cognitoSub(attributes []*cognitoidentityprovider.AttributeType) string {
+	for _, attr := range attributes {
+		if aws.StringValue(attr.Name) == "sub" {
+			return aws.StringValue(attr.Value)
+		}
+	}
+	return ""
+}
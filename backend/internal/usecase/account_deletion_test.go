@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+type fakeAccountDeletionRepository struct {
+	repository.IAccountDeletionRepository
+	// pending - provider + ":" + subject のIDに紐付いた削除待ちのユーザー
+	pending   map[string]uint
+	cancelErr error
+	cancelled []uint
+	scheduled []uint
+	// identities - 物理削除するユーザーに残っているID
+	identities map[uint][]domain.UserIdentity
+	due        []domain.AccountDeletion
+	purged     []uint
+}
+
+func (r *fakeAccountDeletionRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]domain.AccountDeletion, error) {
+	return r.due, nil
+}
+
+func (r *fakeAccountDeletionRepository) ListIdentitiesForPurge(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	return r.identities[userID], nil
+}
+
+func (r *fakeAccountDeletionRepository) PurgeUser(ctx context.Context, userID uint) error {
+	r.purged = append(r.purged, userID)
+	return nil
+}
+
+// fakePermissionCache - 破棄した権限のキャッシュを記録する
+type fakePermissionCache struct {
+	IPermissionUsecase
+	invalidated []string
+}
+
+func (p *fakePermissionCache) InvalidatePermissions(sub string) {
+	p.invalidated = append(p.invalidated, sub)
+}
+
+func (r *fakeAccountDeletionRepository) ScheduleDeletion(ctx context.Context, user *domain.User, purgeAt time.Time) (*domain.AccountDeletion, error) {
+	r.scheduled = append(r.scheduled, user.ID)
+	return &domain.AccountDeletion{UserID: user.ID, Email: user.Email, PurgeAt: purgeAt}, nil
+}
+
+func (r *fakeAccountDeletionRepository) FindPendingDeletion(ctx context.Context, provider, subjectID string) (*domain.AccountDeletion, error) {
+	userID, ok := r.pending[provider+":"+subjectID]
+	if !ok {
+		return nil, nil
+	}
+	return &domain.AccountDeletion{UserID: userID, Email: "alice@example.com"}, nil
+}
+
+func (r *fakeAccountDeletionRepository) CancelDeletion(ctx context.Context, userID uint) error {
+	if r.cancelErr != nil {
+		return r.cancelErr
+	}
+	r.cancelled = append(r.cancelled, userID)
+	return nil
+}
+
+func TestRestorePendingDeletion(t *testing.T) {
+	newRepo := func() *fakeAccountDeletionRepository {
+		// 削除を申請したCognitoユーザー（cognito:alice-sub）と、連携済みのGoogleのID
+		return &fakeAccountDeletionRepository{pending: map[string]uint{
+			"cognito:alice-sub": 1,
+			"google:google-sub": 1,
+		}}
+	}
+
+	t.Run("linked identity restores the account", func(t *testing.T) {
+		repo := newRepo()
+		u := &authUsecase{deletionRepo: repo}
+
+		err := u.restorePendingDeletion(context.Background(), "google", map[string]interface{}{
+			"sub":   "google-sub",
+			"email": "alice@example.com",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.cancelled) != 1 || repo.cancelled[0] != 1 {
+			t.Errorf("deletion should be cancelled: %v", repo.cancelled)
+		}
+	})
+
+	t.Run("same identity restores the account", func(t *testing.T) {
+		// IDが1つだけのアカウントでも、Cognitoユーザーは物理削除まで残るため同じIDで取り消せる
+		repo := &fakeAccountDeletionRepository{pending: map[string]uint{"cognito:alice-sub": 1}}
+		u := &authUsecase{deletionRepo: repo}
+
+		err := u.restorePendingDeletion(context.Background(), "cognito", map[string]interface{}{"sub": "alice-sub"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.cancelled) != 1 || repo.cancelled[0] != 1 {
+			t.Errorf("deletion should be cancelled: %v", repo.cancelled)
+		}
+	})
+
+	t.Run("new account with the same verified email does not restore", func(t *testing.T) {
+		repo := newRepo()
+		u := &authUsecase{deletionRepo: repo}
+
+		err := u.restorePendingDeletion(context.Background(), "cognito", map[string]interface{}{
+			"sub":            "new-sub",
+			"email":          "alice@example.com",
+			"email_verified": true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.cancelled) != 0 {
+			t.Errorf("deletion must not be cancelled by email: %v", repo.cancelled)
+		}
+	})
+
+	t.Run("email taken during the grace period", func(t *testing.T) {
+		repo := newRepo()
+		repo.cancelErr = domain.ErrUserEmailConflict
+		u := &authUsecase{deletionRepo: repo}
+
+		err := u.restorePendingDeletion(context.Background(), "google", map[string]interface{}{"sub": "google-sub"})
+
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeConflict {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+		if !errors.Is(err, domain.ErrUserEmailConflict) {
+			t.Errorf("expected ErrUserEmailConflict, got %v", err)
+		}
+	})
+}
+
+// fakeDeletionCognitoClient - 削除を申請したユーザーのセッション確認とグローバルサインアウトを記録する
+// DeleteUser は実装しない（呼ばれた場合はpanicする）
+type fakeDeletionCognitoClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	sub           string
+	signOutErr    error
+	signedOutWith []string
+}
+
+func (c *fakeDeletionCognitoClient) GetUserWithContext(ctx aws.Context, input *cognitoidentityprovider.GetUserInput, opts ...request.Option) (*cognitoidentityprovider.GetUserOutput, error) {
+	return &cognitoidentityprovider.GetUserOutput{
+		UserAttributes: []*cognitoidentityprovider.AttributeType{{Name: aws.String("sub"), Value: aws.String(c.sub)}},
+	}, nil
+}
+
+func (c *fakeDeletionCognitoClient) GlobalSignOutWithContext(ctx aws.Context, input *cognitoidentityprovider.GlobalSignOutInput, opts ...request.Option) (*cognitoidentityprovider.GlobalSignOutOutput, error) {
+	c.signedOutWith = append(c.signedOutWith, aws.StringValue(input.AccessToken))
+	if c.signOutErr != nil {
+		return nil, c.signOutErr
+	}
+	return &cognitoidentityprovider.GlobalSignOutOutput{}, nil
+}
+
+func TestDeleteAccount(t *testing.T) {
+	claims := &domain.UserClaims{Sub: "sub-1", AuthTime: time.Now().Unix()}
+
+	t.Run("soft-deletes and signs out without deleting the Cognito user", func(t *testing.T) {
+		u, _, _ := newProfileTestUsecase(nil)
+		repo := &fakeAccountDeletionRepository{}
+		client := &fakeDeletionCognitoClient{sub: "sub-1"}
+		permissions := &fakePermissionCache{}
+		u.deletionRepo = repo
+		u.cognitoClient = client
+		u.permissionUsecase = permissions
+		u.deletionGracePeriod = 30 * 24 * time.Hour
+
+		deletion, err := u.DeleteAccount(context.Background(), claims, "access-token")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if deletion.UserID != 1 || len(repo.scheduled) != 1 {
+			t.Errorf("deletion should be scheduled for user 1: %+v, %v", deletion, repo.scheduled)
+		}
+		if len(client.signedOutWith) != 1 || client.signedOutWith[0] != "access-token" {
+			t.Errorf("expected a global sign-out, got %v", client.signedOutWith)
+		}
+		if len(repo.cancelled) != 0 {
+			t.Errorf("deletion must not be cancelled: %v", repo.cancelled)
+		}
+		if len(permissions.invalidated) != 1 || permissions.invalidated[0] != "sub-1" {
+			t.Errorf("cached permissions should be invalidated: %v", permissions.invalidated)
+		}
+	})
+
+	t.Run("sign-out failure cancels the soft delete", func(t *testing.T) {
+		u, _, _ := newProfileTestUsecase(nil)
+		repo := &fakeAccountDeletionRepository{}
+		u.deletionRepo = repo
+		u.cognitoClient = &fakeDeletionCognitoClient{sub: "sub-1", signOutErr: errors.New("cognito unavailable")}
+
+		if _, err := u.DeleteAccount(context.Background(), claims, "access-token"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if len(repo.cancelled) != 1 || repo.cancelled[0] != 1 {
+			t.Errorf("deletion should be cancelled: %v", repo.cancelled)
+		}
+	})
+
+	t.Run("stale session is rejected", func(t *testing.T) {
+		u, _, _ := newProfileTestUsecase(nil)
+		repo := &fakeAccountDeletionRepository{}
+		u.deletionRepo = repo
+
+		stale := &domain.UserClaims{Sub: "sub-1", AuthTime: time.Now().Add(-reauthenticationMaxAge - time.Minute).Unix()}
+		_, err := u.DeleteAccount(context.Background(), stale, "access-token")
+		if !errors.Is(err, domain.ErrReauthenticationRequired) {
+			t.Fatalf("expected ErrReauthenticationRequired, got %v", err)
+		}
+		if len(repo.scheduled) != 0 {
+			t.Errorf("deletion must not be scheduled: %v", repo.scheduled)
+		}
+	})
+}
+
+// fakePurgeCognitoClient - subで探したCognitoユーザーの削除を記録する
+type fakePurgeCognitoClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	deleted []string
+}
+
+func (c *fakePurgeCognitoClient) ListUsersWithContext(ctx aws.Context, input *cognitoidentityprovider.ListUsersInput, opts ...request.Option) (*cognitoidentityprovider.ListUsersOutput, error) {
+	return &cognitoidentityprovider.ListUsersOutput{
+		Users: []*cognitoidentityprovider.UserType{{Username: aws.String("user-" + aws.StringValue(input.Filter))}},
+	}, nil
+}
+
+func (c *fakePurgeCognitoClient) AdminDeleteUserWithContext(ctx aws.Context, input *cognitoidentityprovider.AdminDeleteUserInput, opts ...request.Option) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	c.deleted = append(c.deleted, aws.StringValue(input.Username))
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	repo := &fakeAccountDeletionRepository{
+		due: []domain.AccountDeletion{{UserID: 1}},
+		identities: map[uint][]domain.UserIdentity{
+			1: {{UserID: 1, Provider: "cognito", SubjectID: "alice-sub"}, {UserID: 1, Provider: "google", SubjectID: "google-sub"}},
+		},
+	}
+	client := &fakePurgeCognitoClient{}
+	permissions := &fakePermissionCache{}
+	u := &accountUsecase{deletionRepo: repo, cognitoClient: client, permissionUsecase: permissions}
+
+	purged, err := u.PurgeDeletedAccounts(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 1 || len(repo.purged) != 1 || repo.purged[0] != 1 {
+		t.Errorf("user 1 should be purged: %d, %v", purged, repo.purged)
+	}
+	// 猶予期間中に残したCognitoユーザーはここで削除する
+	if len(client.deleted) != 2 {
+		t.Errorf("Cognito users should be deleted: %v", client.deleted)
+	}
+	if want := []string{"alice-sub", "google-sub"}; !slices.Equal(permissions.invalidated, want) {
+		t.Errorf("invalidated = %v, want %v", permissions.invalidated, want)
+	}
+}
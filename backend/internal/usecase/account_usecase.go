@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+// linkTokenMaxAge - ID連携に使うIDトークンは直近のログインで取得したものに限る
+const linkTokenMaxAge = 10 * time.Minute
+
+type IAccountUsecase interface {
+	ListIdentities(ctx context.Context, claims *domain.UserClaims) ([]domain.UserIdentity, error)
+	LinkIdentity(ctx context.Context, claims *domain.UserClaims, idToken string) (*domain.UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, claims *domain.UserClaims, identityID uint) error
+	GetProfile(ctx context.Context, claims *domain.UserClaims) (*domain.User, error)
+	UpdateProfile(ctx context.Context, claims *domain.UserClaims, accessToken string, update domain.ProfileUpdate) (*domain.User, *domain.ProfileSyncResult, error)
+	VerifyAttribute(ctx context.Context, claims *domain.UserClaims, accessToken, attribute, value, code string) (*domain.AttributeVerification, error)
+	DeleteAccount(ctx context.Context, claims *domain.UserClaims, accessToken string) (*domain.AccountDeletion, error)
+	PurgeDeletedAccounts(ctx context.Context, now time.Time) (int64, error)
+}
+
+type accountUsecase struct {
+	userRepo            repository.IUserRepository
+	identityRepo        repository.IIdentityRepository
+	deletionRepo        repository.IAccountDeletionRepository
+	identityProvider    repository.IIdentityProvider
+	permissionUsecase   IPermissionUsecase
+	cognitoClient       cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	userPoolID          string
+	deletionGracePeriod time.Duration
+}
+
+func NewAccountUsecase(
+	userRepo repository.IUserRepository,
+	identityRepo repository.IIdentityRepository,
+	deletionRepo repository.IAccountDeletionRepository,
+	identityProvider repository.IIdentityProvider,
+	permissionUsecase IPermissionUsecase,
+	awsSession *session.Session,
+	userPoolID string,
+	deletionGracePeriod time.Duration,
+) *accountUsecase {
+	return &accountUsecase{
+		userRepo:            userRepo,
+		identityRepo:        identityRepo,
+		deletionRepo:        deletionRepo,
+		identityProvider:    identityProvider,
+		permissionUsecase:   permissionUsecase,
+		cognitoClient:       cognitoidentityprovider.New(awsSession),
+		userPoolID:          userPoolID,
+		deletionGracePeriod: deletionGracePeriod,
+	}
+}
+
+// ListIdentities - 現在のユーザーに紐付くIDの一覧
+func (u *accountUsecase) ListIdentities(ctx context.Context, claims *domain.UserClaims) ([]domain.UserIdentity, error) {
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := u.identityRepo.ListIdentitiesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "IDの取得に失敗しました", err)
+	}
+	return identities, nil
+}
+
+// LinkIdentity - 別のログイン方法で取得したIDトークンを検証し、そのIDを現在のユーザーに紐付ける
+// IDトークンの所持をもって連携先アカウントの所有者であることを確認する
+func (u *accountUsecase) LinkIdentity(ctx context.Context, claims *domain.UserClaims, idToken string) (*domain.UserIdentity, error) {
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	linkClaims, err := u.identityProvider.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRecentlyAuthenticated(linkClaims, linkTokenMaxAge) {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "連携するIDトークンが古すぎます。もう一度ログインしてください", nil)
+	}
+
+	sub := getString(linkClaims, "sub")
+	if sub == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "IDトークンにsubがありません", nil)
+	}
+	if sub == claims.Sub {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "現在ログイン中のIDは連携できません", nil)
+	}
+
+	identity := &domain.UserIdentity{
+		UserID:    user.ID,
+		Provider:  u.identityProvider.ProviderFromClaims(linkClaims),
+		SubjectID: sub,
+		Email:     getString(linkClaims, "email"),
+	}
+
+	if err := u.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		if errors.Is(err, domain.ErrIdentityAlreadyLinked) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeConflict, "IDは既に連携されています", err)
+		}
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "IDの連携に失敗しました", err)
+	}
+
+	return identity, nil
+}
+
+// UnlinkIdentity - IDの連携を解除する。最後のIDと、現在のセッションのIDは解除できない
+func (u *accountUsecase) UnlinkIdentity(ctx context.Context, claims *domain.UserClaims, identityID uint) error {
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return err
+	}
+
+	// アクセストークンはidentitiesクレームを持たないため、subのみで現在のIDを判定する
+	identities, err := u.identityRepo.ListIdentitiesByUserID(ctx, user.ID)
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeServer, "IDの取得に失敗しました", err)
+	}
+	for _, identity := range identities {
+		if identity.ID == identityID && identity.SubjectID == claims.Sub {
+			return domain.NewAuthError(domain.AuthErrorTypeValidation, "現在ログイン中のIDは解除できません", nil)
+		}
+	}
+
+	if err := u.identityRepo.DeleteIdentity(ctx, user.ID, identityID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrIdentityNotFound):
+			return domain.NewAuthError(domain.AuthErrorTypeNotFound, "IDが見つかりません", err)
+		case errors.Is(err, domain.ErrLastIdentity):
+			return domain.NewAuthError(domain.AuthErrorTypeConflict, "最後のIDは解除できません", err)
+		default:
+			return domain.NewAuthError(domain.AuthErrorTypeServer, "IDの解除に失敗しました", err)
+		}
+	}
+
+	return nil
+}
+
+// currentUser - 認証済みトークンのsubからローカルのユーザーを取得する
+func (u *accountUsecase) currentUser(ctx context.Context, claims *domain.UserClaims) (*domain.User, error) {
+	if claims == nil || claims.Sub == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "認証情報がありません", nil)
+	}
+
+	user, err := u.userRepo.GetUserBySubjectID(ctx, claims.Sub)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+	}
+	if user == nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeNotFound, "ユーザーが見つかりません", nil)
+	}
+	return user, nil
+}
+
+// isRecentlyAuthenticated - auth_time（なければiat）が maxAge 以内か
+func isRecentlyAuthenticated(claims map[string]interface{}, maxAge time.Duration) bool {
+	authTime, ok := claims["auth_time"].(float64)
+	if !ok {
+		authTime, ok = claims["iat"].(float64)
+	}
+	if !ok {
+		return false
+	}
+	return time.Since(time.Unix(int64(authTime), 0)) <= maxAge
+}
@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+const (
+	defaultAdminPageSize = 20
+	// maxAdminPageSize - CognitoのListUsersの上限
+	maxAdminPageSize = 60
+)
+
+type IAdminUsecase interface {
+	ListUsers(ctx context.Context, actor domain.AdminActor, query domain.AdminUserQuery) (*domain.AdminUserPage, error)
+	GetUser(ctx context.Context, actor domain.AdminActor, username string) (*domain.AdminUser, error)
+	DisableUser(ctx context.Context, actor domain.AdminActor, username string) error
+	EnableUser(ctx context.Context, actor domain.AdminActor, username string) error
+	ResetUserPassword(ctx context.Context, actor domain.AdminActor, username string) error
+	GlobalSignOutUser(ctx context.Context, actor domain.AdminActor, username string) error
+	AddUserToGroup(ctx context.Context, actor domain.AdminActor, username, group string) error
+	RemoveUserFromGroup(ctx context.Context, actor domain.AdminActor, username, group string) error
+}
+
+type adminUsecase struct {
+	userRepo      repository.IUserRepository
+	auditRepo     repository.IAdminAuditRepository
+	cognitoClient cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	userPoolID    string
+}
+
+func NewAdminUsecase(
+	userRepo repository.IUserRepository,
+	auditRepo repository.IAdminAuditRepository,
+	awsSession *session.Session,
+	userPoolID string,
+) *adminUsecase {
+	return &adminUsecase{
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+		cognitoClient: cognitoidentityprovider.New(awsSession),
+		userPoolID:    userPoolID,
+	}
+}
+
+// ListUsers - Cognitoのユーザー一覧にローカルのユーザー情報を合わせて返す
+func (u *adminUsecase) ListUsers(ctx context.Context, actor domain.AdminActor, query domain.AdminUserQuery) (*domain.AdminUserPage, error) {
+	filter, err := buildListUsersFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+
+	var page *domain.AdminUserPage
+	err = u.audit(ctx, actor, domain.AdminActionListUsers, "", query, func() error {
+		input := &cognitoidentityprovider.ListUsersInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Limit:      aws.Int64(int64(limit)),
+		}
+		if filter != "" {
+			input.Filter = aws.String(filter)
+		}
+		if query.PageToken != "" {
+			input.PaginationToken = aws.String(query.PageToken)
+		}
+
+		out, err := u.cognitoClient.ListUsersWithContext(ctx, input)
+		if err != nil {
+			return categorizeCognitoError(err, "ユーザー一覧の取得に失敗しました")
+		}
+
+		users := make([]domain.AdminUser, 0, len(out.Users))
+		subs := make([]string, 0, len(out.Users))
+		for _, cognitoUser := range out.Users {
+			user := newAdminUser(cognitoUser.Username, cognitoUser.Attributes, cognitoUser.UserStatus, cognitoUser.Enabled)
+			user.CreatedAt = aws.TimeValue(cognitoUser.UserCreateDate)
+			user.LastModifiedAt = aws.TimeValue(cognitoUser.UserLastModifiedDate)
+			users = append(users, user)
+			subs = append(subs, user.Sub)
+		}
+
+		locals, err := u.userRepo.GetUsersBySubjectIDs(ctx, subs)
+		if err != nil {
+			return domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+		}
+		for i := range users {
+			users[i].Local = locals[users[i].Sub]
+		}
+
+		page = &domain.AdminUserPage{
+			Users:         users,
+			NextPageToken: aws.StringValue(out.PaginationToken),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetUser - ユーザーの詳細（MFAの設定を含む）
+func (u *adminUsecase) GetUser(ctx context.Context, actor domain.AdminActor, username string) (*domain.AdminUser, error) {
+	var user domain.AdminUser
+	err := u.audit(ctx, actor, domain.AdminActionGetUser, username, nil, func() error {
+		out, err := u.cognitoClient.AdminGetUserWithContext(ctx, &cognitoidentityprovider.AdminGetUserInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "ユーザーの取得に失敗しました")
+		}
+
+		user = newAdminUser(out.Username, out.UserAttributes, out.UserStatus, out.Enabled)
+		user.CreatedAt = aws.TimeValue(out.UserCreateDate)
+		user.LastModifiedAt = aws.TimeValue(out.UserLastModifiedDate)
+		user.MFAMethods = aws.StringValueSlice(out.UserMFASettingList)
+
+		groups, err := u.cognitoClient.AdminListGroupsForUserWithContext(ctx, &cognitoidentityprovider.AdminListGroupsForUserInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "グループの取得に失敗しました")
+		}
+		for _, group := range groups.Groups {
+			user.Groups = append(user.Groups, aws.StringValue(group.GroupName))
+		}
+
+		locals, err := u.userRepo.GetUsersBySubjectIDs(ctx, []string{user.Sub})
+		if err != nil {
+			return domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+		}
+		user.Local = locals[user.Sub]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DisableUser - ユーザーを無効化する（ログインとトークンの更新ができなくなる）
+func (u *adminUsecase) DisableUser(ctx context.Context, actor domain.AdminActor, username string) error {
+	return u.audit(ctx, actor, domain.AdminActionDisableUser, username, nil, func() error {
+		_, err := u.cognitoClient.AdminDisableUserWithContext(ctx, &cognitoidentityprovider.AdminDisableUserInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "ユーザーの無効化に失敗しました")
+		}
+		return nil
+	})
+}
+
+// EnableUser - 無効化したユーザーを有効に戻す
+func (u *adminUsecase) EnableUser(ctx context.Context, actor domain.AdminActor, username string) error {
+	return u.audit(ctx, actor, domain.AdminActionEnableUser, username, nil, func() error {
+		_, err := u.cognitoClient.AdminEnableUserWithContext(ctx, &cognitoidentityprovider.AdminEnableUserInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "ユーザーの有効化に失敗しました")
+		}
+		return nil
+	})
+}
+
+// ResetUserPassword - 現在のパスワードを無効にし、確認済みのメールアドレスに再設定コードを送る
+func (u *adminUsecase) ResetUserPassword(ctx context.Context, actor domain.AdminActor, username string) error {
+	return u.audit(ctx, actor, domain.AdminActionResetPassword, username, nil, func() error {
+		_, err := u.cognitoClient.AdminResetUserPasswordWithContext(ctx, &cognitoidentityprovider.AdminResetUserPasswordInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "パスワードのリセットに失敗しました")
+		}
+		return nil
+	})
+}
+
+// GlobalSignOutUser - ユーザーのリフレッシュトークンをすべて無効化する
+// 発行済みのアクセストークン・IDトークンは有効期限まで使えることに注意
+func (u *adminUsecase) GlobalSignOutUser(ctx context.Context, actor domain.AdminActor, username string) error {
+	return u.audit(ctx, actor, domain.AdminActionGlobalSignOut, username, nil, func() error {
+		_, err := u.cognitoClient.AdminUserGlobalSignOutWithContext(ctx, &cognitoidentityprovider.AdminUserGlobalSignOutInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "サインアウトに失敗しました")
+		}
+		return nil
+	})
+}
+
+// AddUserToGroup - ユーザーをグループ（ロール）に追加する
+// トークンのcognito:groupsに反映されるのは次回のログインまたはトークン更新から
+func (u *adminUsecase) AddUserToGroup(ctx context.Context, actor domain.AdminActor, username, group string) error {
+	details := map[string]string{"group": group}
+	return u.audit(ctx, actor, domain.AdminActionAddToGroup, username, details, func() error {
+		_, err := u.cognitoClient.AdminAddUserToGroupWithContext(ctx, &cognitoidentityprovider.AdminAddUserToGroupInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+			GroupName:  aws.String(group),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "グループへの追加に失敗しました")
+		}
+		return nil
+	})
+}
+
+// RemoveUserFromGroup - ユーザーをグループ（ロール）から外す
+// 発行済みのトークンには有効期限までグループが残るため、即時に反映するにはサインアウトも行う
+func (u *adminUsecase) RemoveUserFromGroup(ctx context.Context, actor domain.AdminActor, username, group string) error {
+	details := map[string]string{"group": group}
+	return u.audit(ctx, actor, domain.AdminActionRemoveGroup, username, details, func() error {
+		_, err := u.cognitoClient.AdminRemoveUserFromGroupWithContext(ctx, &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+			UserPoolId: aws.String(u.userPoolID),
+			Username:   aws.String(username),
+			GroupName:  aws.String(group),
+		})
+		if err != nil {
+			return categorizeCognitoError(err, "グループからの削除に失敗しました")
+		}
+		return nil
+	})
+}
+
+// audit - 操作の前に監査ログを記録し、actionの結果で更新する
+// 監査ログを記録できない場合は操作を行わない
+func (u *adminUsecase) audit(ctx context.Context, actor domain.AdminActor, action, target string, details interface{}, fn func() error) error {
+	if actor.Sub == "" {
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, "管理者の認証情報がありません", nil)
+	}
+
+	entry := &domain.AdminAuditLog{
+		ActorSub:       actor.Sub,
+		ActorUsername:  actor.Username,
+		ActorEmail:     actor.Email,
+		ActorIP:        actor.IP,
+		Action:         action,
+		TargetUsername: target,
+	}
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return domain.NewAuthError(domain.AuthErrorTypeServer, "監査ログの作成に失敗しました", err)
+		}
+		entry.Details = string(encoded)
+	}
+
+	if err := u.auditRepo.Start(ctx, entry); err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeServer, "監査ログの記録に失敗しました", err)
+	}
+
+	actionErr := fn()
+
+	if err := u.auditRepo.Finish(ctx, entry, actionErr); err != nil {
+		log.Printf("ERROR: Failed to record admin audit result: id=%d action=%s: %v", entry.ID, action, err)
+	}
+	return actionErr
+}
+
+// buildListUsersFilter - ListUsersのFilter式を組み立てる（指定できる条件は1つ）
+// Filter式の文字列はエスケープできないため、引用符とバックスラッシュを含む値は受け付けない
+func buildListUsersFilter(query domain.AdminUserQuery) (string, error) {
+	if strings.ContainsAny(query.Email, `"\`) || strings.ContainsAny(query.Status, `"\`) {
+		return "", domain.NewAuthError(domain.AuthErrorTypeValidation, "絞り込み条件に使用できない文字が含まれています", nil)
+	}
+
+	var filters []string
+	if query.Email != "" {
+		filters = append(filters, fmt.Sprintf(`email ^= "%s"`, query.Email))
+	}
+	if query.Status != "" {
+		filters = append(filters, fmt.Sprintf(`cognito:user_status = "%s"`, query.Status))
+	}
+	if query.Enabled != nil {
+		status := "Disabled"
+		if *query.Enabled {
+			status = "Enabled"
+		}
+		filters = append(filters, fmt.Sprintf(`status = "%s"`, status))
+	}
+
+	if len(filters) > 1 {
+		return "", domain.NewAuthError(domain.AuthErrorTypeValidation, "絞り込み条件は1つまでです", nil)
+	}
+	return strings.Join(filters, ""), nil
+}
+
+// newAdminUser - Cognitoのユーザー属性を展開する
+func newAdminUser(username *string, attributes []*cognitoidentityprovider.AttributeType, status *string, enabled *bool) domain.AdminUser {
+	user := domain.AdminUser{
+		Username:   aws.StringValue(username),
+		Status:     aws.StringValue(status),
+		Enabled:    aws.BoolValue(enabled),
+		Attributes: make(map[string]string, len(attributes)),
+	}
+
+	for _, attr := range attributes {
+		name := aws.StringValue(attr.Name)
+		value := aws.StringValue(attr.Value)
+		switch name {
+		case "sub":
+			user.Sub = value
+		case "email":
+			user.Email = value
+		case "email_verified":
+			user.EmailVerified = strings.EqualFold(value, "true")
+		default:
+			user.Attributes[name] = value
+		}
+	}
+	return user
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+func TestBuildListUsersFilter(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name    string
+		query   domain.AdminUserQuery
+		want    string
+		wantErr bool
+	}{
+		{name: "no filter", query: domain.AdminUserQuery{}, want: ""},
+		{name: "email prefix", query: domain.AdminUserQuery{Email: "alice@"}, want: `email ^= "alice@"`},
+		{name: "status", query: domain.AdminUserQuery{Status: "UNCONFIRMED"}, want: `cognito:user_status = "UNCONFIRMED"`},
+		{name: "enabled", query: domain.AdminUserQuery{Enabled: &enabled}, want: `status = "Enabled"`},
+		{name: "disabled", query: domain.AdminUserQuery{Enabled: &disabled}, want: `status = "Disabled"`},
+		{name: "multiple conditions", query: domain.AdminUserQuery{Email: "alice@", Status: "CONFIRMED"}, wantErr: true},
+		// 引用符で式を閉じて別の条件を注入する
+		{name: "quote in email", query: domain.AdminUserQuery{Email: `a" or email ^= "`}, wantErr: true},
+		{name: "backslash in email", query: domain.AdminUserQuery{Email: `alice\`}, wantErr: true},
+		{name: "quote in status", query: domain.AdminUserQuery{Status: `CONFIRMED"`}, wantErr: true},
+		{name: "backslash in status", query: domain.AdminUserQuery{Status: `\"`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildListUsersFilter(tt.query)
+			if tt.wantErr {
+				var authErr *domain.AuthError
+				if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeValidation {
+					t.Fatalf("expected validation error, got filter=%q err=%v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("filter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeAdminAuditRepository struct {
+	repository.IAdminAuditRepository
+	startErr error
+	started  []domain.AdminAuditLog
+	finished []domain.AdminAuditLog
+}
+
+func (r *fakeAdminAuditRepository) Start(ctx context.Context, entry *domain.AdminAuditLog) error {
+	if r.startErr != nil {
+		return r.startErr
+	}
+	entry.ID = uint(len(r.started) + 1)
+	r.started = append(r.started, *entry)
+	return nil
+}
+
+func (r *fakeAdminAuditRepository) Finish(ctx context.Context, entry *domain.AdminAuditLog, actionErr error) error {
+	finished := *entry
+	finished.Succeeded = actionErr == nil
+	if actionErr != nil {
+		finished.Error = actionErr.Error()
+	}
+	r.finished = append(r.finished, finished)
+	return nil
+}
+
+type fakeAdminCognitoClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	addErr error
+	added  []string
+}
+
+func (c *fakeAdminCognitoClient) AdminAddUserToGroupWithContext(ctx aws.Context, input *cognitoidentityprovider.AdminAddUserToGroupInput, opts ...request.Option) (*cognitoidentityprovider.AdminAddUserToGroupOutput, error) {
+	if c.addErr != nil {
+		return nil, c.addErr
+	}
+	c.added = append(c.added, aws.StringValue(input.Username)+":"+aws.StringValue(input.GroupName))
+	return &cognitoidentityprovider.AdminAddUserToGroupOutput{}, nil
+}
+
+var testAdminActor = domain.AdminActor{Sub: "admin-sub", Username: "admin", Email: "admin@example.com", IP: "192.0.2.1"}
+
+func TestAudit_RecordsAction(t *testing.T) {
+	auditRepo := &fakeAdminAuditRepository{}
+	client := &fakeAdminCognitoClient{}
+	u := &adminUsecase{auditRepo: auditRepo, cognitoClient: client, userPoolID: "pool"}
+
+	if err := u.AddUserToGroup(context.Background(), testAdminActor, "alice", "admin"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(auditRepo.started) != 1 || len(auditRepo.finished) != 1 {
+		t.Fatalf("expected one audit record, got started=%d finished=%d", len(auditRepo.started), len(auditRepo.finished))
+	}
+	entry := auditRepo.finished[0]
+	if entry.ActorSub != "admin-sub" || entry.ActorIP != "192.0.2.1" || entry.Action != domain.AdminActionAddToGroup || entry.TargetUsername != "alice" {
+		t.Errorf("unexpected audit record: %+v", entry)
+	}
+	if entry.Details != `{"group":"admin"}` || !entry.Succeeded {
+		t.Errorf("unexpected audit result: details=%s succeeded=%v", entry.Details, entry.Succeeded)
+	}
+	if len(client.added) != 1 || client.added[0] != "alice:admin" {
+		t.Errorf("unexpected cognito calls: %v", client.added)
+	}
+}
+
+func TestAudit_RecordsFailure(t *testing.T) {
+	auditRepo := &fakeAdminAuditRepository{}
+	u := &adminUsecase{auditRepo: auditRepo, cognitoClient: &fakeAdminCognitoClient{addErr: errors.New("cognito unavailable")}}
+
+	if err := u.AddUserToGroup(context.Background(), testAdminActor, "alice", "admin"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(auditRepo.finished) != 1 || auditRepo.finished[0].Succeeded || auditRepo.finished[0].Error == "" {
+		t.Errorf("failure should be recorded: %+v", auditRepo.finished)
+	}
+}
+
+func TestAudit_RefusesWithoutRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    domain.AdminActor
+		startErr error
+		wantType domain.AuthErrorType
+	}{
+		{"missing actor", domain.AdminActor{}, nil, domain.AuthErrorTypeSecurity},
+		{"audit log unavailable", testAdminActor, errors.New("db down"), domain.AuthErrorTypeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeAdminCognitoClient{}
+			u := &adminUsecase{auditRepo: &fakeAdminAuditRepository{startErr: tt.startErr}, cognitoClient: client}
+
+			err := u.AddUserToGroup(context.Background(), tt.actor, "alice", "admin")
+
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) || authErr.Type != tt.wantType {
+				t.Fatalf("expected %s error, got %v", tt.wantType, err)
+			}
+			if len(client.added) != 0 {
+				t.Error("the action must not run without an audit record")
+			}
+		})
+	}
+}
+
+func TestListUsers_RejectsFilterBeforeAudit(t *testing.T) {
+	auditRepo := &fakeAdminAuditRepository{}
+	u := &adminUsecase{auditRepo: auditRepo}
+
+	if _, err := u.ListUsers(context.Background(), testAdminActor, domain.AdminUserQuery{Email: `"`}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(auditRepo.started) != 0 {
+		t.Error("invalid queries should not reach Cognito")
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+// challengeSessionTTL - Cognitoのセッションの有効期間（アプリクライアントの既定値は3分）
+const challengeSessionTTL = 3 * time.Minute
+
+// challengeResponseParameters - チャレンジごとにクライアントが必ず応答すべきキー
+var challengeResponseParameters = map[string][]string{
+	domain.ChallengeNewPasswordRequired: {"NEW_PASSWORD"},
+	domain.ChallengeSMSMFA:              {"SMS_MFA_CODE"},
+	domain.ChallengeSoftwareTokenMFA:    {"SOFTWARE_TOKEN_MFA_CODE"},
+	domain.ChallengeSelectMFAType:       {"ANSWER"},
+	domain.ChallengeCustomChallenge:     {"ANSWER"},
+}
+
+// hiddenChallengeParameters - クライアントに返さないチャレンジパラメータ
+var hiddenChallengeParameters = map[string]bool{
+	"USER_ID_FOR_SRP":    true,
+	"userAttributes":     true,
+	"requiredAttributes": true,
+	"MFAS_CAN_CHOOSE":    true,
+}
+
+// cognitoAuthStep - InitiateAuth・RespondToAuthChallengeの結果（トークンまたは次のチャレンジ）
+type cognitoAuthStep struct {
+	challengeName string
+	session       *string
+	parameters    map[string]string
+	result        *cognitoidentityprovider.AuthenticationResultType
+}
+
+// RespondToChallenge - チャレンジに応答する。さらにチャレンジが続く場合は次のチャレンジを返す
+// クライアントはトークンが発行されるまで、返されたチャレンジに対してこのメソッドを繰り返し呼ぶ
+func (u *authUsecase) RespondToChallenge(ctx context.Context, session string, responses map[string]string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error) {
+	challengeSession, err := u.openChallengeSession(session)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	challengeResponses, err := u.buildChallengeResponses(challengeSession, responses)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	out, err := u.cognitoClient.RespondToAuthChallengeWithContext(ctx, &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ChallengeName:      aws.String(challengeSession.Name),
+		ClientId:           aws.String(u.clientID),
+		Session:            aws.String(challengeSession.CognitoSession),
+		ChallengeResponses: challengeResponses,
+	})
+	if err != nil {
+		switch {
+		case isCognitoErrorCode(err, cognitoidentityprovider.ErrCodeNotAuthorizedException):
+			// セッションの期限切れ・再利用、CUSTOM_CHALLENGEの試行回数超過はNotAuthorizedExceptionになる
+			return nil, nil, nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "チャレンジへの応答が拒否されました", fmt.Errorf("%w: %w", domain.ErrChallengeSessionExpired, err))
+		case isCognitoErrorCode(err, cognitoidentityprovider.ErrCodeInvalidPasswordException):
+			return nil, nil, nil, u.passwordRejectedError(responses["NEW_PASSWORD"], err)
+		}
+		return nil, nil, nil, categorizeCognitoError(err, "チャレンジへの応答に失敗しました")
+	}
+
+	return u.finishAuthStep(ctx, &cognitoAuthStep{
+		challengeName: aws.StringValue(out.ChallengeName),
+		session:       out.Session,
+		parameters:    aws.StringValueMap(out.ChallengeParameters),
+		result:        out.AuthenticationResult,
+	}, challengeSession.Username)
+}
+
+// buildChallengeResponses - クライアントの応答を検証し、RespondToAuthChallengeのChallengeResponsesを組み立てる
+// セッションに記録したキー以外は受け付けない
+func (u *authUsecase) buildChallengeResponses(challengeSession *domain.ChallengeSession, responses map[string]string) (map[string]*string, error) {
+	challengeResponses := map[string]*string{
+		"USERNAME": aws.String(challengeSession.Username),
+	}
+
+	for _, key := range challengeSession.Parameters {
+		value := responses[key]
+		if value == "" {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, fmt.Sprintf("%s がありません", key), nil)
+		}
+		challengeResponses[key] = aws.String(value)
+	}
+
+	switch challengeSession.Name {
+	case domain.ChallengeNewPasswordRequired:
+		if err := u.passwordPolicy.Check(responses["NEW_PASSWORD"]); err != nil {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "パスワードがポリシーを満たしていません", err)
+		}
+	case domain.ChallengeSelectMFAType:
+		if !containsValue(challengeSession.Options, responses["ANSWER"]) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "選択できないMFAの種類です", nil)
+		}
+	}
+
+	return challengeResponses, nil
+}
+
+// finishAuthStep - トークンが発行されていればログインを完了し、チャレンジであればクライアントに返す
+func (u *authUsecase) finishAuthStep(ctx context.Context, step *cognitoAuthStep, username string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error) {
+	if step.challengeName == "" {
+		tokens, user, err := u.completePasswordLogin(ctx, step.result)
+		return tokens, user, nil, err
+	}
+
+	challenge, err := u.newChallenge(step, username)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return nil, nil, challenge, nil
+}
+
+// newChallenge - Cognitoのセッションとユーザー名を暗号化したチャレンジセッションを発行する
+func (u *authUsecase) newChallenge(step *cognitoAuthStep, username string) (*domain.AuthChallenge, error) {
+	parameters, ok := challengeResponseParameters[step.challengeName]
+	if !ok {
+		return nil, unsupportedChallengeError(aws.String(step.challengeName))
+	}
+	parameters = append([]string(nil), parameters...)
+
+	var options []string
+	switch step.challengeName {
+	case domain.ChallengeNewPasswordRequired:
+		// 未設定の必須属性は userAttributes.<属性名> として応答する
+		parameters = append(parameters, requiredAttributeParameters(step.parameters["requiredAttributes"])...)
+	case domain.ChallengeSelectMFAType:
+		options = decodeStringArray(step.parameters["MFAS_CAN_CHOOSE"])
+	}
+	sort.Strings(parameters)
+
+	// チャレンジの応答ではSRP用のユーザーID（内部のユーザー名）を使う
+	if userID := step.parameters["USER_ID_FOR_SRP"]; userID != "" {
+		username = userID
+	}
+
+	challengeSession := domain.ChallengeSession{
+		Name:           step.challengeName,
+		CognitoSession: aws.StringValue(step.session),
+		Username:       username,
+		Parameters:     parameters,
+		Options:        options,
+		ExpiresAt:      time.Now().Add(challengeSessionTTL),
+	}
+
+	payload, err := json.Marshal(challengeSession)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "チャレンジセッションの生成に失敗しました", err)
+	}
+
+	sealed, err := utils.Seal(u.jwtSecret, payload)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "チャレンジセッションの生成に失敗しました", err)
+	}
+
+	details := make(map[string]string)
+	for key, value := range step.parameters {
+		if !hiddenChallengeParameters[key] {
+			details[key] = value
+		}
+	}
+
+	return &domain.AuthChallenge{
+		Name:       step.challengeName,
+		Parameters: parameters,
+		Options:    options,
+		Details:    details,
+		Session:    sealed,
+		ExpiresAt:  challengeSession.ExpiresAt,
+	}, nil
+}
+
+// openChallengeSession - チャレンジセッションを復号し、有効期限を確認する
+func (u *authUsecase) openChallengeSession(session string) (*domain.ChallengeSession, error) {
+	payload, err := utils.Open(u.jwtSecret, session)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "チャレンジセッションが不正です", domain.ErrChallengeSessionInvalid)
+	}
+
+	var challengeSession domain.ChallengeSession
+	if err := json.Unmarshal(payload, &challengeSession); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "チャレンジセッションが不正です", domain.ErrChallengeSessionInvalid)
+	}
+
+	if challengeSession.IsExpired(time.Now()) {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "チャレンジセッションの有効期限が切れています", domain.ErrChallengeSessionExpired)
+	}
+
+	if _, ok := challengeResponseParameters[challengeSession.Name]; !ok {
+		return nil, unsupportedChallengeError(aws.String(challengeSession.Name))
+	}
+
+	return &challengeSession, nil
+}
+
+// requiredAttributeParameters - requiredAttributes（JSON配列）を応答のキーに変換する
+func requiredAttributeParameters(requiredAttributes string) []string {
+	var parameters []string
+	for _, attribute := range decodeStringArray(requiredAttributes) {
+		if !strings.HasPrefix(attribute, "userAttributes.") {
+			attribute = "userAttributes." + attribute
+		}
+		parameters = append(parameters, attribute)
+	}
+	return parameters
+}
+
+// decodeStringArray - チャレンジパラメータに含まれるJSON配列の文字列を取り出す
+func decodeStringArray(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
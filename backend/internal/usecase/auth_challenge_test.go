@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const testJWTSecret = "test-secret-test-secret-test-secret"
+
+func sealChallengeSession(t *testing.T, secret string, session domain.ChallengeSession) string {
+	t.Helper()
+	payload, err := json.Marshal(session)
+	if err != nil {
+		t.Fatalf("failed to marshal session: %v", err)
+	}
+	sealed, err := utils.Seal(secret, payload)
+	if err != nil {
+		t.Fatalf("failed to seal session: %v", err)
+	}
+	return sealed
+}
+
+func TestOpenChallengeSession(t *testing.T) {
+	u := &authUsecase{jwtSecret: testJWTSecret}
+
+	valid := domain.ChallengeSession{
+		Name:           domain.ChallengeSoftwareTokenMFA,
+		CognitoSession: "cognito-session",
+		Username:       "user-1",
+		Parameters:     []string{"SOFTWARE_TOKEN_MFA_CODE"},
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}
+	sealed := sealChallengeSession(t, testJWTSecret, valid)
+
+	got, err := u.openChallengeSession(sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.CognitoSession != "cognito-session" || got.Username != "user-1" {
+		t.Errorf("unexpected session: %+v", got)
+	}
+
+	expired := valid
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+
+	// 途中の1文字を変えて暗号文を改ざんする
+	tampered := []byte(sealed)
+	tampered[len(tampered)/2] ^= 0x01
+
+	tests := []struct {
+		name    string
+		session string
+		wantErr error
+	}{
+		{"tampered", string(tampered), domain.ErrChallengeSessionInvalid},
+		{"sealed with another key", sealChallengeSession(t, "another-secret", valid), domain.ErrChallengeSessionInvalid},
+		{"not sealed", "cognito-session", domain.ErrChallengeSessionInvalid},
+		{"expired", sealChallengeSession(t, testJWTSecret, expired), domain.ErrChallengeSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.openChallengeSession(tt.session)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestChallengeResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		challenge   string
+		parameters  map[string]string
+		responses   map[string]string
+		wantKeys    []string
+		wantDetails map[string]string
+		wantOptions []string
+	}{
+		{
+			name:      "new password required",
+			challenge: domain.ChallengeNewPasswordRequired,
+			parameters: map[string]string{
+				"USER_ID_FOR_SRP":    "internal-user",
+				"userAttributes":     `{"email":"alice@example.com"}`,
+				"requiredAttributes": `["userAttributes.name","phone_number"]`,
+			},
+			responses: map[string]string{
+				"NEW_PASSWORD":                "N3w-P@ssword!",
+				"userAttributes.name":         "Alice",
+				"userAttributes.phone_number": "+819012345678",
+				// セッションに記録していないキーは転送しない
+				"userAttributes.email": "attacker@example.com",
+			},
+			wantKeys:    []string{"NEW_PASSWORD", "USERNAME", "userAttributes.name", "userAttributes.phone_number"},
+			wantDetails: map[string]string{},
+		},
+		{
+			name:      "sms mfa",
+			challenge: domain.ChallengeSMSMFA,
+			parameters: map[string]string{
+				"USER_ID_FOR_SRP":               "internal-user",
+				"CODE_DELIVERY_DELIVERY_MEDIUM": "SMS",
+				"CODE_DELIVERY_DESTINATION":     "+*******5678",
+			},
+			responses: map[string]string{"SMS_MFA_CODE": "123456"},
+			wantKeys:  []string{"SMS_MFA_CODE", "USERNAME"},
+			wantDetails: map[string]string{
+				"CODE_DELIVERY_DELIVERY_MEDIUM": "SMS",
+				"CODE_DELIVERY_DESTINATION":     "+*******5678",
+			},
+		},
+		{
+			name:        "software token mfa",
+			challenge:   domain.ChallengeSoftwareTokenMFA,
+			parameters:  map[string]string{"USER_ID_FOR_SRP": "internal-user"},
+			responses:   map[string]string{"SOFTWARE_TOKEN_MFA_CODE": "654321", "SMS_MFA_CODE": "123456"},
+			wantKeys:    []string{"SOFTWARE_TOKEN_MFA_CODE", "USERNAME"},
+			wantDetails: map[string]string{},
+		},
+		{
+			name:      "select mfa type",
+			challenge: domain.ChallengeSelectMFAType,
+			parameters: map[string]string{
+				"USER_ID_FOR_SRP": "internal-user",
+				"MFAS_CAN_CHOOSE": `["SMS_MFA","SOFTWARE_TOKEN_MFA"]`,
+			},
+			responses:   map[string]string{"ANSWER": "SOFTWARE_TOKEN_MFA"},
+			wantKeys:    []string{"ANSWER", "USERNAME"},
+			wantDetails: map[string]string{},
+			wantOptions: []string{"SMS_MFA", "SOFTWARE_TOKEN_MFA"},
+		},
+		{
+			name:      "custom challenge",
+			challenge: domain.ChallengeCustomChallenge,
+			parameters: map[string]string{
+				"USER_ID_FOR_SRP": "internal-user",
+				"email":           "a***@example.com",
+			},
+			responses:   map[string]string{"ANSWER": "123456"},
+			wantKeys:    []string{"ANSWER", "USERNAME"},
+			wantDetails: map[string]string{"email": "a***@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &authUsecase{jwtSecret: testJWTSecret, passwordPolicy: domain.DefaultPasswordPolicy()}
+
+			challenge, err := u.newChallenge(&cognitoAuthStep{
+				challengeName: tt.challenge,
+				session:       aws.String("cognito-session"),
+				parameters:    tt.parameters,
+			}, "alice@example.com")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// USER_ID_FOR_SRP などの内部的なパラメータはクライアントに返さない
+			if !reflect.DeepEqual(challenge.Details, tt.wantDetails) {
+				t.Errorf("details = %v, want %v", challenge.Details, tt.wantDetails)
+			}
+			if !reflect.DeepEqual(challenge.Options, tt.wantOptions) {
+				t.Errorf("options = %v, want %v", challenge.Options, tt.wantOptions)
+			}
+
+			session, err := u.openChallengeSession(challenge.Session)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			responses, err := u.buildChallengeResponses(session, tt.responses)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var keys []string
+			for key := range responses {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("response keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if got := aws.StringValue(responses["USERNAME"]); got != "internal-user" {
+				t.Errorf("USERNAME = %q, want the SRP user id", got)
+			}
+			for _, key := range tt.wantKeys {
+				if key != "USERNAME" && aws.StringValue(responses[key]) != tt.responses[key] {
+					t.Errorf("%s = %q, want %q", key, aws.StringValue(responses[key]), tt.responses[key])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildChallengeResponses_Rejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		session   domain.ChallengeSession
+		responses map[string]string
+	}{
+		{
+			name:      "missing code",
+			session:   domain.ChallengeSession{Name: domain.ChallengeSMSMFA, Parameters: []string{"SMS_MFA_CODE"}},
+			responses: map[string]string{"SOFTWARE_TOKEN_MFA_CODE": "123456"},
+		},
+		{
+			name:      "weak new password",
+			session:   domain.ChallengeSession{Name: domain.ChallengeNewPasswordRequired, Parameters: []string{"NEW_PASSWORD"}},
+			responses: map[string]string{"NEW_PASSWORD": "password"},
+		},
+		{
+			name:    "missing required attribute",
+			session: domain.ChallengeSession{Name: domain.ChallengeNewPasswordRequired, Parameters: []string{"NEW_PASSWORD", "userAttributes.name"}},
+			responses: map[string]string{
+				"NEW_PASSWORD": "N3w-P@ssword!",
+			},
+		},
+		{
+			name: "mfa type not offered",
+			session: domain.ChallengeSession{
+				Name:       domain.ChallengeSelectMFAType,
+				Parameters: []string{"ANSWER"},
+				Options:    []string{"SOFTWARE_TOKEN_MFA"},
+			},
+			responses: map[string]string{"ANSWER": "SMS_MFA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &authUsecase{passwordPolicy: domain.DefaultPasswordPolicy()}
+
+			_, err := u.buildChallengeResponses(&tt.session, tt.responses)
+
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeValidation {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewChallenge_RejectsUnsupportedChallenge(t *testing.T) {
+	u := &authUsecase{jwtSecret: testJWTSecret}
+
+	if _, err := u.newChallenge(&cognitoAuthStep{challengeName: "DEVICE_SRP_AUTH"}, "alice@example.com"); err == nil {
+		t.Fatal("expected an error for an unsupported challenge")
+	}
+}
@@ -2,147 +2,534 @@ package usecase
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
 	"github.com/matthewyuh246/aws-cognito/internal/domain"
 	"github.com/matthewyuh246/aws-cognito/internal/repository"
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 )
 
+// authorizationStateTTL - state・nonce・code_verifierの有効期間
+const authorizationStateTTL = 10 * time.Minute
+
 type IAuthUsecase interface {
-	LoginWithSocialProvider(ctx context.Context, provider, authCode string) (*domain.AuthTokens, error)
+	LoginWithSocialProvider(ctx context.Context, provider, authCode, state, browserState, codeVerifier string) (*domain.AuthTokens, *domain.User, error)
+	CreateAuthorizationRequest(ctx context.Context, provider, codeChallenge, redirectURI string) (*domain.OAuthState, string, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, *domain.User, error)
+	Logout(ctx context.Context, refreshToken, accessToken, logoutURI string, globalSignOut bool) (string, error)
+	SignUp(ctx context.Context, email, password, name string) (*domain.SignUpResult, error)
+	ConfirmSignUp(ctx context.Context, email, code string) error
+	ResendConfirmationCode(ctx context.Context, email string) (*domain.CodeDeliveryDetails, error)
+	LoginWithPassword(ctx context.Context, email, password, mode string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error)
+	RespondToChallenge(ctx context.Context, session string, responses map[string]string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error)
+	StartPasswordlessLogin(ctx context.Context, email string) (*domain.AuthChallenge, error)
+	AnswerPasswordlessLogin(ctx context.Context, session, code string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, email, code, newPassword string) error
+	AssociateSoftwareToken(ctx context.Context, accessToken, accountName string) (*domain.TOTPEnrollment, error)
+	VerifySoftwareToken(ctx context.Context, accessToken, code, deviceName string) error
+	SetMFAPreference(ctx context.Context, accessToken string, preference domain.MFAPreference) error
 }
 
 type authUsecase struct {
-	userRepo      repository.IUserRepository
-	authRepo      repository.IAuthRepository
-	cognitoClient *cognitoidentityprovider.CognitoIdentityProvider
-	userPoolID    string
-	jwtSecret     string
+	userRepo         repository.IUserRepository
+	authRepo         repository.IAuthRepository
+	oauthStateRepo   repository.IOAuthStateRepository
+	rateLimitRepo    repository.IRateLimitRepository
+	deletionRepo     repository.IAccountDeletionRepository
+	identityProvider repository.IIdentityProvider
+	cognitoClient    cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	passwordPolicy   domain.PasswordPolicy
+	userPoolID       string
+	clientID         string
+	jwtSecret        string
+	mfaIssuer        string
+	profileClaims    domain.ProfileClaimConfig
 }
 
 func NewAuthUsecase(
 	userRepo repository.IUserRepository,
 	authRepo repository.IAuthRepository,
+	oauthStateRepo repository.IOAuthStateRepository,
+	rateLimitRepo repository.IRateLimitRepository,
+	deletionRepo repository.IAccountDeletionRepository,
+	identityProvider repository.IIdentityProvider,
 	awsSession *session.Session,
 	userPoolID,
-	jwtSecret string,
+	clientID,
+	jwtSecret,
+	mfaIssuer string,
+	profileClaims domain.ProfileClaimConfig,
 ) *authUsecase {
 	return &authUsecase{
-		userRepo:      userRepo,
-		authRepo:      authRepo,
-		cognitoClient: cognitoidentityprovider.New(awsSession),
-		userPoolID:    userPoolID,
-		jwtSecret:     jwtSecret,
+		userRepo:         userRepo,
+		authRepo:         authRepo,
+		oauthStateRepo:   oauthStateRepo,
+		rateLimitRepo:    rateLimitRepo,
+		deletionRepo:     deletionRepo,
+		identityProvider: identityProvider,
+		cognitoClient:    cognitoidentityprovider.New(awsSession),
+		passwordPolicy:   domain.DefaultPasswordPolicy(),
+		userPoolID:       userPoolID,
+		clientID:         clientID,
+		jwtSecret:        jwtSecret,
+		mfaIssuer:        mfaIssuer,
+		profileClaims:    profileClaims,
 	}
 }
 
-func (u *authUsecase) LoginWithSocialProvider(ctx context.Context, provider, authCode string) (*domain.AuthTokens, error) {
+// LoginWithSocialProvider - 認可コードをトークンに交換する
+// stateは CreateAuthorizationRequest で発行した未使用のものでなければならない
+// browserStateは認可リクエストを開始したブラウザのCookieに保存したstateで、stateと一致しなければならない
+func (u *authUsecase) LoginWithSocialProvider(ctx context.Context, provider, authCode, state, browserState, codeVerifier string) (*domain.AuthTokens, *domain.User, error) {
+	// state（CSRF対策）とPKCEのcode_verifierを検証
+	oauthState, verifier, err := u.consumeAuthorizationState(ctx, provider, state, browserState, codeVerifier)
+	if err != nil {
+		log.Printf("ERROR: Authorization state verification failed: %v", err)
+		return nil, nil, err
+	}
+
 	// 外部認証システムとの統合をリポジトリに委譲
-	tokens, err := u.authRepo.ExchangeCodeForTokens(ctx, authCode)
+	tokens, err := u.authRepo.ExchangeCodeForTokens(ctx, authCode, verifier, oauthState.RedirectURI)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// IDトークンの検証と解析（nonceでリプレイを防止）
+	userInfo, err := u.parseIDToken(ctx, tokens, oauthState.Nonce, true)
+	if err != nil {
+		log.Printf("ERROR: ID token verification failed: %v", err)
+		return nil, nil, err
+	}
+
+	// Hosted UIで別のIdPに切り替えられた場合、別プロバイダーのアイデンティティとして保存しない
+	if idTokenProvider := getString(userInfo, "provider"); idTokenProvider != provider {
+		log.Printf("ERROR: ID token provider mismatch: requested=%s actual=%s", provider, idTokenProvider)
+		return nil, nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "IDトークンのプロバイダーが一致しません", nil)
+	}
+
+	// 削除待ちのアカウントに紐付いていたIDでの再ログインなら削除を取り消す
+	if err := u.restorePendingDeletion(ctx, provider, userInfo); err != nil {
+		return nil, nil, err
+	}
+
+	// ユーザーの作成・更新
+	user, err := u.saveUser(ctx, provider, userInfo)
 	if err != nil {
-		// ドメインエラーをユーザー向けメッセージに変換
-		if authErr, ok := err.(*domain.AuthError); ok {
-			return nil, fmt.Errorf(authErr.UserMessage())
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
+
+// CreateAuthorizationRequest - 1回限りのstate・nonceとPKCEを発行して保存し、Hosted UIの認可URLを返す
+// codeChallengeが空の場合はcode_verifierをサーバー側で生成・保持し、SPAはverifierを扱わずに済む
+func (u *authUsecase) CreateAuthorizationRequest(ctx context.Context, provider, codeChallenge, redirectURI string) (*domain.OAuthState, string, error) {
+	resolvedRedirectURI, err := u.authRepo.ResolveRedirectURI(redirectURI)
+	if err != nil {
+		return nil, "", err
+	}
+
+	state, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeServer, "stateの生成に失敗しました", err)
+	}
+
+	nonce, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeServer, "nonceの生成に失敗しました", err)
+	}
+
+	oauthState := &domain.OAuthState{
+		State:         state,
+		Nonce:         nonce,
+		Provider:      provider,
+		RedirectURI:   resolvedRedirectURI,
+		CodeChallenge: codeChallenge,
+		ExpiresAt:     time.Now().Add(authorizationStateTTL),
+	}
+
+	if codeChallenge == "" {
+		verifier, err := utils.GenerateCodeVerifier()
+		if err != nil {
+			return nil, "", domain.NewAuthError(domain.AuthErrorTypeServer, "code_verifierの生成に失敗しました", err)
 		}
-		return nil, fmt.Errorf("認証に失敗しました")
+		oauthState.CodeVerifier = verifier
+		oauthState.CodeChallenge = utils.CodeChallengeS256(verifier)
 	}
 
-	// IDトークンの解析（ビジネスロジック）
-	userInfo, err := u.parseIDToken(tokens.IdToken)
+	authorizeURL, err := u.authRepo.BuildAuthorizeURL(ctx, oauthState)
 	if err != nil {
-		return nil, fmt.Errorf("ユーザー情報の取得に失敗しました")
+		return nil, "", err
 	}
 
-	// ユーザー処理のビジネスロジック（今後実装予定）
-	log.Printf("DEBUG: User authenticated: %s", userInfo["email"])
+	if err := u.oauthStateRepo.CreateState(ctx, oauthState); err != nil {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeServer, "stateの保存に失敗しました", err)
+	}
 
-	return tokens, nil
+	return oauthState, authorizeURL, nil
 }
 
-// parseIDToken - JWTのパースはビジネスロジックのため、usecaseに残す
+// consumeAuthorizationState - stateを使用済みにし、トークン交換に使うcode_verifierを決定する
+// クライアントがcode_verifierを送信した場合は保存済みのchallengeと一致しなければならない
+func (u *authUsecase) consumeAuthorizationState(ctx context.Context, provider, state, browserState, codeVerifier string) (*domain.OAuthState, string, error) {
+	if state == "" {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "stateがありません", domain.ErrOAuthStateNotFound)
+	}
 
+	// 他人が発行させたstateでログインさせる攻撃（ログインCSRF）を防ぐため、
+	// 認可リクエストを開始したブラウザからのログインであることを確認する（stateは消費しない）
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "stateが認可リクエストを開始したブラウザのものではありません", domain.ErrOAuthStateNotBound)
+	}
+
+	if codeVerifier != "" && !utils.IsValidCodeVerifier(codeVerifier) {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "code_verifierの形式が正しくありません", nil)
+	}
+
+	stored, err := u.oauthStateRepo.ConsumeState(ctx, state)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOAuthStateReused):
+			return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "使用済みのstateです", err)
+		case errors.Is(err, domain.ErrOAuthStateNotFound):
+			return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "不明なstateです", err)
+		default:
+			return nil, "", domain.NewAuthError(domain.AuthErrorTypeServer, "stateの取得に失敗しました", err)
+		}
+	}
 
-func (u *authUsecase) parseIDToken(idToken string) (map[string]interface{}, error) {
-	if idToken == "mock_id_token" {
-		log.Printf("DEBUG: Using mock ID token for development")
-		return map[string]interface{}{
-			"email":    "test@example.com",
-			"username": "testuser",
-			"name":     "Test User",
-			"picture":  "https://via.placeholder.com/150",
-			"sub":      "mock-user-id-123",
-		}, nil
+	if stored.IsExpired(time.Now()) {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "stateの有効期限が切れています", domain.ErrOAuthStateExpired)
 	}
 
-	if idToken == "dummy_id_token" {
-		return nil, fmt.Errorf("無効なIDトークンです")
+	if stored.Provider != provider {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "stateのプロバイダーが一致しません", nil)
 	}
 
-	parts := strings.Split(idToken, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("無効なJWTトークン形式です")
+	if codeVerifier == "" {
+		if stored.CodeVerifier == "" {
+			return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "code_verifierがありません", nil)
+		}
+		return stored, stored.CodeVerifier, nil
 	}
 
-	payload := parts[1]
-	if len(payload)%4 != 0 {
-		payload += strings.Repeat("=", 4-len(payload)%4)
+	if utils.CodeChallengeS256(codeVerifier) != stored.CodeChallenge {
+		return nil, "", domain.NewAuthError(domain.AuthErrorTypeSecurity, "code_verifierが一致しません", nil)
 	}
 
-	decoded, err := base64.URLEncoding.DecodeString(payload)
+	return stored, codeVerifier, nil
+}
+
+// RefreshTokens - リフレッシュトークンでトークンを再発行し、新しいIDトークンからユーザー情報を取り出す
+func (u *authUsecase) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, *domain.User, error) {
+	tokens, err := u.authRepo.RefreshTokens(ctx, refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("JWTペイロードのデコードに失敗しました: %w", err)
+		return nil, nil, err
 	}
 
-	var claims map[string]interface{}
-	if err := json.Unmarshal(decoded, &claims); err != nil {
-		return nil, fmt.Errorf("JWTクレームの解析に失敗しました: %w", err)
+	// リフレッシュ時のIDトークンにはnonceが含まれない
+	// パスワードログインで発行されたリフレッシュトークンの場合、アクセストークンにopenidスコープがない
+	userInfo, err := u.parseIDToken(ctx, tokens, "", u.hasOpenIDScope(ctx, tokens.AccessToken))
+	if err != nil {
+		log.Printf("ERROR: ID token verification failed: %v", err)
+		return nil, nil, err
 	}
 
-	return u.extractUserInfo(claims), nil
+	user, err := u.saveUser(ctx, getString(userInfo, "provider"), userInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
 }
 
-func (u *authUsecase) extractUserInfo(claims map[string]interface{}) map[string]interface{} {
-	email, _ := claims["email"].(string)
-	name, _ := claims["name"].(string)
-	picture, _ := claims["picture"].(string)
-	sub, _ := claims["sub"].(string)
+// Logout - リフレッシュトークンを失効させ、IdPのログアウトURLを返す
+// globalSignOutがtrueの場合はGlobalSignOutで全デバイスのトークンも無効化する
+func (u *authUsecase) Logout(ctx context.Context, refreshToken, accessToken, logoutURI string, globalSignOut bool) (string, error) {
+	logoutURL, err := u.authRepo.BuildLogoutURL(ctx, logoutURI)
+	if err != nil {
+		return "", err
+	}
+
+	if globalSignOut {
+		if u.identityProvider.Name() != repository.IdentityProviderCognito {
+			return "", domain.NewAuthError(domain.AuthErrorTypeConfig, "このIdPではグローバルサインアウトに対応していません", nil)
+		}
+		if accessToken == "" {
+			return "", domain.NewAuthError(domain.AuthErrorTypeValidation, "グローバルサインアウトにはアクセストークンが必要です", nil)
+		}
 
-	// 名前が空の場合、given_nameとfamily_nameから構築
-	if name == "" {
-		givenName, _ := claims["given_name"].(string)
-		familyName, _ := claims["family_name"].(string)
-		if givenName != "" || familyName != "" {
-			name = strings.TrimSpace(givenName + " " + familyName)
+		// aws.cognito.signin.user.admin スコープを持つアクセストークンが必要
+		_, err := u.cognitoClient.GlobalSignOutWithContext(ctx, &cognitoidentityprovider.GlobalSignOutInput{
+			AccessToken: aws.String(accessToken),
+		})
+		if err != nil {
+			return "", categorizeCognitoError(err, "グローバルサインアウトに失敗しました")
 		}
 	}
 
-	// 画像URLの代替フィールドをチェック
-	if picture == "" {
-		if avatar, ok := claims["avatar_url"].(string); ok {
-			picture = avatar
-		} else if photo, ok := claims["photo"].(string); ok {
-			picture = photo
-		} else if profileImage, ok := claims["profile_image_url"].(string); ok {
-			picture = profileImage
+	if err := u.authRepo.RevokeToken(ctx, refreshToken); err != nil {
+		return "", err
+	}
+
+	return logoutURL, nil
+}
+
+// saveUser - IDトークンのユーザー情報で (provider, sub) のIDに紐付くユーザーを作成または更新する
+func (u *authUsecase) saveUser(ctx context.Context, provider string, userInfo map[string]interface{}) (*domain.User, error) {
+	user := &domain.User{
+		Email:     getString(userInfo, "email"),
+		Username:  getString(userInfo, "username"),
+		Name:      getString(userInfo, "name"),
+		Picture:   getString(userInfo, "picture"),
+		Provider:  provider,
+		SubjectID: getString(userInfo, "sub"),
+	}
+
+	if user.SubjectID == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "IDトークンにsubがありません", nil)
+	}
+	if user.Email == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "メールアドレスを取得できませんでした", nil)
+	}
+
+	// 双方のメールアドレスが確認済みの場合のみ、同じメールの既存ユーザーにIDを自動で紐付ける
+	user.EmailVerified, _ = userInfo["email_verified"].(bool)
+
+	saved, err := u.userRepo.UpsertByIdentity(ctx, user)
+	if err != nil {
+		log.Printf("ERROR: Failed to save user: %v", err)
+		if errors.Is(err, domain.ErrUserEmailConflict) || errors.Is(err, domain.ErrUsernameConflict) {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeConflict, "ユーザーの登録が競合しました", err)
 		}
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの保存に失敗しました", err)
 	}
 
-	username := name
+	// グループはCognitoで管理するため保存せず、トークンの値をそのまま返す
+	saved.Groups, _ = userInfo["groups"].([]string)
+
+	return saved, nil
+}
+
+// parseIDToken - 署名・iss・aud・exp・token_useを検証した上でクレームを取り出す
+// expectedNonceが指定された場合はnonceクレームの一致も確認する
+// userInfoAllowedはアクセストークンでuserInfoエンドポイントを呼べる場合（openidスコープを持つ場合）のみtrueにする
+func (u *authUsecase) parseIDToken(ctx context.Context, tokens *domain.AuthTokens, expectedNonce string, userInfoAllowed bool) (map[string]interface{}, error) {
+	claims, err := u.identityProvider.VerifyIDToken(ctx, tokens.IdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedNonce != "" {
+		nonce, _ := claims["nonce"].(string)
+		if subtle.ConstantTimeCompare([]byte(nonce), []byte(expectedNonce)) != 1 {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "IDトークンのnonceが一致しません", nil)
+		}
+	}
+
+	normalizePicture(claims)
+	provider := u.identityProvider.ProviderFromClaims(claims)
+	if missing := u.missingProfileFields(provider, claims); len(missing) > 0 && userInfoAllowed && tokens.AccessToken != "" {
+		claims = u.mergeUserInfoClaims(ctx, tokens.AccessToken, claims, missing)
+	}
+
+	return u.extractUserInfo(provider, claims), nil
+}
+
+// hasOpenIDScope - アクセストークンがuserInfoエンドポイントを呼べるスコープを持つか
+// CognitoのInitiateAuth（パスワード・SRP・カスタム認証）で発行されたトークンは aws.cognito.signin.user.admin のみを持つ
+func (u *authUsecase) hasOpenIDScope(ctx context.Context, accessToken string) bool {
+	// 汎用OIDCのトークンは認可コードフローでのみ発行される
+	if u.identityProvider.Name() != repository.IdentityProviderCognito {
+		return true
+	}
+	claims, err := u.identityProvider.VerifyAccessToken(ctx, accessToken)
+	if err != nil {
+		return false
+	}
+	scope, _ := claims["scope"].(string)
+	return slices.Contains(strings.Fields(scope), "openid")
+}
+
+// missingProfileFields - 必須のプロフィール項目のうちIDトークンから取り出せないもの
+func (u *authUsecase) missingProfileFields(provider string, claims map[string]interface{}) []string {
+	var missing []string
+	for _, field := range u.profileClaims.Required {
+		if u.resolveProfileField(provider, field, claims) == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// mergeUserInfoClaims - IdPによってはIDトークンに含まれない属性（GitHubのメールアドレスなど）を
+// userInfoエンドポイントで補う。IDトークンのクレームを優先し、取得に失敗した場合はIDトークンのクレームのみで続ける
+func (u *authUsecase) mergeUserInfoClaims(ctx context.Context, accessToken string, claims map[string]interface{}, missing []string) map[string]interface{} {
+	userInfo, err := u.identityProvider.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		log.Printf("WARN: Failed to fetch userInfo for missing claims %v: %v", missing, err)
+		return claims
+	}
+
+	// 別のユーザーの属性を混ぜないよう、subが一致する場合のみ使う
+	if sub, _ := userInfo["sub"].(string); sub == "" || sub != getString(claims, "sub") {
+		log.Printf("WARN: userInfo subject does not match ID token subject")
+		return claims
+	}
+
+	normalizePicture(userInfo)
+	merged := make(map[string]interface{}, len(claims)+len(userInfo))
+	for key, value := range claims {
+		merged[key] = value
+	}
+	for key, value := range userInfo {
+		if existing, ok := merged[key]; !ok || existing == nil || existing == "" {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// extractUserInfo - PROFILE_CLAIM_MAPPINGS の対応でクレームからプロフィールを組み立てる
+func (u *authUsecase) extractUserInfo(provider string, claims map[string]interface{}) map[string]interface{} {
+	email := u.resolveProfileField(provider, domain.ProfileFieldEmail, claims)
+	username := u.resolveProfileField(provider, domain.ProfileFieldUsername, claims)
 	if username == "" {
 		username = strings.Split(email, "@")[0]
 	}
 
 	return map[string]interface{}{
-		"email":    email,
-		"username": username,
-		"name":     name,
-		"picture":  picture,
-		"sub":      sub,
+		"email":          email,
+		"username":       username,
+		"name":           u.resolveProfileField(provider, domain.ProfileFieldName, claims),
+		"picture":        u.resolveProfileField(provider, domain.ProfileFieldPicture, claims),
+		"sub":            getString(claims, "sub"),
+		"provider":       provider,
+		"email_verified": boolClaim(claims, "email_verified"),
+		"groups":         u.identityProvider.GroupsFromClaims(claims),
+	}
+}
+
+func (u *authUsecase) resolveProfileField(provider, field string, claims map[string]interface{}) string {
+	return u.profileClaims.Mappings.Resolve(provider, field, claims)
+}
+
+// normalizePicture - pictureクレームをURLの文字列に置き換える
+func normalizePicture(claims map[string]interface{}) {
+	if picture, ok := claims["picture"]; ok {
+		claims["picture"] = pictureURL(picture)
+	}
+}
+
+// pictureURL - Facebookのpictureは {"data":{"url":...}} 形式（Cognito経由ではそのJSON文字列）で届くためURLを取り出す
+func pictureURL(value interface{}) string {
+	var picture struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return v
+		}
+		if err := json.Unmarshal([]byte(v), &picture); err != nil {
+			return ""
+		}
+	case map[string]interface{}:
+		data, _ := v["data"].(map[string]interface{})
+		url, _ := data["url"].(string)
+		return url
+	default:
+		return ""
+	}
+	return picture.Data.URL
+}
+
+// boolClaim - Cognitoはフェデレーションの属性を文字列 "true" で返す場合がある
+func boolClaim(claims map[string]interface{}, key string) bool {
+	switch v := claims[key].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func getString(m map[string]interface{}, key string) string {
+	value, _ := m[key].(string)
+	return value
+}
+
+// categorizeCognitoError - Cognito APIのエラーをドメインエラーに変換する
+// 利用者に伝えるべき例外は対応するドメインのセンチネルエラーでラップする
+func categorizeCognitoError(err error, message string) *domain.AuthError {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return domain.NewAuthError(domain.AuthErrorTypeNetwork, message, err)
 	}
-}
\ No newline at end of file
+
+	switch aerr.Code() {
+	case cognitoidentityprovider.ErrCodeNotAuthorizedException:
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, message, err)
+	case cognitoidentityprovider.ErrCodeUserNotConfirmedException:
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, message, fmt.Errorf("%w: %w", domain.ErrUserNotConfirmed, err))
+	case cognitoidentityprovider.ErrCodePasswordResetRequiredException:
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, message, fmt.Errorf("%w: %w", domain.ErrPasswordResetRequired, err))
+	case cognitoidentityprovider.ErrCodeUsernameExistsException,
+		cognitoidentityprovider.ErrCodeAliasExistsException:
+		return domain.NewAuthError(domain.AuthErrorTypeConflict, message, fmt.Errorf("%w: %w", domain.ErrUserAlreadyExists, err))
+	case cognitoidentityprovider.ErrCodeInvalidPasswordException:
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, message, fmt.Errorf("%w: %w", domain.ErrInvalidPassword, err))
+	case cognitoidentityprovider.ErrCodeCodeMismatchException:
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, message, fmt.Errorf("%w: %w", domain.ErrCodeMismatch, err))
+	case cognitoidentityprovider.ErrCodeExpiredCodeException:
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, message, fmt.Errorf("%w: %w", domain.ErrCodeExpired, err))
+	case cognitoidentityprovider.ErrCodeCodeDeliveryFailureException:
+		return domain.NewAuthError(domain.AuthErrorTypeServer, message, fmt.Errorf("%w: %w", domain.ErrCodeDeliveryFailed, err))
+	case cognitoidentityprovider.ErrCodeLimitExceededException,
+		cognitoidentityprovider.ErrCodeTooManyRequestsException,
+		cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException:
+		return domain.NewAuthError(domain.AuthErrorTypeRateLimit, message, fmt.Errorf("%w: %w", domain.ErrTooManyAttempts, err))
+	case cognitoidentityprovider.ErrCodeUserNotFoundException:
+		return domain.NewAuthError(domain.AuthErrorTypeNotFound, message, err)
+	case cognitoidentityprovider.ErrCodeInvalidParameterException:
+		return domain.NewAuthError(domain.AuthErrorTypeClient, message, err)
+	case cognitoidentityprovider.ErrCodeResourceNotFoundException,
+		cognitoidentityprovider.ErrCodeInvalidUserPoolConfigurationException:
+		return domain.NewAuthError(domain.AuthErrorTypeConfig, message, err)
+	case cognitoidentityprovider.ErrCodeInternalErrorException:
+		return domain.NewAuthError(domain.AuthErrorTypeServer, message, err)
+	default:
+		return domain.NewAuthError(domain.AuthErrorTypeServer, message, err)
+	}
+}
+
+// isCognitoErrorCode - Cognito APIのエラーコードが一致するか
+func isCognitoErrorCode(err error, codes ...string) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	for _, code := range codes {
+		if aerr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
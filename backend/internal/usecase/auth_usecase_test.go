@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+type fakeAuthRepository struct {
+	repository.IAuthRepository
+	refreshErr error
+	revokeErr  error
+	revoked    []string
+}
+
+func (r *fakeAuthRepository) RefreshTokens(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	return nil, r.refreshErr
+}
+
+func (r *fakeAuthRepository) RevokeToken(ctx context.Context, refreshToken string) error {
+	r.revoked = append(r.revoked, refreshToken)
+	return r.revokeErr
+}
+
+func (r *fakeAuthRepository) BuildLogoutURL(ctx context.Context, logoutURI string) (string, error) {
+	return "https://auth.example.com/logout?logout_uri=" + logoutURI, nil
+}
+
+func TestRefreshTokens_KeepsAuthErrorType(t *testing.T) {
+	for _, errorType := range []domain.AuthErrorType{
+		domain.AuthErrorTypeSecurity,
+		domain.AuthErrorTypeNetwork,
+		domain.AuthErrorTypeServer,
+	} {
+		u := &authUsecase{authRepo: &fakeAuthRepository{
+			refreshErr: domain.NewAuthError(errorType, "認証サーバーエラー", nil),
+		}}
+
+		_, _, err := u.RefreshTokens(context.Background(), "refresh-token")
+
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) {
+			t.Fatalf("%s: expected *domain.AuthError, got %T", errorType, err)
+		}
+		if authErr.Type != errorType {
+			t.Errorf("error type = %s, want %s", authErr.Type, errorType)
+		}
+	}
+}
+
+type fakeOAuthStateRepository struct {
+	repository.IOAuthStateRepository
+	states   map[string]*domain.OAuthState
+	consumed []string
+}
+
+func (r *fakeOAuthStateRepository) ConsumeState(ctx context.Context, state string) (*domain.OAuthState, error) {
+	stored, ok := r.states[state]
+	if !ok {
+		return nil, domain.ErrOAuthStateNotFound
+	}
+	r.consumed = append(r.consumed, state)
+	return stored, nil
+}
+
+func TestConsumeAuthorizationState_RequiresBrowserBinding(t *testing.T) {
+	newUsecase := func() (*authUsecase, *fakeOAuthStateRepository) {
+		repo := &fakeOAuthStateRepository{states: map[string]*domain.OAuthState{
+			"issued-state": {
+				State:        "issued-state",
+				Provider:     "google",
+				CodeVerifier: "server-verifier",
+				ExpiresAt:    time.Now().Add(time.Minute),
+			},
+		}}
+		return &authUsecase{oauthStateRepo: repo}, repo
+	}
+
+	// 攻撃者が発行させたstateを、Cookieを持たない（または別のstateのCookieを持つ）ブラウザで使わせる
+	for _, browserState := range []string{"", "other-state"} {
+		u, repo := newUsecase()
+		_, _, err := u.consumeAuthorizationState(context.Background(), "google", "issued-state", browserState, "")
+		if !errors.Is(err, domain.ErrOAuthStateNotBound) {
+			t.Errorf("browser state %q: expected ErrOAuthStateNotBound, got %v", browserState, err)
+		}
+		if len(repo.consumed) != 0 {
+			t.Errorf("browser state %q: state should not be consumed", browserState)
+		}
+	}
+
+	u, repo := newUsecase()
+	stored, verifier, err := u.consumeAuthorizationState(context.Background(), "google", "issued-state", "issued-state", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.State != "issued-state" || verifier != "server-verifier" || len(repo.consumed) != 1 {
+		t.Errorf("unexpected result: state=%v verifier=%q consumed=%v", stored, verifier, repo.consumed)
+	}
+}
+
+func TestConsumeAuthorizationState_CodeVerifier(t *testing.T) {
+	// RFC 7636 Appendix B の例
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	tests := []struct {
+		name         string
+		stored       domain.OAuthState
+		codeVerifier string
+		wantVerifier string
+		wantErr      bool
+	}{
+		{
+			name:         "クライアントのcode_verifierがcode_challengeと一致する",
+			stored:       domain.OAuthState{CodeChallenge: challenge},
+			codeVerifier: verifier,
+			wantVerifier: verifier,
+		},
+		{
+			name:         "クライアントのcode_verifierが一致しない",
+			stored:       domain.OAuthState{CodeChallenge: challenge},
+			codeVerifier: strings.Repeat("a", 43),
+			wantErr:      true,
+		},
+		{
+			name:         "code_verifierの形式が正しくない",
+			stored:       domain.OAuthState{CodeChallenge: challenge},
+			codeVerifier: "short",
+			wantErr:      true,
+		},
+		{
+			name:         "サーバーが保持するcode_verifierを使う",
+			stored:       domain.OAuthState{CodeVerifier: verifier, CodeChallenge: challenge},
+			wantVerifier: verifier,
+		},
+		{
+			name:    "code_verifierがどこにもない",
+			stored:  domain.OAuthState{CodeChallenge: challenge},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.stored
+			stored.State = "issued-state"
+			stored.Provider = "google"
+			stored.ExpiresAt = time.Now().Add(time.Minute)
+			u := &authUsecase{oauthStateRepo: &fakeOAuthStateRepository{states: map[string]*domain.OAuthState{"issued-state": &stored}}}
+
+			_, got, err := u.consumeAuthorizationState(context.Background(), "google", "issued-state", "issued-state", tt.codeVerifier)
+			if tt.wantErr {
+				var authErr *domain.AuthError
+				if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeSecurity {
+					t.Fatalf("expected security error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantVerifier {
+				t.Errorf("verifier = %q, want %q", got, tt.wantVerifier)
+			}
+		})
+	}
+}
+
+type fakeIdentityProvider struct {
+	repository.IIdentityProvider
+	name          string
+	idClaims      map[string]interface{}
+	accessClaims  map[string]interface{}
+	userInfo      map[string]interface{}
+	userInfoCalls int
+}
+
+func (p *fakeIdentityProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeIdentityProvider) VerifyIDToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims := make(map[string]interface{}, len(p.idClaims))
+	for key, value := range p.idClaims {
+		claims[key] = value
+	}
+	return claims, nil
+}
+
+func (p *fakeIdentityProvider) VerifyAccessToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	if p.accessClaims == nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "トークンの検証に失敗しました", nil)
+	}
+	return p.accessClaims, nil
+}
+
+func (p *fakeIdentityProvider) GetUserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	p.userInfoCalls++
+	return p.userInfo, nil
+}
+
+func (p *fakeIdentityProvider) ProviderFromClaims(claims map[string]interface{}) string {
+	return "github"
+}
+
+func (p *fakeIdentityProvider) GroupsFromClaims(claims map[string]interface{}) []string {
+	return nil
+}
+
+// newProfileClaimsUsecase - メールアドレスが必須で、IDトークンにメールアドレスを含まないIdP
+func newProfileClaimsUsecase(userInfo map[string]interface{}) (*authUsecase, *fakeIdentityProvider) {
+	provider := &fakeIdentityProvider{
+		name:     repository.IdentityProviderCognito,
+		idClaims: map[string]interface{}{"sub": "user-sub", "name": "GitHub User"},
+		userInfo: userInfo,
+	}
+	return &authUsecase{
+		identityProvider: provider,
+		profileClaims: domain.ProfileClaimConfig{
+			Mappings: domain.DefaultProfileClaimMappings(),
+			Required: []string{domain.ProfileFieldEmail},
+		},
+	}, provider
+}
+
+func TestParseIDToken_MergesUserInfoClaims(t *testing.T) {
+	u, provider := newProfileClaimsUsecase(map[string]interface{}{
+		"sub":   "user-sub",
+		"email": "user@example.com",
+		"name":  "userInfo Name",
+	})
+
+	userInfo, err := u.parseIDToken(context.Background(), &domain.AuthTokens{IdToken: "id", AccessToken: "access"}, "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.userInfoCalls != 1 {
+		t.Errorf("userInfo calls = %d, want 1", provider.userInfoCalls)
+	}
+	if got := getString(userInfo, "email"); got != "user@example.com" {
+		t.Errorf("email = %q, want userInfo email", got)
+	}
+	// IDトークンのクレームを優先する
+	if got := getString(userInfo, "name"); got != "GitHub User" {
+		t.Errorf("name = %q, want ID token name", got)
+	}
+}
+
+func TestParseIDToken_RejectsUserInfoForOtherSubject(t *testing.T) {
+	for _, sub := range []interface{}{"other-sub", "", nil} {
+		u, provider := newProfileClaimsUsecase(map[string]interface{}{
+			"sub":   sub,
+			"email": "attacker@example.com",
+		})
+
+		userInfo, err := u.parseIDToken(context.Background(), &domain.AuthTokens{IdToken: "id", AccessToken: "access"}, "", true)
+		if err != nil {
+			t.Fatalf("sub %v: unexpected error: %v", sub, err)
+		}
+		if provider.userInfoCalls != 1 {
+			t.Errorf("sub %v: userInfo calls = %d, want 1", sub, provider.userInfoCalls)
+		}
+		if got := getString(userInfo, "email"); got != "" {
+			t.Errorf("sub %v: email = %q, userInfo of another subject must not be merged", sub, got)
+		}
+		if got := getString(userInfo, "sub"); got != "user-sub" {
+			t.Errorf("sub %v: sub = %q, want ID token sub", sub, got)
+		}
+	}
+}
+
+func TestParseIDToken_SkipsUserInfoWhenNotAllowed(t *testing.T) {
+	u, provider := newProfileClaimsUsecase(map[string]interface{}{"sub": "user-sub", "email": "user@example.com"})
+
+	// パスワード・SRP・カスタム認証のトークン
+	if _, err := u.parseIDToken(context.Background(), &domain.AuthTokens{IdToken: "id", AccessToken: "access"}, "", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.userInfoCalls != 0 {
+		t.Errorf("userInfo calls = %d, want 0", provider.userInfoCalls)
+	}
+}
+
+func TestHasOpenIDScope(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		accessClaims map[string]interface{}
+		want         bool
+	}{
+		{"Hosted UIのトークン", repository.IdentityProviderCognito, map[string]interface{}{"scope": "openid email profile"}, true},
+		{"InitiateAuthのトークン", repository.IdentityProviderCognito, map[string]interface{}{"scope": "aws.cognito.signin.user.admin"}, false},
+		{"scopeがない", repository.IdentityProviderCognito, map[string]interface{}{}, false},
+		{"検証できない", repository.IdentityProviderCognito, nil, false},
+		{"汎用OIDCは認可コードフローのみ", repository.IdentityProviderOIDC, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &authUsecase{identityProvider: &fakeIdentityProvider{name: tt.provider, accessClaims: tt.accessClaims}}
+			if got := u.hasOpenIDScope(context.Background(), "access"); got != tt.want {
+				t.Errorf("hasOpenIDScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeSignOutClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	signOutErr    error
+	signedOutWith []string
+}
+
+func (c *fakeSignOutClient) GlobalSignOutWithContext(ctx aws.Context, input *cognitoidentityprovider.GlobalSignOutInput, opts ...request.Option) (*cognitoidentityprovider.GlobalSignOutOutput, error) {
+	c.signedOutWith = append(c.signedOutWith, aws.StringValue(input.AccessToken))
+	if c.signOutErr != nil {
+		return nil, c.signOutErr
+	}
+	return &cognitoidentityprovider.GlobalSignOutOutput{}, nil
+}
+
+func TestLogout_GlobalSignOut(t *testing.T) {
+	authRepo := &fakeAuthRepository{}
+	client := &fakeSignOutClient{}
+	u := &authUsecase{
+		authRepo:         authRepo,
+		cognitoClient:    client,
+		identityProvider: &fakeIdentityProvider{name: repository.IdentityProviderCognito},
+	}
+
+	logoutURL, err := u.Logout(context.Background(), "refresh-token", "access-token", "http://localhost:5173", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logoutURL != "https://auth.example.com/logout?logout_uri=http://localhost:5173" {
+		t.Errorf("unexpected logout URL: %s", logoutURL)
+	}
+	if len(client.signedOutWith) != 1 || client.signedOutWith[0] != "access-token" {
+		t.Errorf("GlobalSignOut calls = %v, want [access-token]", client.signedOutWith)
+	}
+	if len(authRepo.revoked) != 1 || authRepo.revoked[0] != "refresh-token" {
+		t.Errorf("revoked = %v, want [refresh-token]", authRepo.revoked)
+	}
+}
+
+func TestLogout_GlobalSignOutRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		accessToken string
+		signOutErr  error
+		wantType    domain.AuthErrorType
+	}{
+		{"汎用OIDC", repository.IdentityProviderOIDC, "access-token", nil, domain.AuthErrorTypeConfig},
+		{"アクセストークンがない", repository.IdentityProviderCognito, "", nil, domain.AuthErrorTypeValidation},
+		{
+			// aws.cognito.signin.user.admin スコープを持たないアクセストークン
+			"スコープが足りない",
+			repository.IdentityProviderCognito,
+			"access-token",
+			awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "Access Token does not have required scopes", nil),
+			domain.AuthErrorTypeSecurity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authRepo := &fakeAuthRepository{}
+			u := &authUsecase{
+				authRepo:         authRepo,
+				cognitoClient:    &fakeSignOutClient{signOutErr: tt.signOutErr},
+				identityProvider: &fakeIdentityProvider{name: tt.provider},
+			}
+
+			_, err := u.Logout(context.Background(), "refresh-token", tt.accessToken, "", true)
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) || authErr.Type != tt.wantType {
+				t.Fatalf("expected %s error, got %v", tt.wantType, err)
+			}
+			// グローバルサインアウトに失敗した場合はリフレッシュトークンも失効させない（再試行できるように）
+			if len(authRepo.revoked) != 0 {
+				t.Errorf("revoked = %v, want none", authRepo.revoked)
+			}
+		})
+	}
+}
+
+func TestLogout_WithoutGlobalSignOut(t *testing.T) {
+	authRepo := &fakeAuthRepository{revokeErr: domain.NewAuthError(domain.AuthErrorTypeConfig, "トークンの失効に失敗しました", nil)}
+	client := &fakeSignOutClient{}
+	u := &authUsecase{authRepo: authRepo, cognitoClient: client}
+
+	_, err := u.Logout(context.Background(), "refresh-token", "access-token", "", false)
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeConfig {
+		t.Fatalf("expected revoke error type to be kept, got %v", err)
+	}
+	if len(client.signedOutWith) != 0 {
+		t.Errorf("GlobalSignOut should not be called: %v", client.signedOutWith)
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity/cognitoidentityiface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	// awsCredentialsRefreshMargin - 有効期限までの残りがこれを下回った認証情報は再発行する
+	awsCredentialsRefreshMargin = 5 * time.Minute
+	// maxAWSCredentialsCacheEntries - これを超えたら期限切れのエントリを掃除する
+	maxAWSCredentialsCacheEntries = 10000
+)
+
+type IAWSCredentialsUsecase interface {
+	GetCredentials(ctx context.Context, claims *domain.UserClaims, idToken string) (*domain.AWSCredentials, error)
+}
+
+type awsCredentialsUsecase struct {
+	identityClient cognitoidentityiface.CognitoIdentityAPI
+	identityPoolID string
+	// loginProvider - IDプールに登録したユーザープールのプロバイダー名（cognito-idp.{region}.amazonaws.com/{userPoolId}）
+	loginProvider string
+
+	mu sync.Mutex
+	// identityIDs - ユーザー（sub）ごとのIDプールのID（GetIdの結果は変わらない）
+	identityIDs map[string]string
+	// cache - IDプールのIDごとの認証情報
+	cache map[string]*domain.AWSCredentials
+}
+
+func NewAWSCredentialsUsecase(awsSession *session.Session, identityPoolID, userPoolIssuer string) *awsCredentialsUsecase {
+	return &awsCredentialsUsecase{
+		identityClient: cognitoidentity.New(awsSession),
+		identityPoolID: identityPoolID,
+		loginProvider:  strings.TrimPrefix(userPoolIssuer, "https://"),
+		identityIDs:    make(map[string]string),
+		cache:          make(map[string]*domain.AWSCredentials),
+	}
+}
+
+// GetCredentials - 検証済みのIDトークンでIDプールの一時的な認証情報を取得する
+// 同じIDの認証情報は有効期限の少し前まで使い回す
+func (u *awsCredentialsUsecase) GetCredentials(ctx context.Context, claims *domain.UserClaims, idToken string) (*domain.AWSCredentials, error) {
+	if u.identityPoolID == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeConfig, "IDENTITY_POOL_ID環境変数が設定されていません", nil)
+	}
+	if claims == nil || claims.Sub == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "認証情報がありません", nil)
+	}
+
+	if credentials, ok := u.cached(claims.Sub); ok {
+		return credentials, nil
+	}
+
+	logins := map[string]*string{u.loginProvider: aws.String(idToken)}
+
+	identityID, err := u.identityID(ctx, claims.Sub, logins)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := u.identityClient.GetCredentialsForIdentityWithContext(ctx, &cognitoidentity.GetCredentialsForIdentityInput{
+		IdentityId: aws.String(identityID),
+		Logins:     logins,
+	})
+	if err != nil {
+		log.Printf("ERROR: GetCredentialsForIdentity failed: identity=%s err=%v", identityID, err)
+		// IDプールからIDが削除された場合に備えて、次回はGetIdからやり直す
+		u.mu.Lock()
+		delete(u.identityIDs, claims.Sub)
+		u.mu.Unlock()
+		return nil, categorizeIdentityPoolError(err, "AWS認証情報の取得に失敗しました")
+	}
+	if out.Credentials == nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeParse, "AWS認証情報がありません", nil)
+	}
+
+	credentials := &domain.AWSCredentials{
+		IdentityID:      aws.StringValue(out.IdentityId),
+		AccessKeyID:     aws.StringValue(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(out.Credentials.SecretKey),
+		SessionToken:    aws.StringValue(out.Credentials.SessionToken),
+		Expiration:      aws.TimeValue(out.Credentials.Expiration),
+	}
+	u.store(claims.Sub, credentials)
+	return credentials, nil
+}
+
+// identityID - ユーザーのIDプールのID（初回はGetIdで作成される）
+func (u *awsCredentialsUsecase) identityID(ctx context.Context, sub string, logins map[string]*string) (string, error) {
+	u.mu.Lock()
+	identityID, ok := u.identityIDs[sub]
+	u.mu.Unlock()
+	if ok {
+		return identityID, nil
+	}
+
+	out, err := u.identityClient.GetIdWithContext(ctx, &cognitoidentity.GetIdInput{
+		IdentityPoolId: aws.String(u.identityPoolID),
+		Logins:         logins,
+	})
+	if err != nil {
+		log.Printf("ERROR: GetId failed: %v", err)
+		return "", categorizeIdentityPoolError(err, "IDプールのIDの取得に失敗しました")
+	}
+
+	identityID = aws.StringValue(out.IdentityId)
+	u.mu.Lock()
+	u.identityIDs[sub] = identityID
+	u.mu.Unlock()
+	return identityID, nil
+}
+
+func (u *awsCredentialsUsecase) cached(sub string) (*domain.AWSCredentials, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	identityID, ok := u.identityIDs[sub]
+	if !ok {
+		return nil, false
+	}
+	credentials, ok := u.cache[identityID]
+	if !ok || time.Now().Add(awsCredentialsRefreshMargin).After(credentials.Expiration) {
+		return nil, false
+	}
+	return credentials, true
+}
+
+func (u *awsCredentialsUsecase) store(sub string, credentials *domain.AWSCredentials) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	if len(u.cache) >= maxAWSCredentialsCacheEntries {
+		for identityID, entry := range u.cache {
+			if now.After(entry.Expiration) {
+				delete(u.cache, identityID)
+			}
+		}
+	}
+	u.identityIDs[sub] = credentials.IdentityID
+	u.cache[credentials.IdentityID] = credentials
+}
+
+// categorizeIdentityPoolError - IDプール（Cognito Identity）のエラーをドメインエラーに変換する
+func categorizeIdentityPoolError(err error, message string) *domain.AuthError {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return domain.NewAuthError(domain.AuthErrorTypeNetwork, message, err)
+	}
+
+	switch aerr.Code() {
+	case cognitoidentity.ErrCodeNotAuthorizedException:
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, message, err)
+	case cognitoidentity.ErrCodeLimitExceededException,
+		cognitoidentity.ErrCodeTooManyRequestsException:
+		return domain.NewAuthError(domain.AuthErrorTypeRateLimit, message, fmt.Errorf("%w: %w", domain.ErrTooManyAttempts, err))
+	case cognitoidentity.ErrCodeResourceNotFoundException,
+		cognitoidentity.ErrCodeInvalidIdentityPoolConfigurationException:
+		return domain.NewAuthError(domain.AuthErrorTypeConfig, message, err)
+	case cognitoidentity.ErrCodeInvalidParameterException:
+		return domain.NewAuthError(domain.AuthErrorTypeClient, message, err)
+	default:
+		return domain.NewAuthError(domain.AuthErrorTypeServer, message, err)
+	}
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity/cognitoidentityiface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+type fakeIdentityClient struct {
+	cognitoidentityiface.CognitoIdentityAPI
+	// expiresIn - 発行する認証情報の有効期間
+	expiresIn      time.Duration
+	credentialsErr error
+	getIDCalls     int
+	getCredentials int
+}
+
+func (c *fakeIdentityClient) GetIdWithContext(ctx aws.Context, input *cognitoidentity.GetIdInput, opts ...request.Option) (*cognitoidentity.GetIdOutput, error) {
+	c.getIDCalls++
+	var idToken string
+	for _, token := range input.Logins {
+		idToken = aws.StringValue(token)
+	}
+	return &cognitoidentity.GetIdOutput{IdentityId: aws.String("identity-" + idToken)}, nil
+}
+
+func (c *fakeIdentityClient) GetCredentialsForIdentityWithContext(ctx aws.Context, input *cognitoidentity.GetCredentialsForIdentityInput, opts ...request.Option) (*cognitoidentity.GetCredentialsForIdentityOutput, error) {
+	c.getCredentials++
+	if c.credentialsErr != nil {
+		return nil, c.credentialsErr
+	}
+	return &cognitoidentity.GetCredentialsForIdentityOutput{
+		IdentityId: input.IdentityId,
+		Credentials: &cognitoidentity.Credentials{
+			AccessKeyId:  aws.String(fmt.Sprintf("AKIA%d", c.getCredentials)),
+			SecretKey:    aws.String("secret"),
+			SessionToken: aws.String("session"),
+			Expiration:   aws.Time(time.Now().Add(c.expiresIn)),
+		},
+	}, nil
+}
+
+func newTestAWSCredentialsUsecase(client *fakeIdentityClient) *awsCredentialsUsecase {
+	return &awsCredentialsUsecase{
+		identityClient: client,
+		identityPoolID: "ap-northeast-1:pool",
+		loginProvider:  "cognito-idp.ap-northeast-1.amazonaws.com/ap-northeast-1_TEST",
+		identityIDs:    make(map[string]string),
+		cache:          make(map[string]*domain.AWSCredentials),
+	}
+}
+
+func TestGetCredentials_CachesPerSubject(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeIdentityClient{expiresIn: time.Hour}
+	u := newTestAWSCredentialsUsecase(client)
+
+	first, err := u.GetCredentials(ctx, &domain.UserClaims{Sub: "alice"}, "alice-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := u.GetCredentials(ctx, &domain.UserClaims{Sub: "alice"}, "alice-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second != first {
+		t.Error("expected cached credentials for the same subject")
+	}
+	if client.getIDCalls != 1 || client.getCredentials != 1 {
+		t.Errorf("calls = GetId %d, GetCredentialsForIdentity %d, want 1, 1", client.getIDCalls, client.getCredentials)
+	}
+
+	// 別のユーザーには別の認証情報を発行する
+	other, err := u.GetCredentials(ctx, &domain.UserClaims{Sub: "bob"}, "bob-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.IdentityID == first.IdentityID || other.AccessKeyID == first.AccessKeyID {
+		t.Errorf("credentials of another subject must not be shared: %+v", other)
+	}
+}
+
+func TestGetCredentials_RefreshesBeforeExpiration(t *testing.T) {
+	tests := []struct {
+		name        string
+		expiresIn   time.Duration
+		wantFetches int
+	}{
+		{"期限まで余裕がある", awsCredentialsRefreshMargin + time.Minute, 1},
+		{"期限の5分前を過ぎている", awsCredentialsRefreshMargin - time.Minute, 2},
+		{"期限切れ", -time.Minute, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := &fakeIdentityClient{expiresIn: tt.expiresIn}
+			u := newTestAWSCredentialsUsecase(client)
+			claims := &domain.UserClaims{Sub: "alice"}
+
+			for i := 0; i < 2; i++ {
+				if _, err := u.GetCredentials(ctx, claims, "alice-token"); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if client.getCredentials != tt.wantFetches {
+				t.Errorf("GetCredentialsForIdentity calls = %d, want %d", client.getCredentials, tt.wantFetches)
+			}
+			// IDプールのIDは再発行時も使い回す
+			if client.getIDCalls != 1 {
+				t.Errorf("GetId calls = %d, want 1", client.getIDCalls)
+			}
+		})
+	}
+}
+
+func TestGetCredentials_RetriesGetIdAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeIdentityClient{
+		expiresIn:      time.Hour,
+		credentialsErr: awserr.New(cognitoidentity.ErrCodeResourceNotFoundException, "identity not found", nil),
+	}
+	u := newTestAWSCredentialsUsecase(client)
+	claims := &domain.UserClaims{Sub: "alice"}
+
+	_, err := u.GetCredentials(ctx, claims, "alice-token")
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeConfig {
+		t.Fatalf("expected config error, got %v", err)
+	}
+
+	client.credentialsErr = nil
+	if _, err := u.GetCredentials(ctx, claims, "alice-token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.getIDCalls != 2 {
+		t.Errorf("GetId calls = %d, want 2", client.getIDCalls)
+	}
+}
+
+func TestGetCredentials_Rejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolID   string
+		claims   *domain.UserClaims
+		wantType domain.AuthErrorType
+	}{
+		{"IDプールが未設定", "", &domain.UserClaims{Sub: "alice"}, domain.AuthErrorTypeConfig},
+		{"認証情報がない", "ap-northeast-1:pool", nil, domain.AuthErrorTypeSecurity},
+		{"subがない", "ap-northeast-1:pool", &domain.UserClaims{}, domain.AuthErrorTypeSecurity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeIdentityClient{expiresIn: time.Hour}
+			u := newTestAWSCredentialsUsecase(client)
+			u.identityPoolID = tt.poolID
+
+			_, err := u.GetCredentials(context.Background(), tt.claims, "token")
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) || authErr.Type != tt.wantType {
+				t.Fatalf("expected %s error, got %v", tt.wantType, err)
+			}
+			if client.getIDCalls != 0 {
+				t.Errorf("GetId calls = %d, want 0", client.getIDCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// AssociateSoftwareToken - 認証アプリ（TOTP）用のシークレットを発行する
+// accountNameは認証アプリに表示する名前（通常はメールアドレス）
+func (u *authUsecase) AssociateSoftwareToken(ctx context.Context, accessToken, accountName string) (*domain.TOTPEnrollment, error) {
+	out, err := u.cognitoClient.AssociateSoftwareTokenWithContext(ctx, &cognitoidentityprovider.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+	})
+	if err != nil {
+		return nil, categorizeCognitoError(err, "認証アプリの登録に失敗しました")
+	}
+
+	secret := aws.StringValue(out.SecretCode)
+	return &domain.TOTPEnrollment{
+		SecretCode: secret,
+		OTPAuthURI: buildOTPAuthURI(u.mfaIssuer, accountName, secret),
+	}, nil
+}
+
+// VerifySoftwareToken - 認証アプリが生成したコードでTOTPの登録を完了する
+func (u *authUsecase) VerifySoftwareToken(ctx context.Context, accessToken, code, deviceName string) error {
+	input := &cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+		UserCode:    aws.String(code),
+	}
+	if deviceName != "" {
+		input.FriendlyDeviceName = aws.String(deviceName)
+	}
+
+	out, err := u.cognitoClient.VerifySoftwareTokenWithContext(ctx, input)
+	if err != nil {
+		return categorizeCognitoError(err, "認証アプリのコード検証に失敗しました")
+	}
+
+	if aws.StringValue(out.Status) != cognitoidentityprovider.VerifySoftwareTokenResponseTypeSuccess {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "認証アプリのコードが正しくありません", domain.ErrCodeMismatch)
+	}
+
+	return nil
+}
+
+// SetMFAPreference - TOTPによるMFAの有効化・優先設定を更新する
+func (u *authUsecase) SetMFAPreference(ctx context.Context, accessToken string, preference domain.MFAPreference) error {
+	if preference.TOTPPreferred && !preference.TOTPEnabled {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "無効なMFAを優先に設定することはできません", nil)
+	}
+
+	_, err := u.cognitoClient.SetUserMFAPreferenceWithContext(ctx, &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(accessToken),
+		SoftwareTokenMfaSettings: &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(preference.TOTPEnabled),
+			PreferredMfa: aws.Bool(preference.TOTPPreferred),
+		},
+	})
+	if err != nil {
+		return categorizeCognitoError(err, "MFAの設定に失敗しました")
+	}
+
+	return nil
+}
+
+// buildOTPAuthURI - 認証アプリで読み取る otpauth://totp/{issuer}:{account}?secret=...&issuer=... を組み立てる
+// CognitoのTOTPは SHA1・6桁・30秒（いずれも認証アプリの既定値）
+func buildOTPAuthURI(issuer, accountName, secret string) string {
+	label := accountName
+	if issuer != "" {
+		label = issuer + ":" + accountName
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+
+	return (&url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + label,
+		// 空白は + ではなく %20 で表す（Key Uri Format の推奨）
+		RawQuery: strings.ReplaceAll(query.Encode(), "+", "%20"),
+	}).String()
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// fakeCognitoClient - テストで使うメソッドだけを実装したCognitoクライアント
+type fakeCognitoClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+
+	secretCode   string
+	verifyStatus string
+	verifyInput  *cognitoidentityprovider.VerifySoftwareTokenInput
+}
+
+func (c *fakeCognitoClient) AssociateSoftwareTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.AssociateSoftwareTokenInput, opts ...request.Option) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error) {
+	return &cognitoidentityprovider.AssociateSoftwareTokenOutput{SecretCode: aws.String(c.secretCode)}, nil
+}
+
+func (c *fakeCognitoClient) VerifySoftwareTokenWithContext(ctx aws.Context, input *cognitoidentityprovider.VerifySoftwareTokenInput, opts ...request.Option) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error) {
+	c.verifyInput = input
+	return &cognitoidentityprovider.VerifySoftwareTokenOutput{Status: aws.String(c.verifyStatus)}, nil
+}
+
+func TestBuildOTPAuthURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		account string
+		want    string
+	}{
+		{
+			name:    "issuer prefix",
+			issuer:  "aws-cognito",
+			account: "alice@example.com",
+			want:    "otpauth://totp/aws-cognito:alice@example.com?issuer=aws-cognito&secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			name:    "spaces encoded as %20",
+			issuer:  "My App",
+			account: "alice@example.com",
+			want:    "otpauth://totp/My%20App:alice@example.com?issuer=My%20App&secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			name:    "no issuer",
+			account: "alice@example.com",
+			want:    "otpauth://totp/alice@example.com?secret=JBSWY3DPEHPK3PXP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildOTPAuthURI(tt.issuer, tt.account, "JBSWY3DPEHPK3PXP"); got != tt.want {
+				t.Errorf("buildOTPAuthURI() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSoftwareTokenEnrollment(t *testing.T) {
+	client := &fakeCognitoClient{secretCode: "JBSWY3DPEHPK3PXP", verifyStatus: cognitoidentityprovider.VerifySoftwareTokenResponseTypeSuccess}
+	u := &authUsecase{cognitoClient: client, mfaIssuer: "aws-cognito"}
+
+	enrollment, err := u.AssociateSoftwareToken(context.Background(), "access-token", "alice@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.SecretCode != "JBSWY3DPEHPK3PXP" || enrollment.OTPAuthURI != buildOTPAuthURI("aws-cognito", "alice@example.com", "JBSWY3DPEHPK3PXP") {
+		t.Errorf("unexpected enrollment: %+v", enrollment)
+	}
+
+	if err := u.VerifySoftwareToken(context.Background(), "access-token", "123456", "phone"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if aws.StringValue(client.verifyInput.UserCode) != "123456" || aws.StringValue(client.verifyInput.FriendlyDeviceName) != "phone" {
+		t.Errorf("unexpected verify input: %v", client.verifyInput)
+	}
+
+	// Cognitoがエラーではなく ERROR ステータスを返した場合もコード不一致として扱う
+	client.verifyStatus = cognitoidentityprovider.VerifySoftwareTokenResponseTypeError
+	err = u.VerifySoftwareToken(context.Background(), "access-token", "000000", "")
+	if !errors.Is(err, domain.ErrCodeMismatch) {
+		t.Fatalf("expected ErrCodeMismatch, got %v", err)
+	}
+	if client.verifyInput.FriendlyDeviceName != nil {
+		t.Error("empty device name should not be sent")
+	}
+}
+
+func TestSetMFAPreference_RejectsPreferredWithoutEnabled(t *testing.T) {
+	u := &authUsecase{cognitoClient: &fakeCognitoClient{}}
+
+	err := u.SetMFAPreference(context.Background(), "access-token", domain.MFAPreference{TOTPPreferred: true})
+
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
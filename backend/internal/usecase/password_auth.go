@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/srp"
+)
+
+// SignUp - メールアドレスとパスワードでユーザープールにユーザーを登録し、確認コードを送信させる
+// ユーザープールはメールアドレスをユーザー名として使用する
+func (u *authUsecase) SignUp(ctx context.Context, email, password, name string) (*domain.SignUpResult, error) {
+	if err := u.passwordPolicy.Check(password); err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "パスワードがポリシーを満たしていません", err)
+	}
+
+	attributes := []*cognitoidentityprovider.AttributeType{
+		{Name: aws.String("email"), Value: aws.String(email)},
+	}
+	if name != "" {
+		attributes = append(attributes, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String("name"),
+			Value: aws.String(name),
+		})
+	}
+
+	out, err := u.cognitoClient.SignUpWithContext(ctx, &cognitoidentityprovider.SignUpInput{
+		ClientId:       aws.String(u.clientID),
+		Username:       aws.String(email),
+		Password:       aws.String(password),
+		UserAttributes: attributes,
+	})
+	if err != nil {
+		if isCognitoErrorCode(err, cognitoidentityprovider.ErrCodeInvalidPasswordException) {
+			return nil, u.passwordRejectedError(password, err)
+		}
+		return nil, categorizeCognitoError(err, "サインアップに失敗しました")
+	}
+
+	return &domain.SignUpResult{
+		UserSub:       aws.StringValue(out.UserSub),
+		UserConfirmed: aws.BoolValue(out.UserConfirmed),
+		CodeDelivery:  newCodeDeliveryDetails(out.CodeDeliveryDetails),
+	}, nil
+}
+
+// ConfirmSignUp - サインアップ時に送信された確認コードでユーザーを確認済みにする
+func (u *authUsecase) ConfirmSignUp(ctx context.Context, email, code string) error {
+	_, err := u.cognitoClient.ConfirmSignUpWithContext(ctx, &cognitoidentityprovider.ConfirmSignUpInput{
+		ClientId:         aws.String(u.clientID),
+		Username:         aws.String(email),
+		ConfirmationCode: aws.String(code),
+	})
+	if err != nil {
+		return categorizeCognitoError(err, "確認コードの検証に失敗しました")
+	}
+	return nil
+}
+
+// ResendConfirmationCode - 確認コードを再送信する
+func (u *authUsecase) ResendConfirmationCode(ctx context.Context, email string) (*domain.CodeDeliveryDetails, error) {
+	out, err := u.cognitoClient.ResendConfirmationCodeWithContext(ctx, &cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId: aws.String(u.clientID),
+		Username: aws.String(email),
+	})
+	if err != nil {
+		return nil, categorizeCognitoError(err, "確認コードの再送信に失敗しました")
+	}
+	return newCodeDeliveryDetails(out.CodeDeliveryDetails), nil
+}
+
+// LoginWithPassword - メールアドレスとパスワードでログインし、IDトークンのユーザー情報をローカルに保存する
+// modeが PasswordLoginModeSRP の場合は USER_SRP_AUTH、それ以外は USER_PASSWORD_AUTH を使用する
+// MFAなどのチャレンジが必要な場合はトークンの代わりにチャレンジを返す
+func (u *authUsecase) LoginWithPassword(ctx context.Context, email, password, mode string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error) {
+	var (
+		step *cognitoAuthStep
+		err  error
+	)
+	if mode == domain.PasswordLoginModeSRP {
+		step, err = u.initiateSRPAuth(ctx, email, password)
+	} else {
+		step, err = u.initiatePasswordAuth(ctx, email, password)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return u.finishAuthStep(ctx, step, email)
+}
+
+// initiatePasswordAuth - USER_PASSWORD_AUTH
+func (u *authUsecase) initiatePasswordAuth(ctx context.Context, email, password string) (*cognitoAuthStep, error) {
+	out, err := u.cognitoClient.InitiateAuthWithContext(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserPasswordAuth),
+		ClientId: aws.String(u.clientID),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String(email),
+			"PASSWORD": aws.String(password),
+		},
+	})
+	if err != nil {
+		return nil, categorizeLoginError(err)
+	}
+
+	return &cognitoAuthStep{
+		challengeName: aws.StringValue(out.ChallengeName),
+		session:       out.Session,
+		parameters:    aws.StringValueMap(out.ChallengeParameters),
+		result:        out.AuthenticationResult,
+	}, nil
+}
+
+// initiateSRPAuth - USER_SRP_AUTH。PASSWORD_VERIFIER チャレンジにパスワードから計算した署名で応答する
+func (u *authUsecase) initiateSRPAuth(ctx context.Context, email, password string) (*cognitoAuthStep, error) {
+	client, err := srp.NewClient(u.userPoolID)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "SRPの初期化に失敗しました", err)
+	}
+
+	out, err := u.cognitoClient.InitiateAuthWithContext(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeUserSrpAuth),
+		ClientId: aws.String(u.clientID),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String(email),
+			"SRP_A":    aws.String(client.SRPA()),
+		},
+	})
+	if err != nil {
+		return nil, categorizeLoginError(err)
+	}
+
+	if aws.StringValue(out.ChallengeName) != cognitoidentityprovider.ChallengeNameTypePasswordVerifier {
+		return nil, unsupportedChallengeError(out.ChallengeName)
+	}
+
+	params := aws.StringValueMap(out.ChallengeParameters)
+	userIDForSRP := params["USER_ID_FOR_SRP"]
+
+	signature, timestamp, err := client.PasswordVerifier(
+		userIDForSRP,
+		password,
+		params["SALT"],
+		params["SRP_B"],
+		params["SECRET_BLOCK"],
+		time.Now(),
+	)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "SRPチャレンジの計算に失敗しました", err)
+	}
+
+	resp, err := u.cognitoClient.RespondToAuthChallengeWithContext(ctx, &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ChallengeName: out.ChallengeName,
+		ClientId:      aws.String(u.clientID),
+		Session:       out.Session,
+		ChallengeResponses: map[string]*string{
+			"USERNAME":                    aws.String(userIDForSRP),
+			"PASSWORD_CLAIM_SECRET_BLOCK": aws.String(params["SECRET_BLOCK"]),
+			"PASSWORD_CLAIM_SIGNATURE":    aws.String(signature),
+			"TIMESTAMP":                   aws.String(timestamp),
+		},
+	})
+	if err != nil {
+		return nil, categorizeLoginError(err)
+	}
+
+	step := &cognitoAuthStep{
+		challengeName: aws.StringValue(resp.ChallengeName),
+		session:       resp.Session,
+		parameters:    aws.StringValueMap(resp.ChallengeParameters),
+		result:        resp.AuthenticationResult,
+	}
+	if step.parameters["USER_ID_FOR_SRP"] == "" {
+		step.parameters["USER_ID_FOR_SRP"] = userIDForSRP
+	}
+	return step, nil
+}
+
+// categorizeLoginError - ユーザーの存在有無が分からないよう、存在しないユーザーとパスワード誤りを区別しない
+func categorizeLoginError(err error) *domain.AuthError {
+	if isCognitoErrorCode(err,
+		cognitoidentityprovider.ErrCodeNotAuthorizedException,
+		cognitoidentityprovider.ErrCodeUserNotFoundException,
+	) {
+		return domain.NewAuthError(domain.AuthErrorTypeSecurity, "ログインに失敗しました", fmt.Errorf("%w: %w", domain.ErrInvalidCredentials, err))
+	}
+	return categorizeCognitoError(err, "ログインに失敗しました")
+}
+
+func unsupportedChallengeError(challengeName *string) *domain.AuthError {
+	return domain.NewAuthError(domain.AuthErrorTypeSecurity, fmt.Sprintf("未対応の認証チャレンジです: %s", aws.StringValue(challengeName)), nil)
+}
+
+// completePasswordLogin - Cognitoの認証結果からトークンを取り出し、ユーザーを保存する
+func (u *authUsecase) completePasswordLogin(ctx context.Context, result *cognitoidentityprovider.AuthenticationResultType) (*domain.AuthTokens, *domain.User, error) {
+	if result == nil {
+		return nil, nil, domain.NewAuthError(domain.AuthErrorTypeParse, "認証結果がありません", nil)
+	}
+
+	tokens := &domain.AuthTokens{
+		AccessToken:  aws.StringValue(result.AccessToken),
+		RefreshToken: aws.StringValue(result.RefreshToken),
+		IdToken:      aws.StringValue(result.IdToken),
+		ExpiresIn:    int(aws.Int64Value(result.ExpiresIn)),
+	}
+
+	// InitiateAuthのアクセストークンはopenidスコープを持たず、userInfoエンドポイントを呼べない
+	userInfo, err := u.parseIDToken(ctx, tokens, "", false)
+	if err != nil {
+		log.Printf("ERROR: ID token verification failed: %v", err)
+		return nil, nil, err
+	}
+
+	provider := getString(userInfo, "provider")
+	if err := u.restorePendingDeletion(ctx, provider, userInfo); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := u.saveUser(ctx, provider, userInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
+
+func newCodeDeliveryDetails(details *cognitoidentityprovider.CodeDeliveryDetailsType) *domain.CodeDeliveryDetails {
+	if details == nil {
+		return nil
+	}
+	return &domain.CodeDeliveryDetails{
+		Destination:    aws.StringValue(details.Destination),
+		DeliveryMedium: aws.StringValue(details.DeliveryMedium),
+		AttributeName:  aws.StringValue(details.AttributeName),
+	}
+}
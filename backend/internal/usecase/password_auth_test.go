@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const testPassword = "P@ssw0rd-Example"
+
+// fakePasswordAuthClient - サインアップ・確認・ログインの呼び出しを記録し、err が設定されていれば返す
+type fakePasswordAuthClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	err      error
+	initiate *cognitoidentityprovider.InitiateAuthOutput
+	signUps  []*cognitoidentityprovider.SignUpInput
+	confirms []*cognitoidentityprovider.ConfirmSignUpInput
+	logins   []*cognitoidentityprovider.InitiateAuthInput
+}
+
+func (c *fakePasswordAuthClient) SignUpWithContext(ctx aws.Context, input *cognitoidentityprovider.SignUpInput, opts ...request.Option) (*cognitoidentityprovider.SignUpOutput, error) {
+	c.signUps = append(c.signUps, input)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cognitoidentityprovider.SignUpOutput{
+		UserSub:       aws.String("new-sub"),
+		UserConfirmed: aws.Bool(false),
+		CodeDeliveryDetails: &cognitoidentityprovider.CodeDeliveryDetailsType{
+			AttributeName:  aws.String("email"),
+			DeliveryMedium: aws.String("EMAIL"),
+			Destination:    aws.String("a***@example.com"),
+		},
+	}, nil
+}
+
+func (c *fakePasswordAuthClient) ConfirmSignUpWithContext(ctx aws.Context, input *cognitoidentityprovider.ConfirmSignUpInput, opts ...request.Option) (*cognitoidentityprovider.ConfirmSignUpOutput, error) {
+	c.confirms = append(c.confirms, input)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cognitoidentityprovider.ConfirmSignUpOutput{}, nil
+}
+
+func (c *fakePasswordAuthClient) InitiateAuthWithContext(ctx aws.Context, input *cognitoidentityprovider.InitiateAuthInput, opts ...request.Option) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	c.logins = append(c.logins, input)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.initiate, nil
+}
+
+func newPasswordAuthTestUsecase(client *fakePasswordAuthClient) *authUsecase {
+	return &authUsecase{
+		cognitoClient:  client,
+		clientID:       "client-id",
+		jwtSecret:      testJWTSecret,
+		passwordPolicy: domain.DefaultPasswordPolicy(),
+	}
+}
+
+// assertAuthError - 種類とユーザー向けメッセージを確認する
+func assertAuthError(t *testing.T, err error, wantType domain.AuthErrorType, wantMessage string) *domain.AuthError {
+	t.Helper()
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("expected *domain.AuthError, got %v", err)
+	}
+	if authErr.Type != wantType {
+		t.Errorf("type = %s, want %s (%v)", authErr.Type, wantType, err)
+	}
+	if got := authErr.UserMessage(); got != wantMessage {
+		t.Errorf("UserMessage() = %q, want %q", got, wantMessage)
+	}
+	return authErr
+}
+
+func TestSignUp(t *testing.T) {
+	client := &fakePasswordAuthClient{}
+	u := newPasswordAuthTestUsecase(client)
+
+	result, err := u.SignUp(context.Background(), "alice@example.com", testPassword, "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.UserSub != "new-sub" || result.UserConfirmed || result.CodeDelivery == nil || result.CodeDelivery.DeliveryMedium != "EMAIL" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	// メールアドレスをユーザー名として登録する
+	input := client.signUps[0]
+	if aws.StringValue(input.Username) != "alice@example.com" || aws.StringValue(input.ClientId) != "client-id" || len(input.UserAttributes) != 2 {
+		t.Errorf("unexpected sign up input: %v", input)
+	}
+}
+
+func TestSignUp_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		err         error
+		wantType    domain.AuthErrorType
+		wantMessage string
+		wantCalls   int
+	}{
+		{
+			name:        "登録済みのメールアドレス",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeUsernameExistsException, "exists", nil),
+			wantType:    domain.AuthErrorTypeConflict,
+			wantMessage: "このメールアドレスは既に登録されています",
+			wantCalls:   1,
+		},
+		{
+			name:        "Cognitoがパスワードを拒否",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeInvalidPasswordException, "invalid password", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "パスワードが要件を満たしていません",
+			wantCalls:   1,
+		},
+		{
+			name:        "ポリシーを満たさないパスワードはCognitoに送らない",
+			password:    "password",
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "パスワードが要件を満たしていません",
+			wantCalls:   0,
+		},
+		{
+			name:        "リクエストが多すぎる",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "throttled", nil),
+			wantType:    domain.AuthErrorTypeRateLimit,
+			wantMessage: "試行回数が多すぎます。しばらくしてから再度お試しください",
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePasswordAuthClient{err: tt.err}
+			u := newPasswordAuthTestUsecase(client)
+
+			_, err := u.SignUp(context.Background(), "alice@example.com", tt.password, "")
+			assertAuthError(t, err, tt.wantType, tt.wantMessage)
+			if len(client.signUps) != tt.wantCalls {
+				t.Errorf("SignUp calls = %d, want %d", len(client.signUps), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSignUp_RejectedPasswordViolations(t *testing.T) {
+	client := &fakePasswordAuthClient{err: awserr.New(cognitoidentityprovider.ErrCodeInvalidPasswordException, "previously used", nil)}
+	u := newPasswordAuthTestUsecase(client)
+
+	_, err := u.SignUp(context.Background(), "alice@example.com", testPassword, "")
+
+	// ローカルのポリシーは満たすため、違反は rejected の1件になる
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) || len(policyErr.Violations) != 1 || policyErr.Violations[0].Code != domain.PasswordViolationRejected {
+		t.Fatalf("expected a rejected violation, got %v", err)
+	}
+}
+
+func TestConfirmSignUp(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantType    domain.AuthErrorType
+		wantMessage string
+	}{
+		{
+			name:        "コードの不一致",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeCodeMismatchException, "mismatch", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "確認コードが正しくありません",
+		},
+		{
+			name:        "コードの期限切れ",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeExpiredCodeException, "expired", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "確認コードの有効期限が切れました。コードを再送信してください",
+		},
+		{
+			name:        "存在しないユーザー",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "not found", nil),
+			wantType:    domain.AuthErrorTypeNotFound,
+			wantMessage: "対象が見つかりません",
+		},
+		{
+			name:        "確認済みのユーザー",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "already confirmed", nil),
+			wantType:    domain.AuthErrorTypeSecurity,
+			wantMessage: "セキュリティエラーが発生しました",
+		},
+		{
+			name:        "誤りが続いた",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException, "too many", nil),
+			wantType:    domain.AuthErrorTypeRateLimit,
+			wantMessage: "試行回数が多すぎます。しばらくしてから再度お試しください",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePasswordAuthClient{err: tt.err}
+			u := newPasswordAuthTestUsecase(client)
+
+			err := u.ConfirmSignUp(context.Background(), "alice@example.com", "123456")
+			assertAuthError(t, err, tt.wantType, tt.wantMessage)
+		})
+	}
+
+	t.Run("成功", func(t *testing.T) {
+		client := &fakePasswordAuthClient{}
+		u := newPasswordAuthTestUsecase(client)
+
+		if err := u.ConfirmSignUp(context.Background(), "alice@example.com", "123456"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := aws.StringValue(client.confirms[0].ConfirmationCode); got != "123456" {
+			t.Errorf("ConfirmationCode = %q", got)
+		}
+	})
+}
+
+func TestLoginWithPassword_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantType    domain.AuthErrorType
+		wantMessage string
+	}{
+		// 存在しないユーザーとパスワードの誤りは区別しない
+		{
+			name:        "パスワードの誤り",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeNotAuthorizedException, "incorrect username or password", nil),
+			wantType:    domain.AuthErrorTypeSecurity,
+			wantMessage: "メールアドレスまたはパスワードが正しくありません",
+		},
+		{
+			name:        "存在しないユーザー",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "not found", nil),
+			wantType:    domain.AuthErrorTypeSecurity,
+			wantMessage: "メールアドレスまたはパスワードが正しくありません",
+		},
+		{
+			name:        "未確認のユーザー",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeUserNotConfirmedException, "not confirmed", nil),
+			wantType:    domain.AuthErrorTypeSecurity,
+			wantMessage: "メールアドレスの確認が完了していません",
+		},
+		{
+			name:        "パスワードのリセットが必要",
+			err:         awserr.New(cognitoidentityprovider.ErrCodePasswordResetRequiredException, "reset required", nil),
+			wantType:    domain.AuthErrorTypeSecurity,
+			wantMessage: "パスワードのリセットが必要です",
+		},
+		{
+			name:        "リクエストが多すぎる",
+			err:         awserr.New(cognitoidentityprovider.ErrCodeTooManyRequestsException, "throttled", nil),
+			wantType:    domain.AuthErrorTypeRateLimit,
+			wantMessage: "試行回数が多すぎます。しばらくしてから再度お試しください",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePasswordAuthClient{err: tt.err}
+			u := newPasswordAuthTestUsecase(client)
+
+			tokens, user, challenge, err := u.LoginWithPassword(context.Background(), "alice@example.com", testPassword, domain.PasswordLoginModePassword)
+			assertAuthError(t, err, tt.wantType, tt.wantMessage)
+			if tokens != nil || user != nil || challenge != nil {
+				t.Errorf("nothing should be returned on error: %v %v %v", tokens, user, challenge)
+			}
+		})
+	}
+}
+
+func TestLoginWithPassword_Challenge(t *testing.T) {
+	client := &fakePasswordAuthClient{initiate: &cognitoidentityprovider.InitiateAuthOutput{
+		ChallengeName:       aws.String(cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa),
+		Session:             aws.String("cognito-session"),
+		ChallengeParameters: map[string]*string{"USER_ID_FOR_SRP": aws.String("internal-user")},
+	}}
+	u := newPasswordAuthTestUsecase(client)
+
+	tokens, _, challenge, err := u.LoginWithPassword(context.Background(), "alice@example.com", testPassword, domain.PasswordLoginModePassword)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tokens != nil || challenge == nil || challenge.Name != domain.ChallengeSoftwareTokenMFA {
+		t.Fatalf("expected the MFA challenge, got tokens=%v challenge=%+v", tokens, challenge)
+	}
+
+	input := client.logins[0]
+	if aws.StringValue(input.AuthFlow) != cognitoidentityprovider.AuthFlowTypeUserPasswordAuth ||
+		aws.StringValue(input.AuthParameters["USERNAME"]) != "alice@example.com" ||
+		aws.StringValue(input.AuthParameters["PASSWORD"]) != testPassword {
+		t.Errorf("unexpected InitiateAuth input: %v", input)
+	}
+}
+
+func TestCategorizeCognitoError(t *testing.T) {
+	tests := []struct {
+		code        string
+		wantType    domain.AuthErrorType
+		wantMessage string
+	}{
+		{cognitoidentityprovider.ErrCodeUsernameExistsException, domain.AuthErrorTypeConflict, "このメールアドレスは既に登録されています"},
+		{cognitoidentityprovider.ErrCodeAliasExistsException, domain.AuthErrorTypeConflict, "このメールアドレスは既に登録されています"},
+		{cognitoidentityprovider.ErrCodeCodeMismatchException, domain.AuthErrorTypeValidation, "確認コードが正しくありません"},
+		{cognitoidentityprovider.ErrCodeExpiredCodeException, domain.AuthErrorTypeValidation, "確認コードの有効期限が切れました。コードを再送信してください"},
+		{cognitoidentityprovider.ErrCodeInvalidPasswordException, domain.AuthErrorTypeValidation, "パスワードが要件を満たしていません"},
+		{cognitoidentityprovider.ErrCodeNotAuthorizedException, domain.AuthErrorTypeSecurity, "セキュリティエラーが発生しました"},
+		{cognitoidentityprovider.ErrCodeUserNotConfirmedException, domain.AuthErrorTypeSecurity, "メールアドレスの確認が完了していません"},
+		{cognitoidentityprovider.ErrCodePasswordResetRequiredException, domain.AuthErrorTypeSecurity, "パスワードのリセットが必要です"},
+		{cognitoidentityprovider.ErrCodeUserNotFoundException, domain.AuthErrorTypeNotFound, "対象が見つかりません"},
+		{cognitoidentityprovider.ErrCodeTooManyRequestsException, domain.AuthErrorTypeRateLimit, "試行回数が多すぎます。しばらくしてから再度お試しください"},
+		{cognitoidentityprovider.ErrCodeLimitExceededException, domain.AuthErrorTypeRateLimit, "試行回数が多すぎます。しばらくしてから再度お試しください"},
+		{cognitoidentityprovider.ErrCodeTooManyFailedAttemptsException, domain.AuthErrorTypeRateLimit, "試行回数が多すぎます。しばらくしてから再度お試しください"},
+		{cognitoidentityprovider.ErrCodeCodeDeliveryFailureException, domain.AuthErrorTypeServer, "確認コードを送信できませんでした"},
+		{cognitoidentityprovider.ErrCodeInvalidParameterException, domain.AuthErrorTypeClient, "リクエストが正しくありません"},
+		{cognitoidentityprovider.ErrCodeResourceNotFoundException, domain.AuthErrorTypeConfig, "システム設定エラーが発生しました"},
+		{cognitoidentityprovider.ErrCodeInternalErrorException, domain.AuthErrorTypeServer, "認証サーバーエラーが発生しました"},
+		{"UnknownException", domain.AuthErrorTypeServer, "認証サーバーエラーが発生しました"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			cause := awserr.New(tt.code, "cognito error", nil)
+			authErr := assertAuthError(t, categorizeCognitoError(cause, "失敗しました"), tt.wantType, tt.wantMessage)
+			if !errors.Is(authErr, cause) {
+				t.Errorf("the Cognito error should be kept as the cause: %v", authErr)
+			}
+		})
+	}
+
+	// AWSのエラーでない場合は通信エラーとして扱う
+	assertAuthError(t, categorizeCognitoError(errors.New("dial tcp: timeout"), "失敗しました"), domain.AuthErrorTypeNetwork, "ネットワーク接続に失敗しました")
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	// passwordResetLimit - メールアドレスごとにウィンドウ内で許可するリクエスト数
+	passwordResetLimit  = 5
+	passwordResetWindow = 15 * time.Minute
+)
+
+// ForgotPassword - パスワード再設定用の確認コードを送信させる
+// アカウントの存在有無が分からないよう、ユーザーが存在しない場合も成功として扱う
+func (u *authUsecase) ForgotPassword(ctx context.Context, email string) error {
+	if err := u.checkRateLimit(ctx, "password_forgot", email, passwordResetLimit, passwordResetWindow); err != nil {
+		return err
+	}
+
+	_, err := u.cognitoClient.ForgotPasswordWithContext(ctx, &cognitoidentityprovider.ForgotPasswordInput{
+		ClientId: aws.String(u.clientID),
+		Username: aws.String(email),
+	})
+	if err != nil {
+		// 存在しない・未確認・検証済みメールがないユーザー、およびコード送信失敗は応答から区別できないようにする
+		if isCognitoErrorCode(err,
+			cognitoidentityprovider.ErrCodeUserNotFoundException,
+			cognitoidentityprovider.ErrCodeNotAuthorizedException,
+			cognitoidentityprovider.ErrCodeInvalidParameterException,
+			cognitoidentityprovider.ErrCodeCodeDeliveryFailureException,
+		) {
+			log.Printf("WARN: Forgot password request was not fulfilled: %v", err)
+			return nil
+		}
+		return categorizeCognitoError(err, "パスワード再設定コードの送信に失敗しました")
+	}
+
+	return nil
+}
+
+// ResetPassword - 確認コードと新しいパスワードでパスワードを再設定する
+func (u *authUsecase) ResetPassword(ctx context.Context, email, code, newPassword string) error {
+	if err := u.checkRateLimit(ctx, "password_reset", email, passwordResetLimit, passwordResetWindow); err != nil {
+		return err
+	}
+
+	if err := u.passwordPolicy.Check(newPassword); err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeValidation, "パスワードがポリシーを満たしていません", err)
+	}
+
+	_, err := u.cognitoClient.ConfirmForgotPasswordWithContext(ctx, &cognitoidentityprovider.ConfirmForgotPasswordInput{
+		ClientId:         aws.String(u.clientID),
+		Username:         aws.String(email),
+		ConfirmationCode: aws.String(code),
+		Password:         aws.String(newPassword),
+	})
+	if err != nil {
+		switch {
+		case isCognitoErrorCode(err,
+			cognitoidentityprovider.ErrCodeUserNotFoundException,
+			cognitoidentityprovider.ErrCodeNotAuthorizedException,
+		):
+			// 存在しないユーザーはコードの不一致と同じ応答にする
+			return domain.NewAuthError(domain.AuthErrorTypeValidation, "パスワードの再設定に失敗しました", fmt.Errorf("%w: %w", domain.ErrCodeMismatch, err))
+		case isCognitoErrorCode(err, cognitoidentityprovider.ErrCodeInvalidPasswordException):
+			return u.passwordRejectedError(newPassword, err)
+		}
+		return categorizeCognitoError(err, "パスワードの再設定に失敗しました")
+	}
+
+	return nil
+}
+
+// passwordRejectedError - CognitoのInvalidPasswordExceptionを要件ごとの違反に変換する
+// ローカルのポリシーをすべて満たす場合（過去のパスワードの再利用など）は rejected として返す
+func (u *authUsecase) passwordRejectedError(password string, err error) *domain.AuthError {
+	violations := u.passwordPolicy.Validate(password)
+	if len(violations) == 0 {
+		violations = []domain.PasswordPolicyViolation{{
+			Code:    domain.PasswordViolationRejected,
+			Message: "このパスワードは使用できません",
+		}}
+	}
+
+	log.Printf("WARN: Password rejected by Cognito: %v", err)
+	return domain.NewAuthError(domain.AuthErrorTypeValidation, "パスワードがポリシーを満たしていません", &domain.PasswordPolicyError{Violations: violations})
+}
+
+// checkRateLimit - scopeとメールアドレスごとのリクエスト数を制限する
+// メールアドレスはハッシュ化してキーにする
+func (u *authUsecase) checkRateLimit(ctx context.Context, scope, email string, limit int, window time.Duration) error {
+	sum := sha256.Sum256([]byte(email))
+	key := scope + ":" + hex.EncodeToString(sum[:])
+
+	allowed, retryAfter, err := u.rateLimitRepo.Allow(ctx, key, limit, window)
+	if err != nil {
+		return domain.NewAuthError(domain.AuthErrorTypeServer, "レート制限の確認に失敗しました", err)
+	}
+	if !allowed {
+		return domain.NewAuthError(domain.AuthErrorTypeRateLimit, fmt.Sprintf("リクエストが多すぎます（%s後に再試行可能）", retryAfter.Round(time.Second)), domain.ErrTooManyAttempts)
+	}
+	return nil
+}
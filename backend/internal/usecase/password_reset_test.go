@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+// fakeRateLimitRepository - キーごとの回数をメモリで数える（ウィンドウは考慮しない）
+type fakeRateLimitRepository struct {
+	repository.IRateLimitRepository
+	counts map[string]int
+}
+
+func (r *fakeRateLimitRepository) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	r.counts[key]++
+	if r.counts[key] > limit {
+		return false, window, nil
+	}
+	return true, 0, nil
+}
+
+// fakePasswordResetClient - パスワード再設定の呼び出し回数を記録し、err が設定されていれば返す
+type fakePasswordResetClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	err      error
+	forgot   int
+	confirms int
+}
+
+func (c *fakePasswordResetClient) ForgotPasswordWithContext(ctx aws.Context, input *cognitoidentityprovider.ForgotPasswordInput, opts ...request.Option) (*cognitoidentityprovider.ForgotPasswordOutput, error) {
+	c.forgot++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cognitoidentityprovider.ForgotPasswordOutput{}, nil
+}
+
+func (c *fakePasswordResetClient) ConfirmForgotPasswordWithContext(ctx aws.Context, input *cognitoidentityprovider.ConfirmForgotPasswordInput, opts ...request.Option) (*cognitoidentityprovider.ConfirmForgotPasswordOutput, error) {
+	c.confirms++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &cognitoidentityprovider.ConfirmForgotPasswordOutput{}, nil
+}
+
+func newPasswordResetTestUsecase(client *fakePasswordResetClient) *authUsecase {
+	return &authUsecase{
+		cognitoClient:  client,
+		rateLimitRepo:  &fakeRateLimitRepository{counts: make(map[string]int)},
+		clientID:       "client-id",
+		passwordPolicy: domain.DefaultPasswordPolicy(),
+	}
+}
+
+func TestForgotPassword_HidesAccountState(t *testing.T) {
+	// いずれもアカウントの有無・状態が応答から分からないよう成功として扱う
+	for _, code := range []string{
+		cognitoidentityprovider.ErrCodeUserNotFoundException,
+		cognitoidentityprovider.ErrCodeNotAuthorizedException,
+		cognitoidentityprovider.ErrCodeInvalidParameterException,
+		cognitoidentityprovider.ErrCodeCodeDeliveryFailureException,
+	} {
+		t.Run(code, func(t *testing.T) {
+			client := &fakePasswordResetClient{err: awserr.New(code, "cognito error", nil)}
+			u := newPasswordResetTestUsecase(client)
+
+			if err := u.ForgotPassword(context.Background(), "alice@example.com"); err != nil {
+				t.Errorf("expected nil, got %v", err)
+			}
+			if client.forgot != 1 {
+				t.Errorf("ForgotPassword calls = %d, want 1", client.forgot)
+			}
+		})
+	}
+
+	t.Run("その他のエラーは返す", func(t *testing.T) {
+		client := &fakePasswordResetClient{err: awserr.New(cognitoidentityprovider.ErrCodeLimitExceededException, "limit", nil)}
+		u := newPasswordResetTestUsecase(client)
+
+		err := u.ForgotPassword(context.Background(), "alice@example.com")
+		assertAuthError(t, err, domain.AuthErrorTypeRateLimit, "試行回数が多すぎます。しばらくしてから再度お試しください")
+	})
+}
+
+func TestResetPassword_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		err         error
+		wantType    domain.AuthErrorType
+		wantMessage string
+		wantCode    error
+	}{
+		{
+			name:        "コードの不一致",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeCodeMismatchException, "mismatch", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "確認コードが正しくありません",
+			wantCode:    domain.ErrCodeMismatch,
+		},
+		{
+			// 存在しないユーザーはコードの不一致と区別しない
+			name:        "存在しないユーザー",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeUserNotFoundException, "not found", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "確認コードが正しくありません",
+			wantCode:    domain.ErrCodeMismatch,
+		},
+		{
+			name:        "コードの期限切れ",
+			password:    testPassword,
+			err:         awserr.New(cognitoidentityprovider.ErrCodeExpiredCodeException, "expired", nil),
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "確認コードの有効期限が切れました。コードを再送信してください",
+			wantCode:    domain.ErrCodeExpired,
+		},
+		{
+			name:        "ポリシーを満たさないパスワード",
+			password:    "password",
+			wantType:    domain.AuthErrorTypeValidation,
+			wantMessage: "パスワードが要件を満たしていません",
+			wantCode:    domain.ErrInvalidPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePasswordResetClient{err: tt.err}
+			u := newPasswordResetTestUsecase(client)
+
+			err := u.ResetPassword(context.Background(), "alice@example.com", "123456", tt.password)
+			assertAuthError(t, err, tt.wantType, tt.wantMessage)
+			if !errors.Is(err, tt.wantCode) {
+				t.Errorf("expected %v, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestPasswordReset_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	client := &fakePasswordResetClient{}
+	u := newPasswordResetTestUsecase(client)
+
+	for i := 0; i < passwordResetLimit; i++ {
+		if err := u.ForgotPassword(ctx, "alice@example.com"); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i+1, err)
+		}
+	}
+
+	// 上限を超えたリクエストはCognitoに送らない
+	err := u.ForgotPassword(ctx, "alice@example.com")
+	assertAuthError(t, err, domain.AuthErrorTypeRateLimit, "試行回数が多すぎます。しばらくしてから再度お試しください")
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("expected ErrTooManyAttempts, got %v", err)
+	}
+	if client.forgot != passwordResetLimit {
+		t.Errorf("ForgotPassword calls = %d, want %d", client.forgot, passwordResetLimit)
+	}
+
+	// 上限はメールアドレスごと・操作ごとに数える
+	if err := u.ForgotPassword(ctx, "bob@example.com"); err != nil {
+		t.Errorf("another email should not be limited: %v", err)
+	}
+	if err := u.ResetPassword(ctx, "alice@example.com", "123456", testPassword); err != nil {
+		t.Errorf("reset should have its own limit: %v", err)
+	}
+}
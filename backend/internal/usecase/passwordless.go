@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	// passwordlessStartLimit - メールアドレスごとにウィンドウ内で許可するコード送信の回数
+	passwordlessStartLimit  = 5
+	passwordlessStartWindow = 15 * time.Minute
+)
+
+// StartPasswordlessLogin - CUSTOM_AUTHを開始し、メールでワンタイムコードを送信させる
+// コードの生成・送信・検証はユーザープールのトリガー（cmd/lambda/*-auth-challenge）が行う
+func (u *authUsecase) StartPasswordlessLogin(ctx context.Context, email string) (*domain.AuthChallenge, error) {
+	if err := u.checkRateLimit(ctx, "passwordless_start", email, passwordlessStartLimit, passwordlessStartWindow); err != nil {
+		return nil, err
+	}
+
+	out, err := u.cognitoClient.InitiateAuthWithContext(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: aws.String(cognitoidentityprovider.AuthFlowTypeCustomAuth),
+		ClientId: aws.String(u.clientID),
+		AuthParameters: map[string]*string{
+			"USERNAME": aws.String(email),
+		},
+	})
+	if err != nil {
+		return nil, categorizeLoginError(err)
+	}
+
+	if aws.StringValue(out.ChallengeName) != domain.ChallengeCustomChallenge {
+		return nil, unsupportedChallengeError(out.ChallengeName)
+	}
+
+	return u.newChallenge(&cognitoAuthStep{
+		challengeName: aws.StringValue(out.ChallengeName),
+		session:       out.Session,
+		parameters:    aws.StringValueMap(out.ChallengeParameters),
+	}, email)
+}
+
+// AnswerPasswordlessLogin - メールで受け取ったコードでログインする
+// コードが誤っていた場合は試行回数の上限まで新しいチャレンジ（同じコード）が返される
+func (u *authUsecase) AnswerPasswordlessLogin(ctx context.Context, session, code string) (*domain.AuthTokens, *domain.User, *domain.AuthChallenge, error) {
+	challengeSession, err := u.openChallengeSession(session)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if challengeSession.Name != domain.ChallengeCustomChallenge {
+		return nil, nil, nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "パスワードレスログインのセッションではありません", domain.ErrChallengeSessionInvalid)
+	}
+
+	return u.RespondToChallenge(ctx, session, map[string]string{"ANSWER": code})
+}
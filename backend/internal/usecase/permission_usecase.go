@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+// maxPermissionCacheEntries - これを超えたら期限切れのエントリを掃除する
+const maxPermissionCacheEntries = 10000
+
+type IPermissionUsecase interface {
+	GetPermissions(ctx context.Context, claims *domain.UserClaims) (*domain.PermissionSet, error)
+	HasPermission(ctx context.Context, claims *domain.UserClaims, permission string) (bool, error)
+	HasAnyRole(ctx context.Context, claims *domain.UserClaims, roles ...string) (bool, error)
+	InvalidatePermissions(sub string)
+}
+
+// cachedPermissionSet - subごとのキャッシュ。roles はキャッシュしたときのトークンの実効ロール
+type cachedPermissionSet struct {
+	roles     string
+	set       *domain.PermissionSet
+	expiresAt time.Time
+}
+
+type permissionUsecase struct {
+	userRepo       repository.IUserRepository
+	permissionRepo repository.IPermissionRepository
+	hierarchy      domain.RoleHierarchy
+	cacheTTL       time.Duration
+	now            func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedPermissionSet
+}
+
+func NewPermissionUsecase(
+	userRepo repository.IUserRepository,
+	permissionRepo repository.IPermissionRepository,
+	hierarchy domain.RoleHierarchy,
+	cacheTTL time.Duration,
+) *permissionUsecase {
+	return &permissionUsecase{
+		userRepo:       userRepo,
+		permissionRepo: permissionRepo,
+		hierarchy:      hierarchy,
+		cacheTTL:       cacheTTL,
+		now:            time.Now,
+		cache:          make(map[string]cachedPermissionSet),
+	}
+}
+
+// GetPermissions - ユーザーの実効ロールと実効権限
+// Cognitoのグループとローカルで割り当てたロールをロール階層で展開し、それらと同名のロールの権限を合わせる
+// トークンのロールが変わった場合はすぐに、ローカルのロールの割り当て変更は cacheTTL 以内に反映される
+func (u *permissionUsecase) GetPermissions(ctx context.Context, claims *domain.UserClaims) (*domain.PermissionSet, error) {
+	if claims == nil || claims.Sub == "" {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "認証情報がありません", nil)
+	}
+
+	tokenRoles := strings.Join(claims.Roles, ",")
+	if set, ok := u.cached(claims.Sub, tokenRoles); ok {
+		return set, nil
+	}
+
+	user, err := u.userRepo.GetUserBySubjectID(ctx, claims.Sub)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+	}
+
+	// ローカルのユーザーがまだない場合はCognitoのロール由来の権限のみ
+	var userID uint
+	roles := append([]string(nil), claims.Roles...)
+	if user != nil {
+		userID = user.ID
+		localRoles, err := u.permissionRepo.ListRoleNames(ctx, userID)
+		if err != nil {
+			return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ロールの取得に失敗しました", err)
+		}
+		roles = append(roles, localRoles...)
+	}
+
+	roles = u.hierarchy.EffectiveRoles(roles)
+
+	permissions, err := u.permissionRepo.ListPermissionNames(ctx, userID, roles)
+	if err != nil {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "権限の取得に失敗しました", err)
+	}
+
+	set := &domain.PermissionSet{
+		Roles:       roles,
+		Permissions: permissions,
+	}
+	u.store(claims.Sub, tokenRoles, set)
+	return set, nil
+}
+
+// HasPermission - ユーザーがpermissionを持つか
+func (u *permissionUsecase) HasPermission(ctx context.Context, claims *domain.UserClaims, permission string) (bool, error) {
+	set, err := u.GetPermissions(ctx, claims)
+	if err != nil {
+		return false, err
+	}
+	return set.Allows(permission), nil
+}
+
+// HasAnyRole - ユーザーの実効ロール（ローカルのロールを含む）にrolesのいずれかが含まれるか
+func (u *permissionUsecase) HasAnyRole(ctx context.Context, claims *domain.UserClaims, roles ...string) (bool, error) {
+	set, err := u.GetPermissions(ctx, claims)
+	if err != nil {
+		return false, err
+	}
+	return set.HasAnyRole(roles...), nil
+}
+
+// InvalidatePermissions - subのキャッシュを破棄し、次の確認でロールと権限を取得し直す
+func (u *permissionUsecase) InvalidatePermissions(sub string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.cache, sub)
+}
+
+// cached - キャッシュした権限（トークンのロールが変わっていれば使わない）
+func (u *permissionUsecase) cached(sub, tokenRoles string) (*domain.PermissionSet, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.cache[sub]
+	if !ok || entry.roles != tokenRoles || u.now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.set, true
+}
+
+func (u *permissionUsecase) store(sub, tokenRoles string, set *domain.PermissionSet) {
+	if u.cacheTTL <= 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+	if len(u.cache) >= maxPermissionCacheEntries {
+		for k, entry := range u.cache {
+			if now.After(entry.expiresAt) {
+				delete(u.cache, k)
+			}
+		}
+	}
+	u.cache[sub] = cachedPermissionSet{roles: tokenRoles, set: set, expiresAt: now.Add(u.cacheTTL)}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+type fakePermissionRepository struct {
+	repository.IPermissionRepository
+	localRoles map[uint][]string
+	// rolePermissions - ロール名ごとの権限
+	rolePermissions map[string][]string
+	queries         int
+	queriedRoles    []string
+}
+
+func (r *fakePermissionRepository) ListRoleNames(ctx context.Context, userID uint) ([]string, error) {
+	return r.localRoles[userID], nil
+}
+
+func (r *fakePermissionRepository) ListPermissionNames(ctx context.Context, userID uint, roleNames []string) ([]string, error) {
+	r.queries++
+	r.queriedRoles = roleNames
+	var permissions []string
+	for _, role := range roleNames {
+		permissions = append(permissions, r.rolePermissions[role]...)
+	}
+	return permissions, nil
+}
+
+// testClock - キャッシュの有効期限を確認するための時計
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newPermissionTestUsecase(cacheTTL time.Duration) (*permissionUsecase, *fakePermissionRepository, *testClock) {
+	userRepo := &fakeUserRepository{users: map[string]*domain.User{
+		"sub-1": {ID: 1},
+	}}
+	permissionRepo := &fakePermissionRepository{
+		localRoles: map[uint][]string{1: {"admin"}},
+		rolePermissions: map[string][]string{
+			"admin":  {domain.PermissionUsersManage},
+			"member": {"profile:read"},
+		},
+	}
+	clock := &testClock{now: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}
+
+	u := NewPermissionUsecase(userRepo, permissionRepo, domain.RoleHierarchy{"admin": {"member"}}, cacheTTL)
+	u.now = clock.Now
+	return u, permissionRepo, clock
+}
+
+func TestGetPermissions_ExpandsLocalRoles(t *testing.T) {
+	u, repo, _ := newPermissionTestUsecase(time.Minute)
+	claims := &domain.UserClaims{Sub: "sub-1"}
+
+	set, err := u.GetPermissions(context.Background(), claims)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// ローカルで割り当てたadminも、Cognitoのグループと同じくロール階層で展開する
+	if want := []string{"admin", "member"}; !reflect.DeepEqual(set.Roles, want) || !reflect.DeepEqual(repo.queriedRoles, want) {
+		t.Errorf("roles = %v (queried %v), want %v", set.Roles, repo.queriedRoles, want)
+	}
+	if !set.Allows("profile:read") || !set.Allows(domain.PermissionUsersManage) {
+		t.Errorf("inherited role permissions should apply: %v", set.Permissions)
+	}
+
+	ok, err := u.HasAnyRole(context.Background(), claims, "member")
+	if err != nil || !ok {
+		t.Errorf("local admin should satisfy member: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestGetPermissions_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hit", func(t *testing.T) {
+		u, repo, clock := newPermissionTestUsecase(time.Minute)
+		claims := &domain.UserClaims{Sub: "sub-1", Roles: []string{"member"}}
+
+		first, _ := u.GetPermissions(ctx, claims)
+		clock.now = clock.now.Add(59 * time.Second)
+		second, _ := u.GetPermissions(ctx, claims)
+
+		if repo.queries != 1 || first != second {
+			t.Errorf("second call should be served from the cache, queries = %d", repo.queries)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		u, repo, clock := newPermissionTestUsecase(time.Minute)
+		claims := &domain.UserClaims{Sub: "sub-1"}
+
+		u.GetPermissions(ctx, claims)
+		repo.localRoles[1] = nil
+		clock.now = clock.now.Add(time.Minute + time.Second)
+
+		set, _ := u.GetPermissions(ctx, claims)
+		if repo.queries != 2 {
+			t.Fatalf("expired entry should be reloaded, queries = %d", repo.queries)
+		}
+		if set.HasAnyRole("admin") {
+			t.Errorf("revoked local role should no longer apply: %v", set.Roles)
+		}
+	})
+
+	t.Run("token role change", func(t *testing.T) {
+		u, repo, _ := newPermissionTestUsecase(time.Minute)
+
+		u.GetPermissions(ctx, &domain.UserClaims{Sub: "sub-1", Roles: []string{"member"}})
+		set, _ := u.GetPermissions(ctx, &domain.UserClaims{Sub: "sub-1", Roles: []string{"member", "support"}})
+
+		if repo.queries != 2 || !set.HasAnyRole("support") {
+			t.Errorf("new token roles should invalidate the cache: queries=%d roles=%v", repo.queries, set.Roles)
+		}
+		if len(u.cache) != 1 {
+			t.Errorf("stale entry should be replaced, cache has %d entries", len(u.cache))
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		u, repo, _ := newPermissionTestUsecase(time.Minute)
+		claims := &domain.UserClaims{Sub: "sub-1"}
+
+		u.GetPermissions(ctx, claims)
+		repo.localRoles[1] = nil
+		u.InvalidatePermissions("sub-1")
+
+		set, _ := u.GetPermissions(ctx, claims)
+		if repo.queries != 2 || set.HasAnyRole("admin") {
+			t.Errorf("invalidated entry should be reloaded: queries=%d roles=%v", repo.queries, set.Roles)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		u, repo, _ := newPermissionTestUsecase(0)
+		claims := &domain.UserClaims{Sub: "sub-1"}
+
+		u.GetPermissions(ctx, claims)
+		u.GetPermissions(ctx, claims)
+
+		if repo.queries != 2 {
+			t.Errorf("cache should be disabled, queries = %d", repo.queries)
+		}
+	})
+}
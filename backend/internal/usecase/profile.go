@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+const (
+	cognitoAttributeName        = "name"
+	cognitoAttributePicture     = "picture"
+	cognitoAttributeEmail       = "email"
+	cognitoAttributePhoneNumber = "phone_number"
+)
+
+// verifiableAttributes - 確認コードで検証できるCognitoの属性
+var verifiableAttributes = map[string]bool{
+	cognitoAttributeEmail:       true,
+	cognitoAttributePhoneNumber: true,
+}
+
+// GetProfile - ログイン中のユーザーのプロフィール
+func (u *accountUsecase) GetProfile(ctx context.Context, claims *domain.UserClaims) (*domain.User, error) {
+	return u.currentUser(ctx, claims)
+}
+
+// UpdateProfile - プロフィールを更新する
+// name・pictureはCognitoの属性にも反映し（アクセストークンが必要）、usernameはローカルのみで管理する
+// Cognitoを先に更新し、ローカルの保存に失敗した場合はCognitoを元の値に戻す
+func (u *accountUsecase) UpdateProfile(ctx context.Context, claims *domain.UserClaims, accessToken string, update domain.ProfileUpdate) (*domain.User, *domain.ProfileSyncResult, error) {
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if update.Username != nil && *update.Username != user.Username {
+		existing, err := u.userRepo.GetUserByUsername(ctx, *update.Username)
+		if err != nil {
+			return nil, nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+		}
+		if existing != nil {
+			return nil, nil, domain.NewAuthError(domain.AuthErrorTypeConflict, "ユーザー名は既に使用されています", domain.ErrUsernameConflict)
+		}
+	}
+
+	result := &domain.ProfileSyncResult{
+		Local:   domain.ProfileSyncSkipped,
+		Cognito: domain.ProfileSyncSkipped,
+	}
+
+	attributes := changedProfileAttributes(user, update)
+	var previous map[string]string
+	if len(attributes) > 0 {
+		if accessToken == "" {
+			return nil, nil, domain.NewAuthError(domain.AuthErrorTypeSecurity, "名前・画像の変更にはアクセストークンが必要です", nil)
+		}
+
+		previous, err = u.cognitoAttributes(ctx, accessToken, attributes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := u.applyCognitoAttributes(ctx, accessToken, attributes); err != nil {
+			return nil, nil, categorizeCognitoError(err, "プロフィールの更新に失敗しました")
+		}
+		result.Cognito = domain.ProfileSyncUpdated
+	}
+
+	if !applyProfileUpdate(user, update) {
+		return user, result, nil
+	}
+
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		result.Local = domain.ProfileSyncFailed
+		if result.Cognito == domain.ProfileSyncUpdated {
+			if rollbackErr := u.applyCognitoAttributes(ctx, accessToken, previous); rollbackErr != nil {
+				log.Printf("ERROR: Failed to roll back Cognito attributes for sub %s: %v", claims.Sub, rollbackErr)
+				result.Cognito = domain.ProfileSyncRollbackFailed
+			} else {
+				result.Cognito = domain.ProfileSyncRolledBack
+			}
+		}
+
+		syncErr := &domain.ProfileSyncError{Result: *result, Err: err}
+		if errors.Is(err, domain.ErrUsernameConflict) {
+			return nil, result, domain.NewAuthError(domain.AuthErrorTypeConflict, "ユーザー名は既に使用されています", syncErr)
+		}
+		return nil, result, domain.NewAuthError(domain.AuthErrorTypeServer, "プロフィールの保存に失敗しました", syncErr)
+	}
+	result.Local = domain.ProfileSyncUpdated
+
+	return user, result, nil
+}
+
+// VerifyAttribute - Cognitoの属性（email・phone_number）を変更・確認する
+// value を指定すると属性を変更して確認コードを送信し、code を指定すると確認を完了する
+// どちらもなければ確認コードを再送信する。確認済みのemailはローカルのユーザーにも反映する
+func (u *accountUsecase) VerifyAttribute(ctx context.Context, claims *domain.UserClaims, accessToken, attribute, value, code string) (*domain.AttributeVerification, error) {
+	if !verifiableAttributes[attribute] {
+		return nil, domain.NewAuthError(domain.AuthErrorTypeValidation, "確認できない属性です", nil)
+	}
+
+	user, err := u.currentUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &domain.AttributeVerification{Attribute: attribute}
+
+	switch {
+	case code != "":
+		_, err := u.cognitoClient.VerifyUserAttributeWithContext(ctx, &cognitoidentityprovider.VerifyUserAttributeInput{
+			AccessToken:   aws.String(accessToken),
+			AttributeName: aws.String(attribute),
+			Code:          aws.String(code),
+		})
+		if err != nil {
+			return nil, categorizeCognitoError(err, "属性の確認に失敗しました")
+		}
+		verification.Verified = true
+
+		sync, err := u.syncVerifiedAttribute(ctx, user, accessToken, attribute)
+		verification.Sync = sync
+		if err != nil {
+			return verification, err
+		}
+
+	case value != "":
+		if attribute == cognitoAttributeEmail {
+			existing, err := u.userRepo.GetUserByEmail(ctx, value)
+			if err != nil {
+				return nil, domain.NewAuthError(domain.AuthErrorTypeServer, "ユーザーの取得に失敗しました", err)
+			}
+			if existing != nil && existing.ID != user.ID {
+				return nil, domain.NewAuthError(domain.AuthErrorTypeConflict, "メールアドレスは別のアカウントで使用されています", domain.ErrUserEmailConflict)
+			}
+		}
+
+		out, err := u.cognitoClient.UpdateUserAttributesWithContext(ctx, &cognitoidentityprovider.UpdateUserAttributesInput{
+			AccessToken: aws.String(accessToken),
+			UserAttributes: []*cognitoidentityprovider.AttributeType{
+				{Name: aws.String(attribute), Value: aws.String(value)},
+			},
+		})
+		if err != nil {
+			return nil, categorizeCognitoError(err, "属性の変更に失敗しました")
+		}
+		if len(out.CodeDeliveryDetailsList) > 0 {
+			verification.CodeDelivery = newCodeDeliveryDetails(out.CodeDeliveryDetailsList[0])
+		}
+
+	default:
+		out, err := u.cognitoClient.GetUserAttributeVerificationCodeWithContext(ctx, &cognitoidentityprovider.GetUserAttributeVerificationCodeInput{
+			AccessToken:   aws.String(accessToken),
+			AttributeName: aws.String(attribute),
+		})
+		if err != nil {
+			return nil, categorizeCognitoError(err, "確認コードの送信に失敗しました")
+		}
+		verification.CodeDelivery = newCodeDeliveryDetails(out.CodeDeliveryDetails)
+	}
+
+	return verification, nil
+}
+
+// syncVerifiedAttribute - 確認済みになった属性をローカルのユーザーに反映する
+// Cognito側の確認は取り消せないため、ローカルの保存に失敗した場合は結果のみを返す
+func (u *accountUsecase) syncVerifiedAttribute(ctx context.Context, user *domain.User, accessToken, attribute string) (*domain.ProfileSyncResult, error) {
+	result := &domain.ProfileSyncResult{
+		Local:   domain.ProfileSyncSkipped,
+		Cognito: domain.ProfileSyncUpdated,
+	}
+	if attribute != cognitoAttributeEmail {
+		return result, nil
+	}
+
+	current, err := u.cognitoAttributes(ctx, accessToken, map[string]string{cognitoAttributeEmail: ""})
+	if err != nil {
+		result.Local = domain.ProfileSyncFailed
+		return result, err
+	}
+
+	email := current[cognitoAttributeEmail]
+	if email == "" || email == user.Email {
+		return result, nil
+	}
+
+	user.Email = email
+	if err := u.userRepo.UpdateUser(ctx, user); err != nil {
+		result.Local = domain.ProfileSyncFailed
+		syncErr := &domain.ProfileSyncError{Result: *result, Err: err}
+		if errors.Is(err, domain.ErrUserEmailConflict) {
+			return result, domain.NewAuthError(domain.AuthErrorTypeConflict, "メールアドレスは別のアカウントで使用されています", syncErr)
+		}
+		return result, domain.NewAuthError(domain.AuthErrorTypeServer, "メールアドレスの保存に失敗しました", syncErr)
+	}
+	result.Local = domain.ProfileSyncUpdated
+
+	return result, nil
+}
+
+// cognitoAttributes - namesに含まれる属性のCognito上の現在値（未設定は空文字）
+func (u *accountUsecase) cognitoAttributes(ctx context.Context, accessToken string, names map[string]string) (map[string]string, error) {
+	out, err := u.cognitoClient.GetUserWithContext(ctx, &cognitoidentityprovider.GetUserInput{
+		AccessToken: aws.String(accessToken),
+	})
+	if err != nil {
+		return nil, categorizeCognitoError(err, "ユーザー属性の取得に失敗しました")
+	}
+
+	values := make(map[string]string, len(names))
+	for name := range names {
+		values[name] = ""
+	}
+	for _, attr := range out.UserAttributes {
+		name := aws.StringValue(attr.Name)
+		if _, ok := values[name]; ok {
+			values[name] = aws.StringValue(attr.Value)
+		}
+	}
+	return values, nil
+}
+
+// applyCognitoAttributes - Cognitoの属性を更新する（空文字の属性は削除する）
+func (u *accountUsecase) applyCognitoAttributes(ctx context.Context, accessToken string, attributes map[string]string) error {
+	var updates []*cognitoidentityprovider.AttributeType
+	var deletes []*string
+	for name, value := range attributes {
+		if value == "" {
+			deletes = append(deletes, aws.String(name))
+			continue
+		}
+		updates = append(updates, &cognitoidentityprovider.AttributeType{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+
+	if len(updates) > 0 {
+		_, err := u.cognitoClient.UpdateUserAttributesWithContext(ctx, &cognitoidentityprovider.UpdateUserAttributesInput{
+			AccessToken:    aws.String(accessToken),
+			UserAttributes: updates,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(deletes) > 0 {
+		_, err := u.cognitoClient.DeleteUserAttributesWithContext(ctx, &cognitoidentityprovider.DeleteUserAttributesInput{
+			AccessToken:        aws.String(accessToken),
+			UserAttributeNames: deletes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changedProfileAttributes - Cognitoに反映が必要な属性（現在の値から変わるもののみ）
+func changedProfileAttributes(user *domain.User, update domain.ProfileUpdate) map[string]string {
+	attributes := make(map[string]string)
+	if update.Name != nil && *update.Name != user.Name {
+		attributes[cognitoAttributeName] = *update.Name
+	}
+	if update.Picture != nil && *update.Picture != user.Picture {
+		attributes[cognitoAttributePicture] = *update.Picture
+	}
+	return attributes
+}
+
+// applyProfileUpdate - 変更をユーザーに適用し、変更があったかを返す
+func applyProfileUpdate(user *domain.User, update domain.ProfileUpdate) bool {
+	changed := false
+	if update.Name != nil && *update.Name != user.Name {
+		user.Name = *update.Name
+		changed = true
+	}
+	if update.Picture != nil && *update.Picture != user.Picture {
+		user.Picture = *update.Picture
+		changed = true
+	}
+	if update.Username != nil && *update.Username != user.Username {
+		user.Username = *update.Username
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider/cognitoidentityprovideriface"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/internal/repository"
+)
+
+type fakeUserRepository struct {
+	repository.IUserRepository
+	users     map[string]*domain.User
+	updateErr error
+	updated   []domain.User
+}
+
+func (r *fakeUserRepository) GetUserBySubjectID(ctx context.Context, sub string) (*domain.User, error) {
+	user, ok := r.users[sub]
+	if !ok {
+		return nil, nil
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	r.updated = append(r.updated, *user)
+	return nil
+}
+
+// fakeAttributeClient - Cognitoのユーザー属性を保持し、更新・削除の呼び出しを記録する
+type fakeAttributeClient struct {
+	cognitoidentityprovideriface.CognitoIdentityProviderAPI
+	attributes map[string]string
+	// failUpdatesAfter - この回数を超えた UpdateUserAttributes を失敗させる（0は失敗させない）
+	failUpdatesAfter int
+	updates          []map[string]string
+	deletes          [][]string
+	verified         []string
+}
+
+func (c *fakeAttributeClient) GetUserWithContext(ctx aws.Context, input *cognitoidentityprovider.GetUserInput, opts ...request.Option) (*cognitoidentityprovider.GetUserOutput, error) {
+	out := &cognitoidentityprovider.GetUserOutput{}
+	for name, value := range c.attributes {
+		out.UserAttributes = append(out.UserAttributes, &cognitoidentityprovider.AttributeType{Name: aws.String(name), Value: aws.String(value)})
+	}
+	return out, nil
+}
+
+func (c *fakeAttributeClient) UpdateUserAttributesWithContext(ctx aws.Context, input *cognitoidentityprovider.UpdateUserAttributesInput, opts ...request.Option) (*cognitoidentityprovider.UpdateUserAttributesOutput, error) {
+	if c.failUpdatesAfter > 0 && len(c.updates) >= c.failUpdatesAfter {
+		return nil, errors.New("cognito unavailable")
+	}
+	update := make(map[string]string)
+	for _, attr := range input.UserAttributes {
+		update[aws.StringValue(attr.Name)] = aws.StringValue(attr.Value)
+		c.attributes[aws.StringValue(attr.Name)] = aws.StringValue(attr.Value)
+	}
+	c.updates = append(c.updates, update)
+	return &cognitoidentityprovider.UpdateUserAttributesOutput{
+		CodeDeliveryDetailsList: []*cognitoidentityprovider.CodeDeliveryDetailsType{
+			{AttributeName: aws.String("email"), DeliveryMedium: aws.String("EMAIL"), Destination: aws.String("b***@example.com")},
+		},
+	}, nil
+}
+
+func (c *fakeAttributeClient) DeleteUserAttributesWithContext(ctx aws.Context, input *cognitoidentityprovider.DeleteUserAttributesInput, opts ...request.Option) (*cognitoidentityprovider.DeleteUserAttributesOutput, error) {
+	names := aws.StringValueSlice(input.UserAttributeNames)
+	for _, name := range names {
+		delete(c.attributes, name)
+	}
+	c.deletes = append(c.deletes, names)
+	return &cognitoidentityprovider.DeleteUserAttributesOutput{}, nil
+}
+
+func (c *fakeAttributeClient) VerifyUserAttributeWithContext(ctx aws.Context, input *cognitoidentityprovider.VerifyUserAttributeInput, opts ...request.Option) (*cognitoidentityprovider.VerifyUserAttributeOutput, error) {
+	c.verified = append(c.verified, aws.StringValue(input.AttributeName))
+	return &cognitoidentityprovider.VerifyUserAttributeOutput{}, nil
+}
+
+func newProfileTestUsecase(updateErr error) (*accountUsecase, *fakeUserRepository, *fakeAttributeClient) {
+	userRepo := &fakeUserRepository{
+		users: map[string]*domain.User{
+			"sub-1": {ID: 1, Name: "Alice", Username: "alice", Email: "alice@example.com"},
+			"sub-2": {ID: 2, Name: "Bob", Username: "bob", Email: "bob@example.com"},
+		},
+		updateErr: updateErr,
+	}
+	client := &fakeAttributeClient{attributes: map[string]string{"name": "Alice", "email": "alice@example.com"}}
+	return &accountUsecase{userRepo: userRepo, cognitoClient: client}, userRepo, client
+}
+
+func TestUpdateProfile_RollsBackCognitoWhenLocalSaveFails(t *testing.T) {
+	u, _, client := newProfileTestUsecase(errors.New("connection reset"))
+
+	update := domain.ProfileUpdate{Name: aws.String("Alicia"), Picture: aws.String("https://example.com/alicia.png")}
+	_, result, err := u.UpdateProfile(context.Background(), &domain.UserClaims{Sub: "sub-1"}, "access-token", update)
+
+	var syncErr *domain.ProfileSyncError
+	if !errors.As(err, &syncErr) {
+		t.Fatalf("expected ProfileSyncError, got %v", err)
+	}
+	want := domain.ProfileSyncResult{Local: domain.ProfileSyncFailed, Cognito: domain.ProfileSyncRolledBack}
+	if *result != want || syncErr.Result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+
+	// 変更前の値に戻し、元々なかったpictureは削除する
+	if len(client.updates) != 2 || client.updates[1]["name"] != "Alice" {
+		t.Errorf("name should be restored: %v", client.updates)
+	}
+	if len(client.deletes) != 1 || len(client.deletes[0]) != 1 || client.deletes[0][0] != "picture" {
+		t.Errorf("picture should be deleted: %v", client.deletes)
+	}
+	if client.attributes["name"] != "Alice" {
+		t.Errorf("cognito name = %q, want Alice", client.attributes["name"])
+	}
+	if _, ok := client.attributes["picture"]; ok {
+		t.Error("cognito picture should be removed")
+	}
+}
+
+func TestUpdateProfile_ReportsRollbackFailure(t *testing.T) {
+	u, _, client := newProfileTestUsecase(errors.New("connection reset"))
+	client.failUpdatesAfter = 1
+
+	_, result, err := u.UpdateProfile(context.Background(), &domain.UserClaims{Sub: "sub-1"}, "access-token", domain.ProfileUpdate{Name: aws.String("Alicia")})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := domain.ProfileSyncResult{Local: domain.ProfileSyncFailed, Cognito: domain.ProfileSyncRollbackFailed}
+	if *result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		update     domain.ProfileUpdate
+		updateErr  error
+		wantResult *domain.ProfileSyncResult
+		wantType   domain.AuthErrorType
+		wantCalls  int
+	}{
+		{
+			name:       "name synced to cognito",
+			update:     domain.ProfileUpdate{Name: aws.String("Alicia")},
+			wantResult: &domain.ProfileSyncResult{Local: domain.ProfileSyncUpdated, Cognito: domain.ProfileSyncUpdated},
+			wantCalls:  1,
+		},
+		{
+			name:       "username is local only",
+			update:     domain.ProfileUpdate{Username: aws.String("alicia")},
+			wantResult: &domain.ProfileSyncResult{Local: domain.ProfileSyncUpdated, Cognito: domain.ProfileSyncSkipped},
+		},
+		{
+			name:       "unchanged",
+			update:     domain.ProfileUpdate{Name: aws.String("Alice")},
+			wantResult: &domain.ProfileSyncResult{Local: domain.ProfileSyncSkipped, Cognito: domain.ProfileSyncSkipped},
+		},
+		{
+			name:     "username taken",
+			update:   domain.ProfileUpdate{Username: aws.String("bob")},
+			wantType: domain.AuthErrorTypeConflict,
+		},
+		{
+			name:      "username taken at save",
+			update:    domain.ProfileUpdate{Username: aws.String("alicia")},
+			updateErr: domain.ErrUsernameConflict,
+			wantType:  domain.AuthErrorTypeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _, client := newProfileTestUsecase(tt.updateErr)
+
+			_, result, err := u.UpdateProfile(context.Background(), &domain.UserClaims{Sub: "sub-1"}, "access-token", tt.update)
+			if tt.wantType != "" {
+				var authErr *domain.AuthError
+				if !errors.As(err, &authErr) || authErr.Type != tt.wantType {
+					t.Fatalf("expected %s error, got %v", tt.wantType, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *result != *tt.wantResult {
+				t.Errorf("result = %+v, want %+v", *result, *tt.wantResult)
+			}
+			if len(client.updates) != tt.wantCalls {
+				t.Errorf("cognito updates = %d, want %d", len(client.updates), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile_RequiresAccessTokenForCognitoAttributes(t *testing.T) {
+	u, _, client := newProfileTestUsecase(nil)
+
+	_, _, err := u.UpdateProfile(context.Background(), &domain.UserClaims{Sub: "sub-1"}, "", domain.ProfileUpdate{Name: aws.String("Alicia")})
+
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeSecurity {
+		t.Fatalf("expected security error, got %v", err)
+	}
+	if len(client.updates) != 0 {
+		t.Error("cognito should not be updated")
+	}
+}
+
+func TestVerifyAttribute(t *testing.T) {
+	claims := &domain.UserClaims{Sub: "sub-1"}
+
+	t.Run("unsupported attribute", func(t *testing.T) {
+		u, _, _ := newProfileTestUsecase(nil)
+		_, err := u.VerifyAttribute(context.Background(), claims, "access-token", "name", "", "123456")
+
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || authErr.Type != domain.AuthErrorTypeValidation {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("email used by another account", func(t *testing.T) {
+		u, _, client := newProfileTestUsecase(nil)
+		_, err := u.VerifyAttribute(context.Background(), claims, "access-token", "email", "bob@example.com", "")
+
+		if !errors.Is(err, domain.ErrUserEmailConflict) {
+			t.Fatalf("expected ErrUserEmailConflict, got %v", err)
+		}
+		if len(client.updates) != 0 {
+			t.Error("cognito should not be updated")
+		}
+	})
+
+	t.Run("change sends code", func(t *testing.T) {
+		u, _, _ := newProfileTestUsecase(nil)
+		verification, err := u.VerifyAttribute(context.Background(), claims, "access-token", "email", "alicia@example.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verification.Verified || verification.CodeDelivery == nil || verification.CodeDelivery.DeliveryMedium != "EMAIL" {
+			t.Errorf("unexpected verification: %+v", verification)
+		}
+	})
+
+	t.Run("confirmed email synced locally", func(t *testing.T) {
+		u, userRepo, client := newProfileTestUsecase(nil)
+		client.attributes["email"] = "alicia@example.com"
+
+		verification, err := u.VerifyAttribute(context.Background(), claims, "access-token", "email", "", "123456")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !verification.Verified || len(client.verified) != 1 {
+			t.Errorf("unexpected verification: %+v", verification)
+		}
+		want := domain.ProfileSyncResult{Local: domain.ProfileSyncUpdated, Cognito: domain.ProfileSyncUpdated}
+		if verification.Sync == nil || *verification.Sync != want {
+			t.Errorf("sync = %+v, want %+v", verification.Sync, want)
+		}
+		if len(userRepo.updated) != 1 || userRepo.updated[0].Email != "alicia@example.com" {
+			t.Errorf("local email should be updated: %v", userRepo.updated)
+		}
+	})
+
+	t.Run("confirmed email fails to save locally", func(t *testing.T) {
+		u, _, client := newProfileTestUsecase(domain.ErrUserEmailConflict)
+		client.attributes["email"] = "alicia@example.com"
+
+		verification, err := u.VerifyAttribute(context.Background(), claims, "access-token", "email", "", "123456")
+
+		var syncErr *domain.ProfileSyncError
+		if !errors.As(err, &syncErr) {
+			t.Fatalf("expected ProfileSyncError, got %v", err)
+		}
+		if verification == nil || verification.Sync.Local != domain.ProfileSyncFailed || verification.Sync.Cognito != domain.ProfileSyncUpdated {
+			t.Errorf("unexpected verification: %+v", verification)
+		}
+	})
+}
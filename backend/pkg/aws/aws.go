@@ -1,6 +1,9 @@
 package awsconfig
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/matthewyuh246/aws-cognito/pkg/utils"
 )
 
@@ -9,7 +12,15 @@ type Config struct {
 	AWSRegion string
 	UserPoolID string
 	UserPoolClientID string
+	// IdentityPoolID - 一時的なAWS認証情報を発行するIDプール
+	IdentityPoolID string
 	JWTSecret string
+	// MFAIssuer - 認証アプリに表示する発行者名
+	MFAIssuer string
+	// AdminRole - 管理者APIに必要なロール
+	AdminRole string
+	// RoleHierarchy - ロール階層（"admin=member;..."、ロールはCognitoのグループ名）
+	RoleHierarchy string
 }
 
 func LoadCognitoConfig() *Config {
@@ -18,6 +29,38 @@ func LoadCognitoConfig() *Config {
 		AWSRegion: utils.GetEnv("AWS_REGION", ""),
 		UserPoolID: utils.GetEnv("USER_POOL_ID", ""),
 		UserPoolClientID: utils.GetEnv("USER_POOL_CLIENT_ID", ""),
-		JWTSecret: utils.GetEnv("JWT_SECRET", "your-secret-key"),
+		IdentityPoolID: utils.GetEnv("IDENTITY_POOL_ID", ""),
+		JWTSecret: utils.GetEnv("JWT_SECRET", ""),
+		MFAIssuer: utils.GetEnv("MFA_ISSUER", "aws-cognito"),
+		AdminRole: utils.GetEnv("ADMIN_ROLE", "admin"),
+		RoleHierarchy: utils.GetEnv("ROLE_HIERARCHY", "admin=member"),
+	}
+}
+
+// minJWTSecretLength - チャレンジセッションなどの暗号鍵の導出元として必要な長さ
+const minJWTSecretLength = 32
+
+// placeholderJWTSecrets - サンプルの値（そのまま使うと誰でも暗号化・改ざんできる）
+var placeholderJWTSecrets = map[string]bool{
+	"your-secret-key": true,
+	"your-super-secret-jwt-key-minimum-32-characters": true,
+}
+
+// Validate - 既定値のままでは安全に起動できない設定を検証する
+func (c *Config) Validate() error {
+	if c.JWTSecret == "" {
+		return errors.New("JWT_SECRET is required")
+	}
+	if placeholderJWTSecrets[c.JWTSecret] {
+		return errors.New("JWT_SECRET must not be the example value")
+	}
+	if len(c.JWTSecret) < minJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d characters", minJWTSecretLength)
 	}
-}
\ No newline at end of file
+	return nil
+}
+
+// CognitoIssuer - ユーザープールが発行するトークンのiss
+func (c *Config) CognitoIssuer() string {
+	return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", c.AWSRegion, c.UserPoolID)
+}
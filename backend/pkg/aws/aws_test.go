@@ -0,0 +1,26 @@
+package awsconfig
+
+import "testing"
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		jwtSecret string
+		wantErr   bool
+	}{
+		{"missing", "", true},
+		{"old default", "your-secret-key", true},
+		{"example value", "your-super-secret-jwt-key-minimum-32-characters", true},
+		{"too short", "short-secret", true},
+		{"random", "q3J0aG9yLXNlY3JldC1mb3ItdGVzdHMtb25seS0xMjM0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{JWTSecret: tt.jwtSecret}).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package githuboidc
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	defaultGitHubURL    = "https://github.com"
+	defaultGitHubAPIURL = "https://api.github.com"
+	defaultIDTokenTTL   = 5 * time.Minute
+)
+
+// Config - GitHub OIDCシムの設定
+type Config struct {
+	// Issuer - シムの公開URL（Cognitoの oidc_issuer と一致させる。HTTPSで到達できる必要がある）
+	Issuer string
+	// PrivateKey - IDトークンの署名鍵（再起動で変わるとCognitoが検証できなくなるため固定する）
+	PrivateKey *rsa.PrivateKey
+	// GitHubURL / GitHubAPIURL - GitHub Enterprise やテストで差し替える
+	GitHubURL    string
+	GitHubAPIURL string
+	IDTokenTTL   time.Duration
+	HTTPClient   *http.Client
+}
+
+// LoadConfig - 環境変数から設定を読み込む
+// GITHUB_OIDC_ISSUER と GITHUB_OIDC_PRIVATE_KEY_FILE（PKCS#1 または PKCS#8 のRSA鍵）は必須
+func LoadConfig() (Config, error) {
+	config := Config{
+		Issuer:       strings.TrimSuffix(utils.GetEnv("GITHUB_OIDC_ISSUER", ""), "/"),
+		GitHubURL:    utils.GetEnv("GITHUB_URL", defaultGitHubURL),
+		GitHubAPIURL: utils.GetEnv("GITHUB_API_URL", defaultGitHubAPIURL),
+		IDTokenTTL:   defaultIDTokenTTL,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+	if config.Issuer == "" {
+		return Config{}, errors.New("GITHUB_OIDC_ISSUER is required")
+	}
+
+	keyFile := utils.GetEnv("GITHUB_OIDC_PRIVATE_KEY_FILE", "")
+	if keyFile == "" {
+		return Config{}, errors.New("GITHUB_OIDC_PRIVATE_KEY_FILE is required")
+	}
+	pemBytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		return Config{}, fmt.Errorf("read private key: %w", err)
+	}
+	config.PrivateKey, err = ParsePrivateKey(pemBytes)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// ParsePrivateKey - PEM形式のRSA秘密鍵を読み込む
+func ParsePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not RSA")
+	}
+	return key, nil
+}
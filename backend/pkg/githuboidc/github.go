@@ -0,0 +1,138 @@
+package githuboidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// errInvalidGrant - GitHubが認可コードまたはアクセストークンを拒否した
+var errInvalidGrant = errors.New("github rejected the grant")
+
+// githubUser - GET /user の必要な項目
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+	HTMLURL   string `json:"html_url"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// githubEmail - GET /user/emails の要素
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// exchangeCode - 認可コードをGitHubのアクセストークンに交換する
+func (s *Server) exchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI string) (string, string, error) {
+	form := url.Values{}
+	form.Set("client_id", clientID)
+	form.Set("client_secret", clientSecret)
+	form.Set("code", code)
+	if redirectURI != "" {
+		form.Set("redirect_uri", redirectURI)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.GitHubURL+"/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	// GitHubはエラーでも200を返し、本文の error で失敗を示す
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Scope            string `json:"scope"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", "", fmt.Errorf("decode token response: %w", err)
+	}
+	if body.Error != "" || body.AccessToken == "" {
+		return "", "", fmt.Errorf("%w: %s %s", errInvalidGrant, body.Error, body.ErrorDescription)
+	}
+	return body.AccessToken, body.Scope, nil
+}
+
+// fetchClaims - GitHubのユーザー情報をOIDCの標準クレームに変換する
+// emailはプライマリのアドレスを使い、GitHubで確認済みかを email_verified に入れる
+func (s *Server) fetchClaims(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	var user githubUser
+	if err := s.getJSON(ctx, accessToken, "/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("github user has no id")
+	}
+
+	email, verified := user.Email, false
+	var emails []githubEmail
+	if err := s.getJSON(ctx, accessToken, "/user/emails", &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary {
+				email, verified = e.Email, e.Verified
+				break
+			}
+		}
+	} else if errors.Is(err, errInvalidGrant) {
+		return nil, err
+	}
+	// user:email スコープがない場合は公開メールアドレスのみ（未確認として扱う）
+
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+
+	claims := map[string]interface{}{
+		"sub":                strconv.FormatInt(user.ID, 10),
+		"name":               name,
+		"preferred_username": user.Login,
+		"picture":            user.AvatarURL,
+		"profile":            user.HTMLURL,
+		"email":              email,
+		"email_verified":     verified,
+	}
+	return claims, nil
+}
+
+func (s *Server) getJSON(ctx context.Context, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.GitHubAPIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%w: %s returned 401", errInvalidGrant, path)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("github %s returned %d", path, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,249 @@
+// Package githuboidc - GitHub（OAuth2のみでIDトークンを発行しない）をOIDCプロバイダーとして
+// Cognitoに登録するためのシム。Cognitoからの認可・トークン・userinfoのリクエストをGitHubに中継し、
+// GitHubのユーザー情報から署名付きIDトークンを発行する
+package githuboidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+)
+
+// githubScopes - プロフィールと確認済みメールアドレスの取得に必要なスコープ
+const githubScopes = "read:user user:email"
+
+type Server struct {
+	config Config
+	keyID  string
+	logger *logger.Logger
+}
+
+func NewServer(config Config) *Server {
+	if config.GitHubURL == "" {
+		config.GitHubURL = defaultGitHubURL
+	}
+	if config.GitHubAPIURL == "" {
+		config.GitHubAPIURL = defaultGitHubAPIURL
+	}
+	if config.IDTokenTTL == 0 {
+		config.IDTokenTTL = defaultIDTokenTTL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Server{
+		config: config,
+		keyID:  keyID(config),
+		logger: logger.New("GITHUB_OIDC"),
+	}
+}
+
+// Handler - OIDCのエンドポイント
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /.well-known/jwks.json", s.handleJWKS)
+	mux.HandleFunc("GET /authorize", s.handleAuthorize)
+	mux.HandleFunc("POST /token", s.handleToken)
+	mux.HandleFunc("GET /userinfo", s.handleUserInfo)
+	mux.HandleFunc("POST /userinfo", s.handleUserInfo)
+	return mux
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.config.Issuer,
+		"authorization_endpoint":                s.config.Issuer + "/authorize",
+		"token_endpoint":                        s.config.Issuer + "/token",
+		"userinfo_endpoint":                     s.config.Issuer + "/userinfo",
+		"jwks_uri":                              s.config.Issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+		"claims_supported": []string{
+			"sub", "name", "preferred_username", "picture", "profile", "email", "email_verified",
+		},
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := s.config.PrivateKey.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": s.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// handleAuthorize - GitHubの認可画面にリダイレクトする
+// redirect_uri（Cognitoの /oauth2/idpresponse）とstateはそのまま渡し、GitHubからCognitoに直接戻す
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_response_type", "only the code flow is supported")
+		return
+	}
+	if query.Get("client_id") == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "client_id is required")
+		return
+	}
+
+	upstream := url.Values{}
+	upstream.Set("client_id", query.Get("client_id"))
+	upstream.Set("scope", githubScopes)
+	if redirectURI := query.Get("redirect_uri"); redirectURI != "" {
+		upstream.Set("redirect_uri", redirectURI)
+	}
+	if state := query.Get("state"); state != "" {
+		upstream.Set("state", state)
+	}
+
+	http.Redirect(w, r, s.config.GitHubURL+"/login/oauth/authorize?"+upstream.Encode(), http.StatusFound)
+}
+
+// handleToken - 認可コードをGitHubで交換し、GitHubのアクセストークンと署名付きIDトークンを返す
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid form")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	code := r.PostForm.Get("code")
+	if clientID == "" || clientSecret == "" || code == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "client credentials and code are required")
+		return
+	}
+
+	accessToken, scope, err := s.exchangeCode(r.Context(), clientID, clientSecret, code, r.PostForm.Get("redirect_uri"))
+	if err != nil {
+		s.writeUpstreamError(w, "トークン交換エラー", err)
+		return
+	}
+
+	claims, err := s.fetchClaims(r.Context(), accessToken)
+	if err != nil {
+		s.writeUpstreamError(w, "ユーザー情報取得エラー", err)
+		return
+	}
+
+	idToken, err := s.signIDToken(clientID, claims)
+	if err != nil {
+		s.logger.Error("IDトークン署名エラー", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue id_token")
+		return
+	}
+
+	s.logger.Info("IDトークン発行", map[string]interface{}{
+		"sub":          claims["sub"],
+		"github_scope": scope,
+	})
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+		"scope":        "openid email profile",
+	})
+}
+
+// handleUserInfo - GitHubのアクセストークンでユーザー情報を返す
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token", "bearer token is required")
+		return
+	}
+
+	claims, err := s.fetchClaims(r.Context(), strings.TrimSpace(header[len("Bearer "):]))
+	if errors.Is(err, errInvalidGrant) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token", "github rejected the access token")
+		return
+	}
+	if err != nil {
+		s.writeUpstreamError(w, "ユーザー情報取得エラー", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func (s *Server) signIDToken(audience string, claims map[string]interface{}) (string, error) {
+	now := time.Now()
+	tokenClaims := jwt.MapClaims{
+		"iss":       s.config.Issuer,
+		"aud":       audience,
+		"iat":       now.Unix(),
+		"auth_time": now.Unix(),
+		"exp":       now.Add(s.config.IDTokenTTL).Unix(),
+	}
+	for key, value := range claims {
+		tokenClaims[key] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, tokenClaims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.config.PrivateKey)
+}
+
+func (s *Server) writeUpstreamError(w http.ResponseWriter, message string, err error) {
+	s.logger.Error(message, map[string]interface{}{
+		"error": err.Error(),
+	})
+	if errors.Is(err, errInvalidGrant) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "github rejected the request")
+		return
+	}
+	writeOAuthError(w, http.StatusBadGateway, "server_error", "github is unavailable")
+}
+
+// keyID - 公開鍵から決まるkid（鍵を差し替えるとkidも変わる）
+func keyID(config Config) string {
+	if config.PrivateKey == nil {
+		return ""
+	}
+	sum := sha256.Sum256(config.PrivateKey.PublicKey.N.Bytes())
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
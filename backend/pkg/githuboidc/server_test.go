@@ -0,0 +1,195 @@
+package githuboidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "github-client-id"
+	testClientSecret = "github-client-secret"
+	testCode         = "valid-code"
+	testAccessToken  = "gho_test"
+)
+
+func newFakeGitHub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("client_id") != testClientID || r.PostForm.Get("client_secret") != testClientSecret ||
+			r.PostForm.Get("code") != testCode {
+			writeJSON(w, http.StatusOK, map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"access_token": testAccessToken, "scope": githubScopes})
+	})
+	authorized := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer "+testAccessToken
+	}
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, githubUser{
+			ID:        42,
+			Login:     "octocat",
+			AvatarURL: "https://avatars.example.com/u/42",
+			HTMLURL:   "https://github.com/octocat",
+			Email:     "public@example.com",
+		})
+	})
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, []githubEmail{
+			{Email: "other@example.com", Verified: true},
+			{Email: "octocat@example.com", Primary: true, Verified: true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestServer(t *testing.T) (*Server, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	github := newFakeGitHub(t)
+	return NewServer(Config{
+		Issuer:       "https://oidc.example.com",
+		PrivateKey:   key,
+		GitHubURL:    github.URL,
+		GitHubAPIURL: github.URL,
+	}), key
+}
+
+func postToken(t *testing.T, handler http.Handler, code string) *httptest.ResponseRecorder {
+	t.Helper()
+	form := url.Values{"grant_type": {"authorization_code"}, "code": {code}}
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(testClientID, testClientSecret)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestToken_IssuesSignedIDToken(t *testing.T) {
+	server, key := newTestServer(t)
+
+	rec := postToken(t, server.Handler(), testCode)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.AccessToken != testAccessToken {
+		t.Errorf("access_token = %q", body.AccessToken)
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(body.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["kid"] != server.keyID {
+			t.Errorf("kid = %v, want %v", token.Header["kid"], server.keyID)
+		}
+		return &key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer("https://oidc.example.com"),
+		jwt.WithAudience(testClientID),
+	)
+	if err != nil || !token.Valid {
+		t.Fatalf("id_token invalid: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"sub":                "42",
+		"name":               "octocat",
+		"preferred_username": "octocat",
+		"email":              "octocat@example.com",
+		"email_verified":     true,
+	}
+	for k, v := range want {
+		if claims[k] != v {
+			t.Errorf("claim %s = %v, want %v", k, claims[k], v)
+		}
+	}
+}
+
+func TestToken_RejectedCode(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	rec := postToken(t, server.Handler(), "wrong-code")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"invalid_grant"`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestAuthorize_RedirectsToGitHub(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/authorize?response_type=code&client_id="+testClientID+
+		"&redirect_uri=https%3A%2F%2Fauth.example.com%2Foauth2%2Fidpresponse&state=abc&scope=openid+email", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("location: %v", err)
+	}
+	if location.Path != "/login/oauth/authorize" {
+		t.Errorf("path = %s", location.Path)
+	}
+	query := location.Query()
+	if query.Get("state") != "abc" || query.Get("scope") != githubScopes ||
+		query.Get("redirect_uri") != "https://auth.example.com/oauth2/idpresponse" {
+		t.Errorf("query = %v", query)
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+testAccessToken)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer expired")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
@@ -62,6 +62,10 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 		if err == nil && !c.shouldRetry(resp.StatusCode) {
 			return resp, nil
 		}
+		// 再試行しても回復しなかった5xxは、呼び出し側がステータスコードで分類できるようにそのまま返す
+		if err == nil && attempt == c.config.MaxRetries {
+			return resp, nil
+		}
 
 		if err != nil {
 			lastErr = err
@@ -26,6 +26,10 @@ func (l *Logger) Info(message string, fields map[string]interface{}) {
 	l.LogStructured("INFO", message, fields)
 }
 
+func (l *Logger) Warn(message string, fields map[string]interface{}) {
+	l.LogStructured("WARN", message, fields)
+}
+
 func (l *Logger) Error(message string, fields map[string]interface{}) {
 	l.LogStructured("ERROR", message, fields)
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileSender - メールを送信せず .eml ファイルとして書き出す（ローカル開発用）
+type fileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir, from string) Sender {
+	return &fileSender{dir: dir, from: from}
+}
+
+func (s *fileSender) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s.eml", time.Now().UTC().Format("20060102T150405.000000000"))
+	path := filepath.Join(s.dir, name)
+
+	if err := os.WriteFile(path, buildMIMEMessage(s.from, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := NewFileSender(dir, "no-reply@example.com")
+
+	err := sender.Send(context.Background(), Message{
+		To:      "alice@example.com",
+		Subject: "ログインコード",
+		Body:    "ログインコード: 123456\n",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mail file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	msg, err := mail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("written mail is not a valid message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(msg.Body)
+
+	tests := map[string]string{
+		"From":         msg.Header.Get("From"),
+		"To":           msg.Header.Get("To"),
+		"Subject":      subject,
+		"Content-Type": msg.Header.Get("Content-Type"),
+		"Body":         string(body),
+	}
+	want := map[string]string{
+		"From":         "no-reply@example.com",
+		"To":           "alice@example.com",
+		"Subject":      "ログインコード",
+		"Content-Type": "text/plain; charset=UTF-8",
+		"Body":         "ログインコード: 123456\n",
+	}
+	for key, got := range tests {
+		if got != want[key] {
+			t.Errorf("%s = %q, want %q", key, got, want[key])
+		}
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("invalid Date header: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, driver := range []string{DriverFile, DriverSMTP} {
+		if _, err := New(Config{Driver: driver}); err != nil {
+			t.Errorf("%s: unexpected error: %v", driver, err)
+		}
+	}
+	if _, err := New(Config{Driver: "sendmail"}); err == nil {
+		t.Error("expected an error for an unknown driver")
+	}
+}
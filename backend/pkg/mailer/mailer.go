@@ -0,0 +1,73 @@
+// Package mailer - メール送信の抽象化（開発用のファイル出力・SMTP、本番用のSES）
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matthewyuh246/aws-cognito/pkg/utils"
+)
+
+const (
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+	DriverSES  = "ses"
+)
+
+// Message - 送信するメール（本文はテキストのみ）
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender - メールの送信方法
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config - 送信方法の設定
+type Config struct {
+	Driver string
+	From   string
+
+	// FileDir - file: メールを書き出すディレクトリ
+	FileDir string
+
+	// SMTP - smtp: MailHog などのローカルSMTPサーバーを想定
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// AWSRegion - ses: 送信に使うリージョン
+	AWSRegion string
+}
+
+// LoadConfig - 環境変数から設定を読み込む
+func LoadConfig() Config {
+	return Config{
+		Driver:       utils.GetEnv("MAIL_DRIVER", DriverFile),
+		From:         utils.GetEnv("MAIL_FROM", "no-reply@localhost"),
+		FileDir:      utils.GetEnv("MAIL_FILE_DIR", "tmp/mail"),
+		SMTPHost:     utils.GetEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     utils.GetEnv("SMTP_PORT", "1025"),
+		SMTPUsername: utils.GetEnv("SMTP_USERNAME", ""),
+		SMTPPassword: utils.GetEnv("SMTP_PASSWORD", ""),
+		AWSRegion:    utils.GetEnv("AWS_REGION", ""),
+	}
+}
+
+// New - 設定のドライバーに応じた Sender を返す
+func New(config Config) (Sender, error) {
+	switch config.Driver {
+	case DriverFile:
+		return NewFileSender(config.FileDir, config.From), nil
+	case DriverSMTP:
+		return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From), nil
+	case DriverSES:
+		return NewSESSender(config.AWSRegion, config.From)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", config.Driver)
+	}
+}
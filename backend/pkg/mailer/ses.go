@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+type sesSender struct {
+	client *ses.SES
+	from   string
+}
+
+func NewSESSender(region, from string) (Sender, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return &sesSender{client: ses.New(sess), from: from}, nil
+}
+
+func (s *sesSender) Send(ctx context.Context, msg Message) error {
+	_, err := s.client.SendEmailWithContext(ctx, &ses.SendEmailInput{
+		Source: aws.String(s.from),
+		Destination: &ses.Destination{
+			ToAddresses: []*string{aws.String(msg.To)},
+		},
+		Message: &ses.Message{
+			Subject: &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(msg.Subject)},
+			Body: &ses.Body{
+				Text: &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(msg.Body)},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send mail via SES: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+)
+
+const (
+	userClaimsContextKey = "user_claims"
+	rawTokenContextKey   = "raw_token"
+	bearerPrefix         = "Bearer "
+)
+
+// TokenVerifier - Bearerトークンを検証し、クレームからプロバイダーとグループを取り出す（repository.IIdentityProvider が実装する）
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (map[string]interface{}, error)
+	ProviderFromClaims(claims map[string]interface{}) string
+	GroupsFromClaims(claims map[string]interface{}) []string
+}
+
+type AuthMiddleware struct {
+	verifier  TokenVerifier
+	hierarchy domain.RoleHierarchy
+	logger    *logger.Logger
+}
+
+func NewAuthMiddleware(verifier TokenVerifier, hierarchy domain.RoleHierarchy) *AuthMiddleware {
+	return &AuthMiddleware{
+		verifier:  verifier,
+		hierarchy: hierarchy,
+		logger:    logger.New("AUTH_MIDDLEWARE"),
+	}
+}
+
+// RequireAuth - 有効なBearerトークンがない場合は401を返す
+func (m *AuthMiddleware) RequireAuth() echo.MiddlewareFunc {
+	return m.authenticate(true)
+}
+
+// OptionalAuth - トークンがあれば検証してクレームを設定し、なければそのまま通す
+// 不正なトークンが送られてきた場合は401を返す
+func (m *AuthMiddleware) OptionalAuth() echo.MiddlewareFunc {
+	return m.authenticate(false)
+}
+
+func (m *AuthMiddleware) authenticate(required bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, found := extractBearerToken(c)
+			if !found {
+				if !required {
+					return next(c)
+				}
+				return sendUnauthorized(c, "認証が必要です")
+			}
+
+			claims, err := m.verifier.VerifyToken(c.Request().Context(), token)
+			if err != nil {
+				m.logger.Error("トークン検証エラー", map[string]interface{}{
+					"path":  c.Path(),
+					"error": err.Error(),
+				})
+				return sendUnauthorized(c, "認証トークンが無効です")
+			}
+
+			userClaims := m.buildUserClaims(claims)
+			userClaims.Roles = m.hierarchy.EffectiveRoles(userClaims.Groups)
+
+			c.Set(userClaimsContextKey, userClaims)
+			c.Set(rawTokenContextKey, token)
+			return next(c)
+		}
+	}
+}
+
+// GetUserClaims - 認証ミドルウェアが設定したクレームを取得する
+func GetUserClaims(c echo.Context) (*domain.UserClaims, bool) {
+	claims, ok := c.Get(userClaimsContextKey).(*domain.UserClaims)
+	return claims, ok && claims != nil
+}
+
+// GetRawToken - 認証に使用されたBearerトークンを取得する
+func GetRawToken(c echo.Context) (string, bool) {
+	token, ok := c.Get(rawTokenContextKey).(string)
+	return token, ok && token != ""
+}
+
+func extractBearerToken(c echo.Context) (string, bool) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if header == "" {
+		return "", false
+	}
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", true
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):]), true
+}
+
+func sendUnauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return response.SendUnauthorized(c, message)
+}
+
+func (m *AuthMiddleware) buildUserClaims(claims map[string]interface{}) *domain.UserClaims {
+	userClaims := &domain.UserClaims{
+		Sub:      stringClaim(claims, "sub"),
+		Email:    stringClaim(claims, "email"),
+		Name:     stringClaim(claims, "name"),
+		Picture:  stringClaim(claims, "picture"),
+		TokenUse: stringClaim(claims, "token_use"),
+		Provider: m.verifier.ProviderFromClaims(claims),
+		Groups:   m.verifier.GroupsFromClaims(claims),
+	}
+
+	// CognitoのIDトークンは cognito:username、アクセストークンは username、汎用OIDCは preferred_username を持つ
+	for _, key := range []string{"cognito:username", "username", "preferred_username"} {
+		if userClaims.Username = stringClaim(claims, key); userClaims.Username != "" {
+			break
+		}
+	}
+
+	if exp, ok := claims["exp"].(float64); ok {
+		userClaims.Exp = int64(exp)
+	}
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		userClaims.AuthTime = int64(authTime)
+	}
+
+	return userClaims
+}
+
+func stringClaim(claims map[string]interface{}, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
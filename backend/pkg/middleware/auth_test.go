@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+type fakeVerifier struct {
+	tokens map[string]map[string]interface{}
+}
+
+func (v *fakeVerifier) VerifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, ok := v.tokens[token]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (v *fakeVerifier) ProviderFromClaims(claims map[string]interface{}) string {
+	return "cognito"
+}
+
+func (v *fakeVerifier) GroupsFromClaims(claims map[string]interface{}) []string {
+	groups, _ := claims["groups"].([]string)
+	return groups
+}
+
+func newTestAuthMiddleware() *AuthMiddleware {
+	verifier := &fakeVerifier{tokens: map[string]map[string]interface{}{
+		"valid": {
+			"sub":              "user-1",
+			"cognito:username": "alice",
+			"token_use":        "id",
+			"groups":           []string{"admin"},
+		},
+	}}
+	return NewAuthMiddleware(verifier, domain.RoleHierarchy{"admin": {"member"}})
+}
+
+// serve - ミドルウェアを通したハンドラーを実行し、ステータスとハンドラーに渡ったクレームを返す
+func serve(t *testing.T, mw echo.MiddlewareFunc, authorization string) (int, map[string]interface{}) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var seen map[string]interface{}
+	handler := mw(func(c echo.Context) error {
+		seen = map[string]interface{}{}
+		if claims, ok := GetUserClaims(c); ok {
+			seen["sub"] = claims.Sub
+			seen["username"] = claims.Username
+			seen["roles"] = claims.Roles
+		}
+		if token, ok := GetRawToken(c); ok {
+			seen["token"] = token
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec.Code, seen
+}
+
+func TestRequireAuth(t *testing.T) {
+	m := newTestAuthMiddleware()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"empty bearer", "Bearer ", http.StatusUnauthorized},
+		{"invalid token", "Bearer forged", http.StatusUnauthorized},
+		{"valid token", "Bearer valid", http.StatusOK},
+		{"case-insensitive scheme", "bearer valid", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, seen := serve(t, m.RequireAuth(), tt.authorization)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK && seen != nil {
+				t.Error("handler should not run for rejected requests")
+			}
+		})
+	}
+}
+
+func TestRequireAuth_SetsClaims(t *testing.T) {
+	m := newTestAuthMiddleware()
+
+	_, seen := serve(t, m.RequireAuth(), "Bearer valid")
+	if seen["sub"] != "user-1" || seen["username"] != "alice" || seen["token"] != "valid" {
+		t.Fatalf("unexpected claims: %v", seen)
+	}
+	if roles, _ := seen["roles"].([]string); len(roles) != 2 || roles[0] != "admin" || roles[1] != "member" {
+		t.Errorf("roles should be expanded through the hierarchy: %v", seen["roles"])
+	}
+}
+
+func TestRequireAuth_SetsWWWAuthenticate(t *testing.T) {
+	m := newTestAuthMiddleware()
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	if err := m.RequireAuth()(func(c echo.Context) error { return nil })(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.Header().Get(echo.HeaderWWWAuthenticate); got != "Bearer" {
+		t.Errorf("WWW-Authenticate = %q, want Bearer", got)
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	m := newTestAuthMiddleware()
+
+	status, seen := serve(t, m.OptionalAuth(), "")
+	if status != http.StatusOK {
+		t.Fatalf("anonymous request should pass, got %d", status)
+	}
+	if _, ok := seen["sub"]; ok {
+		t.Error("anonymous request should not have claims")
+	}
+
+	status, seen = serve(t, m.OptionalAuth(), "Bearer valid")
+	if status != http.StatusOK || seen["sub"] != "user-1" {
+		t.Fatalf("valid token should set claims: status=%d claims=%v", status, seen)
+	}
+
+	// トークンが送られた場合は、任意認証でも不正なものを拒否する
+	for _, authorization := range []string{"Bearer forged", "Token valid"} {
+		if status, _ := serve(t, m.OptionalAuth(), authorization); status != http.StatusUnauthorized {
+			t.Errorf("%q: status = %d, want 401", authorization, status)
+		}
+	}
+}
@@ -25,5 +25,7 @@ func SetupCORS(config *CORSConfig) echo.MiddlewareFunc {
 		AllowOrigins: config.AllowOrigins,
 		AllowMethods: config.AllowMethods,
 		AllowHeaders: config.AllowHeaders,
+		// 認可リクエストのstateをCookieでブラウザに結び付けるため
+		AllowCredentials: true,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/controller/response"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+	"github.com/matthewyuh246/aws-cognito/pkg/logger"
+)
+
+// PermissionChecker - ユーザーが権限・ロールを持つかを判定する
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, claims *domain.UserClaims, permission string) (bool, error)
+	HasAnyRole(ctx context.Context, claims *domain.UserClaims, roles ...string) (bool, error)
+}
+
+type PermissionMiddleware struct {
+	checker PermissionChecker
+	logger  *logger.Logger
+}
+
+func NewPermissionMiddleware(checker PermissionChecker) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		checker: checker,
+		logger:  logger.New("PERMISSION_MIDDLEWARE"),
+	}
+}
+
+// RequirePermission - permissionを持たない場合は403を返す（RequireAuthの後に使う）
+func (m *PermissionMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := GetUserClaims(c)
+			if !ok {
+				return sendUnauthorized(c, "認証が必要です")
+			}
+
+			allowed, err := m.checker.HasPermission(c.Request().Context(), claims, permission)
+			if err != nil {
+				m.logger.Error("権限確認エラー", map[string]interface{}{
+					"path":       c.Path(),
+					"sub":        claims.Sub,
+					"permission": permission,
+					"error":      err.Error(),
+				})
+				return response.SendAuthError(c, err)
+			}
+
+			if !allowed {
+				m.logger.Warn("権限のないアクセス", map[string]interface{}{
+					"path":       c.Path(),
+					"sub":        claims.Sub,
+					"permission": permission,
+				})
+				return response.SendForbidden(c, "この操作を行う権限がありません")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRoles - rolesのいずれも持たない場合は403を返す（RequireAuthの後に使う）
+// ロールはIdPのグループとローカルで割り当てたロールを、ロール階層で展開したもの（admin → member など）
+func (m *PermissionMiddleware) RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := GetUserClaims(c)
+			if !ok {
+				return sendUnauthorized(c, "認証が必要です")
+			}
+
+			allowed, err := m.checker.HasAnyRole(c.Request().Context(), claims, roles...)
+			if err != nil {
+				m.logger.Error("ロール確認エラー", map[string]interface{}{
+					"path":  c.Path(),
+					"sub":   claims.Sub,
+					"roles": roles,
+					"error": err.Error(),
+				})
+				return response.SendAuthError(c, err)
+			}
+
+			if !allowed {
+				m.logger.Warn("権限のないアクセス", map[string]interface{}{
+					"path":   c.Path(),
+					"sub":    claims.Sub,
+					"groups": claims.Groups,
+					"roles":  roles,
+				})
+				return response.SendForbidden(c, "この操作を行う権限がありません")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/matthewyuh246/aws-cognito/internal/domain"
+)
+
+// fakePermissionChecker - subごとの実効ロール・権限を返す
+type fakePermissionChecker struct {
+	sets map[string]*domain.PermissionSet
+	err  error
+}
+
+func (c *fakePermissionChecker) HasPermission(ctx context.Context, claims *domain.UserClaims, permission string) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	set, ok := c.sets[claims.Sub]
+	return ok && set.Allows(permission), nil
+}
+
+func (c *fakePermissionChecker) HasAnyRole(ctx context.Context, claims *domain.UserClaims, roles ...string) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	set, ok := c.sets[claims.Sub]
+	return ok && set.HasAnyRole(roles...), nil
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	auth := newTestAuthMiddleware()
+	checker := &fakePermissionChecker{sets: map[string]*domain.PermissionSet{
+		// ローカルで割り当てたadminをロール階層で展開した実効ロール
+		"user-1": {Roles: []string{"admin", "member"}, Permissions: []string{"users:read"}},
+	}}
+	m := NewPermissionMiddleware(checker)
+
+	chain := func(mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return auth.RequireAuth()(mw(next))
+		}
+	}
+
+	tests := []struct {
+		name       string
+		middleware echo.MiddlewareFunc
+		wantStatus int
+	}{
+		{"inherited role", m.RequireRoles("member"), http.StatusOK},
+		{"any of roles", m.RequireRoles("support", "admin"), http.StatusOK},
+		{"missing role", m.RequireRoles("support"), http.StatusForbidden},
+		{"granted permission", m.RequirePermission("users:read"), http.StatusOK},
+		{"missing permission", m.RequirePermission("users:manage"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := serve(t, chain(tt.middleware), "Bearer valid"); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+
+	// ロールを取得できない場合は拒否し、リポジトリのエラー種別でレスポンスを返す
+	checker.err = domain.NewAuthError(domain.AuthErrorTypeNetwork, "権限の取得に失敗しました", errors.New("db down"))
+	if status, _ := serve(t, chain(m.RequireRoles("member")), "Bearer valid"); status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", status)
+	}
+}
@@ -0,0 +1,195 @@
+// Package srp - Cognitoの USER_SRP_AUTH（SRP-6a）のクライアント側の計算
+// 計算方法は amazon-cognito-identity-js の AuthenticationHelper と互換
+package srp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// nHex - RFC 3526 の3072ビットMODPグループの素数
+const nHex = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1" +
+	"29024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+	"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245" +
+	"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+	"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D" +
+	"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+	"83655D23DCA3AD961C62F356208552BB9ED529077096966D" +
+	"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+	"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9" +
+	"DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+	"15728E5A8AAAC42DAD33170D04507A33A85521ABDF1CBA64" +
+	"ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7" +
+	"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6B" +
+	"F12FFA06D98A0864D87602733EC86A64521F2B18177B200C" +
+	"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB31" +
+	"43DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF"
+
+const (
+	gHex = "2"
+
+	// derivedKeyInfo - HKDFのinfo（Cognito固有の値）
+	derivedKeyInfo = "Caldera Derived Key"
+	derivedKeyLen  = 16
+
+	// TimestampLayout - PASSWORD_CLAIM_SIGNATURE に含めるタイムスタンプの形式（日はゼロ埋めしない）
+	TimestampLayout = "Mon Jan 2 15:04:05 UTC 2006"
+
+	ephemeralKeyBytes = 128
+)
+
+var (
+	// ErrInvalidServerValue - サーバーから受け取ったBまたはuが不正（B mod N == 0 など）
+	ErrInvalidServerValue = errors.New("srp: invalid server public value")
+
+	bigN, _ = new(big.Int).SetString(nHex, 16)
+	bigG, _ = new(big.Int).SetString(gHex, 16)
+	bigK    = new(big.Int).SetBytes(hashHex(PadHex(bigN) + PadHex(bigG)))
+)
+
+// Client - 1回のログインで使う一時鍵 a と公開値 A を保持する
+type Client struct {
+	poolName string
+	a        *big.Int
+	bigA     *big.Int
+}
+
+// NewClient - ユーザープールIDから一時鍵を生成する
+func NewClient(userPoolID string) (*Client, error) {
+	for {
+		buf := make([]byte, ephemeralKeyBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("srp: failed to generate ephemeral key: %w", err)
+		}
+		c, err := newClientWithKey(userPoolID, new(big.Int).SetBytes(buf))
+		if err == nil {
+			return c, nil
+		}
+	}
+}
+
+func newClientWithKey(userPoolID string, a *big.Int) (*Client, error) {
+	poolName := userPoolID
+	if i := strings.Index(userPoolID, "_"); i >= 0 {
+		poolName = userPoolID[i+1:]
+	}
+
+	a = new(big.Int).Mod(a, bigN)
+	bigA := new(big.Int).Exp(bigG, a, bigN)
+	if bigA.Sign() == 0 {
+		return nil, errors.New("srp: invalid ephemeral key")
+	}
+
+	return &Client{poolName: poolName, a: a, bigA: bigA}, nil
+}
+
+// SRPA - InitiateAuth の SRP_A に渡す公開値
+func (c *Client) SRPA() string {
+	return c.bigA.Text(16)
+}
+
+// PasswordVerifier - PASSWORD_VERIFIER チャレンジの応答に使う署名とタイムスタンプを計算する
+// userIDForSRP・saltHex・srpBHex・secretBlock はチャレンジパラメータの値をそのまま渡す
+func (c *Client) PasswordVerifier(userIDForSRP, password, saltHex, srpBHex, secretBlock string, now time.Time) (signature, timestamp string, err error) {
+	bigB, ok := new(big.Int).SetString(srpBHex, 16)
+	if !ok || new(big.Int).Mod(bigB, bigN).Sign() == 0 {
+		return "", "", ErrInvalidServerValue
+	}
+
+	salt, ok := new(big.Int).SetString(saltHex, 16)
+	if !ok {
+		return "", "", errors.New("srp: invalid salt")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(secretBlock)
+	if err != nil {
+		return "", "", fmt.Errorf("srp: invalid secret block: %w", err)
+	}
+
+	key, err := c.passwordAuthenticationKey(userIDForSRP, password, salt, bigB)
+	if err != nil {
+		return "", "", err
+	}
+
+	timestamp = now.UTC().Format(TimestampLayout)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(c.poolName))
+	mac.Write([]byte(userIDForSRP))
+	mac.Write(secret)
+	mac.Write([]byte(timestamp))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), timestamp, nil
+}
+
+// passwordAuthenticationKey - 共有秘密 S = (B - k * g^x)^(a + u * x) mod N からHKDFで鍵を導出する
+func (c *Client) passwordAuthenticationKey(userIDForSRP, password string, salt, bigB *big.Int) ([]byte, error) {
+	u := computeU(c.bigA, bigB)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidServerValue
+	}
+
+	x := computeX(c.poolName, userIDForSRP, password, salt)
+
+	gx := new(big.Int).Exp(bigG, x, bigN)
+	base := new(big.Int).Sub(bigB, new(big.Int).Mul(bigK, gx))
+	base.Mod(base, bigN)
+
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	s := new(big.Int).Exp(base, exp, bigN)
+
+	return deriveKey(s, u), nil
+}
+
+// computeU - u = H(PAD(A) | PAD(B))
+func computeU(bigA, bigB *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hashHex(PadHex(bigA) + PadHex(bigB)))
+}
+
+// computeX - x = H(PAD(salt) | H(poolName | userID | ":" | password))
+func computeX(poolName, userIDForSRP, password string, salt *big.Int) *big.Int {
+	identity := sha256.Sum256([]byte(poolName + userIDForSRP + ":" + password))
+	return new(big.Int).SetBytes(hashHex(PadHex(salt) + hex.EncodeToString(identity[:])))
+}
+
+// deriveKey - HKDF-SHA256（salt = PAD(u), ikm = PAD(S)）で16バイトの鍵を導出する
+func deriveKey(s, u *big.Int) []byte {
+	ikm, _ := hex.DecodeString(PadHex(s))
+	salt, _ := hex.DecodeString(PadHex(u))
+
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write([]byte(derivedKeyInfo))
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:derivedKeyLen]
+}
+
+// PadHex - 16進表現を偶数桁にし、最上位ビットが立つ場合は負数と解釈されないよう 00 を付ける
+func PadHex(n *big.Int) string {
+	h := n.Text(16)
+	if len(h)%2 == 1 {
+		return "0" + h
+	}
+	if strings.ContainsRune("89abcdef", rune(h[0])) {
+		return "00" + h
+	}
+	return h
+}
+
+// hashHex - 16進文字列をバイト列として SHA-256 でハッシュする
+func hashHex(h string) []byte {
+	b, _ := hex.DecodeString(h)
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
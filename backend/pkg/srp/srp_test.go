@@ -0,0 +1,313 @@
+package srp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const (
+	testPoolID   = "us-east-1_AbCdEfGhI"
+	testUserID   = "3f9c2a1e-1111-4a4a-9b9b-0123456789ab"
+	testPassword = "P@ssw0rd!"
+)
+
+func TestGroupParameters(t *testing.T) {
+	if bigN.BitLen() != 3072 {
+		t.Fatalf("unexpected N size: %d", bigN.BitLen())
+	}
+	// Nは安全素数（(N-1)/2も素数）
+	if !bigN.ProbablyPrime(20) || !new(big.Int).Rsh(bigN, 1).ProbablyPrime(20) {
+		t.Fatal("N is not a safe prime")
+	}
+	// k = H(PAD(N) | PAD(g)) は Cognito のSDKと同じ値になる
+	const wantK = "538282c4354742d7cbbde2359fcf67f9f5b3a6b08791e5011b43b8a5b66d9ee6"
+	if got := bigK.Text(16); got != wantK {
+		t.Fatalf("unexpected k: %s", got)
+	}
+}
+
+func TestPadHex(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0x1, "01"},
+		{0x7f, "7f"},
+		{0x80, "0080"},
+		{0xabc, "0abc"},
+		{0x7fff, "7fff"},
+		{0xffff, "00ffff"},
+	}
+
+	for _, tt := range tests {
+		if got := PadHex(big.NewInt(tt.in)); got != tt.want {
+			t.Errorf("PadHex(%x) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTimestampLayout(t *testing.T) {
+	// 日はゼロ埋めせず、時刻はゼロ埋めする
+	now := time.Date(2024, time.March, 5, 7, 8, 9, 0, time.FixedZone("JST", 9*60*60))
+	if got := now.UTC().Format(TimestampLayout); got != "Mon Mar 4 22:08:09 UTC 2024" {
+		t.Fatalf("unexpected timestamp: %s", got)
+	}
+}
+
+// testServer - Cognito側の検証処理（v = g^x, B = k*v + g^b, S = (A * v^u)^b）
+type testServer struct {
+	salt        *big.Int
+	verifier    *big.Int
+	b           *big.Int
+	bigB        *big.Int
+	secretBlock []byte
+}
+
+func newTestServer(t *testing.T, poolName, userID, password string) *testServer {
+	t.Helper()
+	salt := randomInt(t, 16)
+	x := computeX(poolName, userID, password, salt)
+	v := new(big.Int).Exp(bigG, x, bigN)
+	b := randomInt(t, 128)
+
+	bigB := new(big.Int).Mul(bigK, v)
+	bigB.Add(bigB, new(big.Int).Exp(bigG, b, bigN))
+	bigB.Mod(bigB, bigN)
+
+	secret := make([]byte, 64)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatalf("failed to generate secret block: %v", err)
+	}
+
+	return &testServer{salt: salt, verifier: v, b: b, bigB: bigB, secretBlock: secret}
+}
+
+func (s *testServer) verify(poolName, userID, srpA, signature, timestamp string) bool {
+	bigA, _ := new(big.Int).SetString(srpA, 16)
+	u := computeU(bigA, s.bigB)
+
+	base := new(big.Int).Mul(bigA, new(big.Int).Exp(s.verifier, u, bigN))
+	secret := new(big.Int).Exp(base.Mod(base, bigN), s.b, bigN)
+	key := deriveKey(secret, u)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(poolName + userID))
+	mac.Write(s.secretBlock)
+	mac.Write([]byte(timestamp))
+
+	got, err := base64.StdEncoding.DecodeString(signature)
+	return err == nil && hmac.Equal(got, mac.Sum(nil))
+}
+
+func randomInt(t *testing.T, byteLen int) *big.Int {
+	t.Helper()
+	buf := make([]byte, byteLen)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatalf("failed to generate random value: %v", err)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+func TestPasswordVerifier_AcceptedByServer(t *testing.T) {
+	server := newTestServer(t, "AbCdEfGhI", testUserID, testPassword)
+
+	client, err := NewClient(testPoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signature, timestamp, err := client.PasswordVerifier(
+		testUserID,
+		testPassword,
+		server.salt.Text(16),
+		server.bigB.Text(16),
+		base64.StdEncoding.EncodeToString(server.secretBlock),
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !server.verify("AbCdEfGhI", testUserID, client.SRPA(), signature, timestamp) {
+		t.Fatal("server rejected password claim signature")
+	}
+}
+
+func TestPasswordVerifier_WrongPasswordRejected(t *testing.T) {
+	server := newTestServer(t, "AbCdEfGhI", testUserID, testPassword)
+
+	client, err := NewClient(testPoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signature, timestamp, err := client.PasswordVerifier(
+		testUserID,
+		"wrong-password",
+		server.salt.Text(16),
+		server.bigB.Text(16),
+		base64.StdEncoding.EncodeToString(server.secretBlock),
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if server.verify("AbCdEfGhI", testUserID, client.SRPA(), signature, timestamp) {
+		t.Fatal("server accepted signature for wrong password")
+	}
+}
+
+func TestPasswordVerifier_RejectsInvalidB(t *testing.T) {
+	client, err := NewClient(testPoolID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, srpB := range []string{"0", nHex, "not-hex"} {
+		_, _, err := client.PasswordVerifier(testUserID, testPassword, "abcd", srpB, "", time.Now())
+		if !errors.Is(err, ErrInvalidServerValue) {
+			t.Errorf("B=%.8s: expected ErrInvalidServerValue, got %v", srpB, err)
+		}
+	}
+}
+
+// srpVector - 一時鍵 a を固定したチャレンジ応答の期待値
+// pycognito（https://github.com/NabuCasa/pycognito）の pycognito/aws_srp.py にある AWSSRP の
+// get_password_authentication_key・process_challenge を移植したPythonスクリプトで算出した
+// このパッケージとは独立に、pad_hex・compute_hkdf・タイムスタンプの形式まで pycognito の手順に従う
+type srpVector struct {
+	name         string
+	poolID       string
+	userIDForSRP string
+	password     string
+	a            string
+	salt         string
+	srpB         string
+	secretBlock  string
+	now          time.Time
+	// 期待値
+	srpA      string
+	timestamp string
+	signature string
+}
+
+var srpVectors = []srpVector{
+	{
+		// a = 0x0102...80、SRP_B は奇数桁（PadHexで 0 を付ける）、日は1桁
+		name:         "odd-length B",
+		poolID:       testPoolID,
+		userIDForSRP: testUserID,
+		password:     testPassword,
+		a: "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20" +
+			"2122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f40" +
+			"4142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f60" +
+			"6162636465666768696a6b6c6d6e6f707172737475767778797a7b7c7d7e7f80",
+		salt: "2f8b1a7c3e9d4f6a0b5c8e1d7a2f4b9c",
+		srpB: "59c734fdf1956c13be299cec3dd4b1f0f3063ba7a40f9dbbb94d0b703057354f" +
+			"a3cff0079ac6f1dda79afa83e9c4aaea00394a5391f559047a27a621139c0483" +
+			"31ab590050334516d10154249f7e4bf9c21b120ba34022b22b7281c24b8ee687" +
+			"bb703353cfd5d6da88c12278966d9457bbb53d956891025c6218b0122a21364b" +
+			"7eef8d373ea316bed95a0bcd78aa176dde0231c18a6dacaab1b4da562ca840e1" +
+			"b158dbb99a4c255e2a72c44589faa6f6a55bd0312a7704f5044b0767adab82ad" +
+			"a2fc867fd9aef2f4b49ec9214992653c7fec1c6092110ff384fe01bbc43d02ee" +
+			"8d5c9987b2a62bffab59318cb673fdb297e479c84dcb7ae7096e4c78957c1e3b" +
+			"8984ef5944d2ff8bfb785b42eb41f13d6fb116eec0684a40f2956d559795bf2f" +
+			"8551fbe49d811f11497918dba69547410cd5bedafd037277c74bac12b429f53d" +
+			"55caf085a8ebec6a0cbed637bd459bdd6ddb962ad078e609214d37fabbb49bed" +
+			"477f11e82ec3634d11ca0d15ca3db26c169a38251f9cdbc9a3bd14dff182b77",
+		secretBlock: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4OTo7PD0+Pw==",
+		now:         time.Date(2024, time.March, 5, 7, 8, 9, 0, time.FixedZone("JST", 9*60*60)),
+		srpA: "ce00119aff3d209146734fd8a5754ea0972b6e30ad843f288552a8bb40c4ae75" +
+			"7aef4cc842bb79dc35cf9439dd1d63bba10ef1c877282221c9f441fae286ddb0" +
+			"537d364de9bb37f0d525516605574ad06bc7918e4cd4e309ad5531b6c4e9b836" +
+			"1a09ad02bd307ab434f5e521c4f733aa507f8fde534e6c8cdfc7695f4ab19c53" +
+			"3be45581df6ce0ad63f91e1a95699dbfedf73576caa59890de50861614a0dd4c" +
+			"c3177c64302fb1520b8b0f00d0fb8acee6de2e3431e1b8878bcfb5763bebda1c" +
+			"d408f5febc6d7578c0f781253fafc5092bbbf5d2435524d9785f03fca3b548b4" +
+			"5eb7d04e12e387dd5904e07f770324a89dc76d554130b7ddced7a95f5b5213e7" +
+			"4d0173ca4bddd7e31858b39ef4753897c96ef109497bd7c570a177ea05da873b" +
+			"e036d2c1235e614e9c2f2ffd1deaed2280cae326413d8acf7e99a7f81e3113a0" +
+			"57aa72f0650b6abf79f08d302966470a380236bca2e9a0ed56303c2342aff22e" +
+			"2214fffefa9049c5af9b4446a4166c3a723051b7cd1cb6eb93a6d3b47f9ea1ee",
+		timestamp: "Mon Mar 4 22:08:09 UTC 2024",
+		signature: "md4bSQfwSf/eu2E2Gj4/WUQr5eId9hZmJ78rmrLR60k=",
+	},
+	{
+		// SALT・SRP_B の最上位ビットが立つ（PadHexで 00 を付ける）、パスワードに非ASCII文字を含む
+		name:         "high-bit salt and B",
+		poolID:       "ap-northeast-1_Zz9Yy8Xx7",
+		userIDForSRP: "alice@example.com",
+		password:     "Pässwörd ñ 1!",
+		a: "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d" +
+			"4bf5122f344554c53bde2ebb8cd2b7e3d1600ad631c385a5d7cce23c7785459a" +
+			"dbc1b4c900ffe48d575b5da5c638040125f65db0fe3e24494b76ea986457d986" +
+			"084fed08b978af4d7d196a7446a86b58009e636b611db16211b65a9aadff29c5",
+		salt: "e3b0c44298fc1c149afbf4c8996fb924",
+		srpB: "f6c6e57cc3dac1d6a2349701056ff5a3e48134efe4496a8c0f5cb9fc9e6dfc12" +
+			"4cb1fd840b329ec808f95c7a17d95ccc8848275c382ab11e4dfabd290c068070" +
+			"33210e8834e029b8498462db74392467738e494c653b3d045d2b45e8e82bc7bc" +
+			"4491461c9aaa3786193f0e80b1018a44dc5f67bda4637bd26184a2950f1cf852" +
+			"f4daf8fafbc239eebeb8b8f2204b444536a01ea3ec8a4039227c36c68fa18243" +
+			"3cc681c1df60183f4f7ba879aa08a6bdc47cb8743126976a47a3c4241310d1be" +
+			"edff53bb313a1a6829a948a69b40a8c83efba6e2eb2c33c29a5406e89572a4b1" +
+			"718468bc9070e7c449284cfc9eb02a76e07af725717be651e57119974ea89d92" +
+			"96aa691017d56d2746dc5d62a28621db5a3efa5b5dbfb65e1a135ac70169c869" +
+			"2567927e7a1f111ddb63c9621f43306c4839fe667febe615021517aa9f74f971" +
+			"c0cde77fa8fef97d476c10aad3d2d54fcc2f336140d073651c2dcccf1e379fd6" +
+			"c9430e1fa13a26d0a46699e217402d85cfd60ceebcfdbd9dc37c08bda031ce99",
+		secretBlock: "vSsar3708Jvp9Szi2NWZZ02Bqp1qRCFpbcTZPdBhnWgs5WtNZKnvCXdhztmeD2cm" +
+			"W192CF5bDufKRpayrW/isr0rGq9+9PCb6fUs4tjVmWdNgaqdakQhaW3E2T3QYZ1o" +
+			"LOVrTWSp7wl3Yc7Zng9nJltfdgheWw7nykaWsq1v4rI=",
+		now: time.Date(2025, time.December, 31, 23, 59, 5, 0, time.UTC),
+		srpA: "135d6a27d03b842425b4893c467cbc593def9a781b90b95cf19d3eee041f31b4" +
+			"6fb14e9173c840622202c7c8d7866b5ff1599a2b9d75bcceb0a2425b81396e0c" +
+			"0308e87de44fab57bb404edaaa432f76cfae57f393816f7609ec87c739c88896" +
+			"c6ada0358b0ad1b1455ed947ddcb92b2ce769488f6eb4c224bea960ee92a043f" +
+			"4e2b8fa180a7248ad344523fc8f3dc5b4a441b394c696125591c65445fafe05f" +
+			"c27035c1b4085c846dce4ed6b5e6144297d60a29f95911a8b4a34ed49e568251" +
+			"fdb587bb2edcaf76a2835c9ef435a9565c261b9777ab2290f6ffbe98f075451c" +
+			"9c91fc5b7b53bda8f431f82990d60002111781d01250f3a45c79e97e169df3ed" +
+			"417fbf5b3e008c49c3c66e2fb1c1a1084ed4db3a61eb68e131c21b1346368174" +
+			"48fd90ff9c41cadeb73dbb9b9545fed8089dcface19769c6ac45c81b402019fd" +
+			"095ac863c08597ebbe351e5dcf8ab3df45216fee89c97825965e56aac0383a42" +
+			"48d91c3c3ff849bdcd277a3599556b8b6720874f6f5b86e4eeda5f9caf63e949",
+		timestamp: "Wed Dec 31 23:59:05 UTC 2025",
+		signature: "0OF5PmVZG6xogm6d46+URiSihZciN897Sh7TFv28EkI=",
+	},
+}
+
+func TestPasswordVerifier_FixedVector(t *testing.T) {
+	for _, v := range srpVectors {
+		t.Run(v.name, func(t *testing.T) {
+			a, ok := new(big.Int).SetString(v.a, 16)
+			if !ok {
+				t.Fatalf("invalid a: %s", v.a)
+			}
+			client, err := newClientWithKey(v.poolID, a)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := client.SRPA(); got != v.srpA {
+				t.Fatalf("unexpected SRP_A: %s", got)
+			}
+
+			signature, timestamp, err := client.PasswordVerifier(v.userIDForSRP, v.password, v.salt, v.srpB, v.secretBlock, v.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if timestamp != v.timestamp {
+				t.Errorf("timestamp = %q, want %q", timestamp, v.timestamp)
+			}
+			if signature != v.signature {
+				t.Errorf("PASSWORD_CLAIM_SIGNATURE = %s, want %s", signature, v.signature)
+			}
+		})
+	}
+}
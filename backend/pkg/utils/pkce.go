@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+const (
+	PKCEMethodS256 = "S256"
+
+	pkceVerifierMinLen = 43
+	pkceVerifierMaxLen = 128
+)
+
+// GenerateRandomString - 暗号論的乱数をbase64url（パディングなし）で返す
+func GenerateRandomString(byteLen int) (string, error) {
+	buf := make([]byte, byteLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateCodeVerifier - RFC 7636 の code_verifier を生成（32バイト → 43文字）
+func GenerateCodeVerifier() (string, error) {
+	return GenerateRandomString(32)
+}
+
+// CodeChallengeS256 - code_verifier から S256 の code_challenge を計算
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// IsValidCodeVerifier - 長さと文字種（unreserved characters）を検証
+func IsValidCodeVerifier(verifier string) bool {
+	if len(verifier) < pkceVerifierMinLen || len(verifier) > pkceVerifierMaxLen {
+		return false
+	}
+	for _, c := range verifier {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-', c == '.', c == '_', c == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// RFC 7636 Appendix B の例
+func TestCodeChallengeS256_RFC7636(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got, want := CodeChallengeS256(verifier), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallengeS256() = %q, want %q", got, want)
+	}
+}
+
+func TestIsValidCodeVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string
+		want     bool
+	}{
+		{"RFC 7636の例", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", true},
+		{"最短（43文字）", strings.Repeat("a", 43), true},
+		{"最長（128文字）", strings.Repeat("a", 128), true},
+		{"unreserved characters", "ABCXYZabcxyz0189-._~" + strings.Repeat("a", 23), true},
+		{"短すぎる", strings.Repeat("a", 42), false},
+		{"長すぎる", strings.Repeat("a", 129), false},
+		{"空", "", false},
+		{"base64の+", "+" + strings.Repeat("a", 42), false},
+		{"base64の/", "/" + strings.Repeat("a", 42), false},
+		{"パディング", strings.Repeat("a", 42) + "=", false},
+		{"空白", " " + strings.Repeat("a", 42), false},
+		{"マルチバイト文字", "あ" + strings.Repeat("a", 42), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidCodeVerifier(tt.verifier); got != tt.want {
+				t.Errorf("IsValidCodeVerifier(%q) = %v, want %v", tt.verifier, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCodeVerifier(t *testing.T) {
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(verifier) != 43 || !IsValidCodeVerifier(verifier) {
+		t.Errorf("invalid generated verifier: %q", verifier)
+	}
+
+	other, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other == verifier {
+		t.Error("expected a new verifier for each call")
+	}
+}
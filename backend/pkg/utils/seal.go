@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrInvalidSealedValue - 改ざん・破損・別の鍵で暗号化された値
+var ErrInvalidSealedValue = errors.New("invalid sealed value")
+
+// Seal - secretから導出した鍵でAES-256-GCM暗号化し、base64url（パディングなし）で返す
+// クライアントに渡すが中身を見せたくない・改ざんされたくない値に使う
+func Seal(secret string, plaintext []byte) (string, error) {
+	aead, err := newSealAEAD(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open - Sealで暗号化した値を復号する
+func Open(secret, sealed string) ([]byte, error) {
+	aead, err := newSealAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrInvalidSealedValue
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrInvalidSealedValue
+	}
+	return plaintext, nil
+}
+
+func newSealAEAD(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("seal:" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	sealed, err := Seal("secret", []byte("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Open("secret", sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, []byte("payload")) {
+		t.Errorf("Open() = %q, want payload", got)
+	}
+
+	// 同じ値でも毎回異なるnonceで暗号化する
+	again, _ := Seal("secret", []byte("payload"))
+	if again == sealed {
+		t.Error("sealing the same value twice should not produce the same output")
+	}
+}
+
+func TestOpen_RejectsInvalidValues(t *testing.T) {
+	sealed, err := Seal("secret", []byte("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	flipped := []byte(sealed)
+	flipped[len(flipped)/2] ^= 0x01
+
+	for name, value := range map[string]string{
+		"wrong key":   "",
+		"tampered":    string(flipped),
+		"truncated":   sealed[:8],
+		"not base64":  "!!!",
+		"empty value": "",
+	} {
+		secret := "secret"
+		if name == "wrong key" {
+			secret, value = "other", sealed
+		}
+		if _, err := Open(secret, value); !errors.Is(err, ErrInvalidSealedValue) {
+			t.Errorf("%s: expected ErrInvalidSealedValue, got %v", name, err)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package utils
 
-import "strings"
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
 
 func MaskSensitiveData(data string, prefixLen, suffixLen int, mask string) string {
 	if prefixLen < 0 {
@@ -31,4 +36,14 @@ func MaskSensitiveData(data string, prefixLen, suffixLen int, mask string) strin
 	suffix := string(runes[length-suffixLen:])
 
 	return prefix + mask + suffix
-}
\ No newline at end of file
+}
+
+// GenerateNumericCode - 暗号論的乱数で指定桁数の数字コード（ワンタイムコード）を生成
+func GenerateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
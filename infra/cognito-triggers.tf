@@ -0,0 +1,109 @@
+# ===========================================
+# Cognito Custom Auth Triggers (Passwordless email OTP)
+# ===========================================
+# Lambdaのzipは `make build-lambdas` で backend/build/lambda に作成する
+
+locals {
+  auth_challenge_triggers = toset([
+    "define-auth-challenge",
+    "create-auth-challenge",
+    "verify-auth-challenge",
+  ])
+}
+
+# IAM Role for trigger functions
+resource "aws_iam_role" "auth_challenge_trigger" {
+  name = "${var.project}-${var.environment}-auth-challenge-trigger"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+        Action = "sts:AssumeRole"
+      }
+    ]
+  })
+}
+
+resource "aws_iam_role_policy_attachment" "auth_challenge_trigger_logs" {
+  role       = aws_iam_role.auth_challenge_trigger.name
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+}
+
+# ワンタイムコードのメール送信
+resource "aws_iam_role_policy" "auth_challenge_trigger_ses" {
+  name = "send_login_code"
+  role = aws_iam_role.auth_challenge_trigger.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["ses:SendEmail"]
+        Resource = "*"
+        Condition = {
+          StringEquals = {
+            "ses:FromAddress" = var.mail_from
+          }
+        }
+      }
+    ]
+  })
+}
+
+# Define Auth Challenge がMFAの設定を確認する（TOTPを有効にしたユーザーはメールのコードだけでログインさせない）
+resource "aws_iam_role_policy" "auth_challenge_trigger_cognito" {
+  name = "read_mfa_settings"
+  role = aws_iam_role.auth_challenge_trigger.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["cognito-idp:AdminGetUser"]
+        Resource = aws_cognito_user_pool.main.arn
+      }
+    ]
+  })
+}
+
+resource "aws_lambda_function" "auth_challenge" {
+  for_each = local.auth_challenge_triggers
+
+  function_name    = "${var.project}-${var.environment}-${each.key}"
+  role             = aws_iam_role.auth_challenge_trigger.arn
+  runtime          = "provided.al2023"
+  handler          = "bootstrap"
+  architectures    = ["arm64"]
+  filename         = "${var.lambda_artifacts_dir}/${each.key}.zip"
+  source_code_hash = filebase64sha256("${var.lambda_artifacts_dir}/${each.key}.zip")
+  timeout          = 10
+
+  environment {
+    variables = {
+      MAIL_DRIVER    = "ses"
+      MAIL_FROM      = var.mail_from
+      LOGIN_CODE_TTL = "5m"
+    }
+  }
+
+  tags = {
+    Name = "${var.project}-${var.environment}-${each.key}"
+  }
+}
+
+resource "aws_lambda_permission" "auth_challenge_cognito" {
+  for_each = local.auth_challenge_triggers
+
+  statement_id  = "AllowCognitoInvoke"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.auth_challenge[each.key].function_name
+  principal     = "cognito-idp.amazonaws.com"
+  source_arn    = aws_cognito_user_pool.main.arn
+}
@@ -9,6 +9,18 @@ resource "aws_cognito_user_pool" "main" {
   username_attributes      = ["email"]
   auto_verified_attributes = ["email"]
 
+  # メールアドレスの変更は確認コードで検証されるまで反映しない（/me/attributes/verify）
+  user_attribute_update_settings {
+    attributes_require_verification_before_update = ["email"]
+  }
+
+  # ユーザーごとに認証アプリ（TOTP）によるMFAを有効化できる
+  mfa_configuration = "OPTIONAL"
+
+  software_token_mfa_configuration {
+    enabled = true
+  }
+
   password_policy {
     minimum_length    = 8
     require_lowercase = true
@@ -24,6 +36,13 @@ resource "aws_cognito_user_pool" "main" {
     }
   }
 
+  # パスワードレスログイン（メールのワンタイムコード）
+  lambda_config {
+    define_auth_challenge          = aws_lambda_function.auth_challenge["define-auth-challenge"].arn
+    create_auth_challenge          = aws_lambda_function.auth_challenge["create-auth-challenge"].arn
+    verify_auth_challenge_response = aws_lambda_function.auth_challenge["verify-auth-challenge"].arn
+  }
+
   verification_message_template {
     default_email_option = "CONFIRM_WITH_CODE"
     email_subject        = "Your verification code"
@@ -42,6 +61,9 @@ resource "aws_cognito_user_pool_client" "main" {
 
   generate_secret = false
 
+  # 存在しないユーザーでもログイン・パスワード再設定の応答を変えない
+  prevent_user_existence_errors = "ENABLED"
+
   # OAuth configuration
   callback_urls = [
     "http://localhost:5173/auth/callback",
@@ -53,20 +75,24 @@ resource "aws_cognito_user_pool_client" "main" {
     "https://${var.domain}"
   ]
 
+  # aws.cognito.signin.user.admin - Hosted UIのアクセストークンでGlobalSignOut・GetUser・UpdateUserAttributes・MFA登録などを呼ぶために必要
   allowed_oauth_flows                  = ["code"]
   allowed_oauth_flows_user_pool_client = true
-  allowed_oauth_scopes                 = ["email", "openid", "profile"]
+  allowed_oauth_scopes                 = ["email", "openid", "profile", "aws.cognito.signin.user.admin"]
 
-  supported_identity_providers = ["COGNITO", "Google"]
+  supported_identity_providers = ["COGNITO", "Google", "Facebook", "GitHub"]
 
   explicit_auth_flows = [
     "ALLOW_USER_PASSWORD_AUTH",
     "ALLOW_REFRESH_TOKEN_AUTH",
-    "ALLOW_USER_SRP_AUTH"
+    "ALLOW_USER_SRP_AUTH",
+    "ALLOW_CUSTOM_AUTH"
   ]
 
   depends_on = [
-    aws_cognito_identity_provider.google
+    aws_cognito_identity_provider.google,
+    aws_cognito_identity_provider.facebook,
+    aws_cognito_identity_provider.github
   ]
 }
 
@@ -83,15 +109,61 @@ resource "aws_cognito_identity_provider" "google" {
   }
 
   attribute_mapping = {
-    email       = "email"
-    username    = "sub"
-    given_name  = "given_name"
-    family_name = "family_name"
-    picture     = "picture"
+    email          = "email"
+    email_verified = "email_verified"
+    username       = "sub"
+    given_name     = "given_name"
+    family_name    = "family_name"
+    picture        = "picture"
+  }
+}
+
+# Cognito Identity Provider - Facebook
+resource "aws_cognito_identity_provider" "facebook" {
+  user_pool_id  = aws_cognito_user_pool.main.id
+  provider_name = "Facebook"
+  provider_type = "Facebook"
+
+  provider_details = {
+    client_id        = var.facebook_app_id
+    client_secret    = var.facebook_app_secret
+    authorize_scopes = "public_profile,email"
+    api_version      = "v19.0"
+  }
+
+  # picture は {"data":{"url":...}} のJSONで届くため backend 側で正規化する
+  attribute_mapping = {
+    email    = "email"
+    name     = "name"
+    picture  = "picture"
+    username = "id"
   }
 }
 
+# Cognito Identity Provider - GitHub
+# GitHubはOIDCに対応していないため、backend/cmd/github-oidc のシムをOIDCプロバイダーとして登録する
+resource "aws_cognito_identity_provider" "github" {
+  user_pool_id  = aws_cognito_user_pool.main.id
+  provider_name = "GitHub"
+  provider_type = "OIDC"
 
+  provider_details = {
+    client_id                 = var.github_client_id
+    client_secret             = var.github_client_secret
+    oidc_issuer               = var.github_oidc_issuer
+    attributes_request_method = "GET"
+    authorize_scopes          = "openid email profile"
+  }
+
+  attribute_mapping = {
+    email              = "email"
+    email_verified     = "email_verified"
+    name               = "name"
+    picture            = "picture"
+    preferred_username = "preferred_username"
+    username           = "sub"
+  }
+}
 
 # Cognito User Pool Domain
 resource "aws_cognito_user_pool_domain" "main" {
@@ -99,6 +171,20 @@ resource "aws_cognito_user_pool_domain" "main" {
   user_pool_id = aws_cognito_user_pool.main.id
 }
 
+# 管理者API（/api/v1/admin）を利用できるグループ（backend の ADMIN_ROLE と一致させる）
+resource "aws_cognito_user_group" "admin" {
+  name         = "admin"
+  user_pool_id = aws_cognito_user_pool.main.id
+  description  = "Support staff allowed to use the admin user API"
+}
+
+# 一般ユーザーのロール（ROLE_HIERARCHY の既定値では admin が member を包含する）
+resource "aws_cognito_user_group" "member" {
+  name         = "member"
+  user_pool_id = aws_cognito_user_pool.main.id
+  description  = "Regular members"
+}
+
 # Random string for global uniqueness
 resource "random_string" "cognito_suffix" {
   length  = 8
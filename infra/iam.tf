@@ -31,14 +31,16 @@ resource "aws_iam_role_policy" "authenticated" {
 
   policy = jsonencode({
     Version = "2012-10-17"
+    # IDプールの GetId・GetCredentialsForIdentity は署名なしで呼ぶAPIのため、cognito-identity の権限は不要
     Statement = [
       {
+        # 自分のIDのプレフィックスにのみアップロードできる
         Effect = "Allow"
         Action = [
-          "cognito-sync:*",
-          "cognito-identity:*"
+          "s3:PutObject",
+          "s3:AbortMultipartUpload"
         ]
-        Resource = "*"
+        Resource = "${aws_s3_bucket.uploads.arn}/uploads/$${cognito-identity.amazonaws.com:sub}/*"
       }
     ]
   })
@@ -0,0 +1,42 @@
+# ===========================================
+# Uploads Bucket
+# ===========================================
+# フロントエンドがIDプールの一時的な認証情報で直接アップロードする
+# 認証済みロールは uploads/{IDプールのID}/ 以下にのみ書き込める（iam.tf）
+
+resource "aws_s3_bucket" "uploads" {
+  bucket_prefix = "${var.project}-${var.environment}-uploads-"
+
+  tags = {
+    Name = "${var.project}-${var.environment}-uploads"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "uploads" {
+  bucket = aws_s3_bucket.uploads.id
+
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_cors_configuration" "uploads" {
+  bucket = aws_s3_bucket.uploads.id
+
+  cors_rule {
+    allowed_methods = ["PUT", "POST"]
+    allowed_origins = [
+      "http://localhost:5173",
+      "https://${var.domain}"
+    ]
+    allowed_headers = ["*"]
+    expose_headers  = ["ETag"]
+    max_age_seconds = 3000
+  }
+}
+
+output "uploads_bucket_name" {
+  description = "S3 bucket for direct uploads"
+  value       = aws_s3_bucket.uploads.bucket
+}
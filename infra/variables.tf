@@ -26,4 +26,44 @@ variable "google_client_secret" {
   description = "Google OAuth Client Secret"
   type        = string
   sensitive   = true
-}
\ No newline at end of file
+}
+
+variable "facebook_app_id" {
+  description = "Facebook App ID"
+  type        = string
+  sensitive   = true
+}
+
+variable "facebook_app_secret" {
+  description = "Facebook App Secret"
+  type        = string
+  sensitive   = true
+}
+
+variable "github_client_id" {
+  description = "GitHub OAuth App Client ID"
+  type        = string
+  sensitive   = true
+}
+
+variable "github_client_secret" {
+  description = "GitHub OAuth App Client Secret"
+  type        = string
+  sensitive   = true
+}
+
+variable "github_oidc_issuer" {
+  description = "Public HTTPS URL of the GitHub OIDC shim (backend/cmd/github-oidc, GITHUB_OIDC_ISSUER)"
+  type        = string
+}
+variable "lambda_artifacts_dir" {
+  description = "Directory containing the Cognito trigger Lambda zip files"
+  type        = string
+  default     = "../backend/build/lambda"
+}
+
+variable "mail_from" {
+  description = "Verified SES sender address for login codes"
+  type        = string
+  default     = "no-reply@example.com"
+}